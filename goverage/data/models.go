@@ -8,13 +8,103 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BadgeSetting struct {
+	ID          int32
+	RepoName    string
+	ProjectName string
+	ColorScale  string
+	UpdatedAt   pgtype.Timestamptz
+}
+
 type Coverage struct {
-	ID           int32
-	RepoName     string
-	ProjectName  string
-	BranchName   string
-	Commit       string
-	Coverage     float64
-	CoverageDate pgtype.Timestamptz
-	RawData      []byte
+	ID                   int32
+	RepoName             string
+	ProjectName          string
+	BranchName           string
+	Commit               string
+	Coverage             float64
+	CoverageDate         pgtype.Timestamptz
+	RawData              []byte
+	Format               string
+	Kind                 string
+	LinesCovered         int32
+	LinesTotal           int32
+	BranchesCovered      int32
+	BranchesTotal        int32
+	OriginalCoverage     float64
+	OriginalLinesCovered int32
+	OriginalLinesTotal   int32
+	FunctionsCovered     int32
+	FunctionsTotal       int32
+	RulesVersion         string
+}
+
+type CoverageConfig struct {
+	CoverageID int32
+	Content    string
+	UploadedAt pgtype.Timestamptz
+}
+
+type CoverageFile struct {
+	ID                int32
+	CoverageID        int32
+	Path              string
+	Coverage          float64
+	LinesCovered      int32
+	LinesTotal        int32
+	BranchesCovered   int32
+	BranchesTotal     int32
+	StatementsCovered int32
+	StatementsTotal   int32
+}
+
+type CoverageLineRange struct {
+	CoverageFileID  int32
+	StartLine       int32
+	EndLine         int32
+	Hits            int64
+	BranchesCovered int32
+	BranchesTotal   int32
+}
+
+type CoverageShard struct {
+	ID             int32
+	RepoName       string
+	ProjectName    string
+	BranchName     string
+	Commit         string
+	ShardID        string
+	Format         string
+	Content        []byte
+	ExpectedShards pgtype.Int4
+	UploadedAt     pgtype.Timestamptz
+}
+
+type ExclusionRule struct {
+	ID          int32
+	RepoName    string
+	ProjectName string
+	Pattern     string
+	CreatedAt   pgtype.Timestamptz
+}
+
+type FileRename struct {
+	RepoName    string
+	ProjectName string
+	OldPath     string
+	NewPath     string
+	Commit      string
+	CreatedAt   pgtype.Timestamptz
+	BranchName  string
+}
+
+type GatePolicy struct {
+	ID               int32
+	RepoName         string
+	ProjectName      string
+	MinCoverage      pgtype.Float8
+	MaxDrop          pgtype.Float8
+	MinPatchCoverage pgtype.Float8
+	PathRules        []byte
+	UpdatedAt        pgtype.Timestamptz
 }
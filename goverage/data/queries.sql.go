@@ -11,12 +11,187 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countStaleProjectCoverage = `-- name: CountStaleProjectCoverage :one
+SELECT count(*) FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND rules_version <> $3
+`
+
+type CountStaleProjectCoverageParams struct {
+	RepoName     string
+	ProjectName  string
+	RulesVersion string
+}
+
+func (q *Queries) CountStaleProjectCoverage(ctx context.Context, arg CountStaleProjectCoverageParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countStaleProjectCoverage, arg.RepoName, arg.ProjectName, arg.RulesVersion)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteBadgeSettings = `-- name: DeleteBadgeSettings :execrows
+DELETE FROM badge_settings WHERE repo_name = $1 AND project_name = $2
+`
+
+type DeleteBadgeSettingsParams struct {
+	RepoName    string
+	ProjectName string
+}
+
+func (q *Queries) DeleteBadgeSettings(ctx context.Context, arg DeleteBadgeSettingsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBadgeSettings, arg.RepoName, arg.ProjectName)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteCoverageConfig = `-- name: DeleteCoverageConfig :exec
+DELETE FROM coverage_config WHERE coverage_id = $1
+`
+
+func (q *Queries) DeleteCoverageConfig(ctx context.Context, coverageID int32) error {
+	_, err := q.db.Exec(ctx, deleteCoverageConfig, coverageID)
+	return err
+}
+
+const deleteCoverageFiles = `-- name: DeleteCoverageFiles :exec
+DELETE FROM coverage_file WHERE coverage_id = $1
+`
+
+func (q *Queries) DeleteCoverageFiles(ctx context.Context, coverageID int32) error {
+	_, err := q.db.Exec(ctx, deleteCoverageFiles, coverageID)
+	return err
+}
+
+const deleteExclusionRule = `-- name: DeleteExclusionRule :execrows
+DELETE FROM exclusion_rule WHERE id = $1 AND repo_name = $2 AND project_name = $3
+`
+
+type DeleteExclusionRuleParams struct {
+	ID          int32
+	RepoName    string
+	ProjectName string
+}
+
+func (q *Queries) DeleteExclusionRule(ctx context.Context, arg DeleteExclusionRuleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExclusionRule, arg.ID, arg.RepoName, arg.ProjectName)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteGatePolicy = `-- name: DeleteGatePolicy :execrows
+DELETE FROM gate_policy WHERE repo_name = $1 AND project_name = $2
+`
+
+type DeleteGatePolicyParams struct {
+	RepoName    string
+	ProjectName string
+}
+
+func (q *Queries) DeleteGatePolicy(ctx context.Context, arg DeleteGatePolicyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteGatePolicy, arg.RepoName, arg.ProjectName)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getBadgeSettings = `-- name: GetBadgeSettings :one
+SELECT id, repo_name, project_name, color_scale, updated_at FROM badge_settings WHERE repo_name = $1 AND project_name = $2
+`
+
+type GetBadgeSettingsParams struct {
+	RepoName    string
+	ProjectName string
+}
+
+func (q *Queries) GetBadgeSettings(ctx context.Context, arg GetBadgeSettingsParams) (BadgeSetting, error) {
+	row := q.db.QueryRow(ctx, getBadgeSettings, arg.RepoName, arg.ProjectName)
+	var i BadgeSetting
+	err := row.Scan(
+		&i.ID,
+		&i.RepoName,
+		&i.ProjectName,
+		&i.ColorScale,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCommitCoverage = `-- name: GetCommitCoverage :one
+SELECT id, repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data, format, kind, lines_covered, lines_total, branches_covered, branches_total, original_coverage, original_lines_covered, original_lines_total, functions_covered, functions_total, rules_version FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND branch_name = $3
+    AND "commit" = $4
+    AND kind = $5
+`
+
+type GetCommitCoverageParams struct {
+	RepoName    string
+	ProjectName string
+	BranchName  string
+	Commit      string
+	Kind        string
+}
+
+func (q *Queries) GetCommitCoverage(ctx context.Context, arg GetCommitCoverageParams) (Coverage, error) {
+	row := q.db.QueryRow(ctx, getCommitCoverage,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.BranchName,
+		arg.Commit,
+		arg.Kind,
+	)
+	var i Coverage
+	err := row.Scan(
+		&i.ID,
+		&i.RepoName,
+		&i.ProjectName,
+		&i.BranchName,
+		&i.Commit,
+		&i.Coverage,
+		&i.CoverageDate,
+		&i.RawData,
+		&i.Format,
+		&i.Kind,
+		&i.LinesCovered,
+		&i.LinesTotal,
+		&i.BranchesCovered,
+		&i.BranchesTotal,
+		&i.OriginalCoverage,
+		&i.OriginalLinesCovered,
+		&i.OriginalLinesTotal,
+		&i.FunctionsCovered,
+		&i.FunctionsTotal,
+		&i.RulesVersion,
+	)
+	return i, err
+}
+
+const getCoverageConfig = `-- name: GetCoverageConfig :one
+SELECT content FROM coverage_config WHERE coverage_id = $1
+`
+
+func (q *Queries) GetCoverageConfig(ctx context.Context, coverageID int32) (string, error) {
+	row := q.db.QueryRow(ctx, getCoverageConfig, coverageID)
+	var content string
+	err := row.Scan(&content)
+	return content, err
+}
+
 const getCoverageData = `-- name: GetCoverageData :one
 SELECT raw_data FROM coverage
 WHERE repo_name = $1
     AND project_name = $2
     AND branch_name = $3
     AND "commit" = $4
+    AND kind = $5
 LIMIT 1
 `
 
@@ -25,6 +200,7 @@ type GetCoverageDataParams struct {
 	ProjectName string
 	BranchName  string
 	Commit      string
+	Kind        string
 }
 
 func (q *Queries) GetCoverageData(ctx context.Context, arg GetCoverageDataParams) ([]byte, error) {
@@ -33,17 +209,94 @@ func (q *Queries) GetCoverageData(ctx context.Context, arg GetCoverageDataParams
 		arg.ProjectName,
 		arg.BranchName,
 		arg.Commit,
+		arg.Kind,
 	)
 	var raw_data []byte
 	err := row.Scan(&raw_data)
 	return raw_data, err
 }
 
+const getGatePolicy = `-- name: GetGatePolicy :one
+SELECT id, repo_name, project_name, min_coverage, max_drop, min_patch_coverage, path_rules, updated_at FROM gate_policy WHERE repo_name = $1 AND project_name = $2
+`
+
+type GetGatePolicyParams struct {
+	RepoName    string
+	ProjectName string
+}
+
+func (q *Queries) GetGatePolicy(ctx context.Context, arg GetGatePolicyParams) (GatePolicy, error) {
+	row := q.db.QueryRow(ctx, getGatePolicy, arg.RepoName, arg.ProjectName)
+	var i GatePolicy
+	err := row.Scan(
+		&i.ID,
+		&i.RepoName,
+		&i.ProjectName,
+		&i.MinCoverage,
+		&i.MaxDrop,
+		&i.MinPatchCoverage,
+		&i.PathRules,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectCommitCoverage = `-- name: GetProjectCommitCoverage :one
+SELECT id, repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data, format, kind, lines_covered, lines_total, branches_covered, branches_total, original_coverage, original_lines_covered, original_lines_total, functions_covered, functions_total, rules_version FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND "commit" = $3
+    AND kind = $4
+ORDER BY coverage_date DESC
+LIMIT 1
+`
+
+type GetProjectCommitCoverageParams struct {
+	RepoName    string
+	ProjectName string
+	Commit      string
+	Kind        string
+}
+
+func (q *Queries) GetProjectCommitCoverage(ctx context.Context, arg GetProjectCommitCoverageParams) (Coverage, error) {
+	row := q.db.QueryRow(ctx, getProjectCommitCoverage,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.Commit,
+		arg.Kind,
+	)
+	var i Coverage
+	err := row.Scan(
+		&i.ID,
+		&i.RepoName,
+		&i.ProjectName,
+		&i.BranchName,
+		&i.Commit,
+		&i.Coverage,
+		&i.CoverageDate,
+		&i.RawData,
+		&i.Format,
+		&i.Kind,
+		&i.LinesCovered,
+		&i.LinesTotal,
+		&i.BranchesCovered,
+		&i.BranchesTotal,
+		&i.OriginalCoverage,
+		&i.OriginalLinesCovered,
+		&i.OriginalLinesTotal,
+		&i.FunctionsCovered,
+		&i.FunctionsTotal,
+		&i.RulesVersion,
+	)
+	return i, err
+}
+
 const getRecentCoverage = `-- name: GetRecentCoverage :one
-SELECT id, repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data FROM coverage
+SELECT id, repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data, format, kind, lines_covered, lines_total, branches_covered, branches_total, original_coverage, original_lines_covered, original_lines_total, functions_covered, functions_total, rules_version FROM coverage
 WHERE repo_name = $1
     AND project_name = $2
     AND branch_name = $3
+    AND kind = $4
 ORDER BY coverage_date DESC
 LIMIT 1
 `
@@ -52,10 +305,16 @@ type GetRecentCoverageParams struct {
 	RepoName    string
 	ProjectName string
 	BranchName  string
+	Kind        string
 }
 
 func (q *Queries) GetRecentCoverage(ctx context.Context, arg GetRecentCoverageParams) (Coverage, error) {
-	row := q.db.QueryRow(ctx, getRecentCoverage, arg.RepoName, arg.ProjectName, arg.BranchName)
+	row := q.db.QueryRow(ctx, getRecentCoverage,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.BranchName,
+		arg.Kind,
+	)
 	var i Coverage
 	err := row.Scan(
 		&i.ID,
@@ -66,10 +325,162 @@ func (q *Queries) GetRecentCoverage(ctx context.Context, arg GetRecentCoveragePa
 		&i.Coverage,
 		&i.CoverageDate,
 		&i.RawData,
+		&i.Format,
+		&i.Kind,
+		&i.LinesCovered,
+		&i.LinesTotal,
+		&i.BranchesCovered,
+		&i.BranchesTotal,
+		&i.OriginalCoverage,
+		&i.OriginalLinesCovered,
+		&i.OriginalLinesTotal,
+		&i.FunctionsCovered,
+		&i.FunctionsTotal,
+		&i.RulesVersion,
+	)
+	return i, err
+}
+
+const insertCoverageFiles = `-- name: InsertCoverageFiles :many
+INSERT INTO coverage_file (
+    coverage_id, path, coverage, lines_covered, lines_total, branches_covered, branches_total,
+    statements_covered, statements_total
+)
+SELECT $1::int, unnest($2::text[]), unnest($3::float8[]),
+    unnest($4::int[]), unnest($5::int[]),
+    unnest($6::int[]), unnest($7::int[]),
+    unnest($8::int[]), unnest($9::int[])
+RETURNING id, path
+`
+
+type InsertCoverageFilesParams struct {
+	CoverageID        int32
+	Paths             []string
+	Coverages         []float64
+	LinesCovered      []int32
+	LinesTotal        []int32
+	BranchesCovered   []int32
+	BranchesTotal     []int32
+	StatementsCovered []int32
+	StatementsTotal   []int32
+}
+
+type InsertCoverageFilesRow struct {
+	ID   int32
+	Path string
+}
+
+func (q *Queries) InsertCoverageFiles(ctx context.Context, arg InsertCoverageFilesParams) ([]InsertCoverageFilesRow, error) {
+	rows, err := q.db.Query(ctx, insertCoverageFiles,
+		arg.CoverageID,
+		arg.Paths,
+		arg.Coverages,
+		arg.LinesCovered,
+		arg.LinesTotal,
+		arg.BranchesCovered,
+		arg.BranchesTotal,
+		arg.StatementsCovered,
+		arg.StatementsTotal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InsertCoverageFilesRow
+	for rows.Next() {
+		var i InsertCoverageFilesRow
+		if err := rows.Scan(&i.ID, &i.Path); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertCoverageLineRanges = `-- name: InsertCoverageLineRanges :exec
+INSERT INTO coverage_line_range (coverage_file_id, start_line, end_line, hits, branches_covered, branches_total)
+SELECT unnest($1::int[]), unnest($2::int[]), unnest($3::int[]),
+    unnest($4::bigint[]), unnest($5::int[]), unnest($6::int[])
+`
+
+type InsertCoverageLineRangesParams struct {
+	CoverageFileIds []int32
+	StartLines      []int32
+	EndLines        []int32
+	Hits            []int64
+	BranchesCovered []int32
+	BranchesTotal   []int32
+}
+
+func (q *Queries) InsertCoverageLineRanges(ctx context.Context, arg InsertCoverageLineRangesParams) error {
+	_, err := q.db.Exec(ctx, insertCoverageLineRanges,
+		arg.CoverageFileIds,
+		arg.StartLines,
+		arg.EndLines,
+		arg.Hits,
+		arg.BranchesCovered,
+		arg.BranchesTotal,
+	)
+	return err
+}
+
+const insertExclusionRule = `-- name: InsertExclusionRule :one
+INSERT INTO exclusion_rule (repo_name, project_name, pattern)
+VALUES ($1, $2, $3)
+ON CONFLICT (repo_name, project_name, pattern) DO UPDATE SET pattern = EXCLUDED.pattern
+RETURNING id, repo_name, project_name, pattern, created_at
+`
+
+type InsertExclusionRuleParams struct {
+	RepoName    string
+	ProjectName string
+	Pattern     string
+}
+
+func (q *Queries) InsertExclusionRule(ctx context.Context, arg InsertExclusionRuleParams) (ExclusionRule, error) {
+	row := q.db.QueryRow(ctx, insertExclusionRule, arg.RepoName, arg.ProjectName, arg.Pattern)
+	var i ExclusionRule
+	err := row.Scan(
+		&i.ID,
+		&i.RepoName,
+		&i.ProjectName,
+		&i.Pattern,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const insertFileRenames = `-- name: InsertFileRenames :exec
+INSERT INTO file_rename (repo_name, project_name, branch_name, commit, old_path, new_path)
+SELECT $1::text, $2::text, $3::text, $4::text,
+    unnest($5::text[]), unnest($6::text[])
+ON CONFLICT (repo_name, project_name, branch_name, old_path, new_path) DO NOTHING
+`
+
+type InsertFileRenamesParams struct {
+	RepoName    string
+	ProjectName string
+	BranchName  string
+	Commit      string
+	OldPaths    []string
+	NewPaths    []string
+}
+
+func (q *Queries) InsertFileRenames(ctx context.Context, arg InsertFileRenamesParams) error {
+	_, err := q.db.Exec(ctx, insertFileRenames,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.BranchName,
+		arg.Commit,
+		arg.OldPaths,
+		arg.NewPaths,
+	)
+	return err
+}
+
 const listBranches = `-- name: ListBranches :many
 SELECT DISTINCT branch_name FROM coverage WHERE repo_name = $1 AND project_name = $2 order by branch_name
 `
@@ -85,13 +496,293 @@ func (q *Queries) ListBranches(ctx context.Context, arg ListBranchesParams) ([]s
 		return nil, err
 	}
 	defer rows.Close()
-	var items []string
+	var items []string
+	for rows.Next() {
+		var branch_name string
+		if err := rows.Scan(&branch_name); err != nil {
+			return nil, err
+		}
+		items = append(items, branch_name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommitsByPrefix = `-- name: ListCommitsByPrefix :many
+SELECT DISTINCT "commit" FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND branch_name = $3
+    AND kind = $4
+    AND ("commit" LIKE $5::text || '%'
+        OR (length("commit") = 8 AND $5::text LIKE "commit" || '%'))
+    AND NOT (length("commit") = 8 AND EXISTS (
+        SELECT 1 FROM coverage full_row
+        WHERE full_row.repo_name = coverage.repo_name
+            AND full_row.project_name = coverage.project_name
+            AND full_row.branch_name = coverage.branch_name
+            AND full_row.kind = coverage.kind
+            AND length(full_row."commit") > 8
+            AND full_row."commit" LIKE coverage."commit" || '%'
+            AND full_row."commit" LIKE $5::text || '%'
+    ))
+ORDER BY "commit"
+LIMIT 10
+`
+
+type ListCommitsByPrefixParams struct {
+	RepoName    string
+	ProjectName string
+	BranchName  string
+	Kind        string
+	Prefix      string
+}
+
+func (q *Queries) ListCommitsByPrefix(ctx context.Context, arg ListCommitsByPrefixParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listCommitsByPrefix,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.BranchName,
+		arg.Kind,
+		arg.Prefix,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var commit string
+		if err := rows.Scan(&commit); err != nil {
+			return nil, err
+		}
+		items = append(items, commit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCoverage = `-- name: ListCoverage :many
+SELECT id, repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data, format, kind, lines_covered, lines_total, branches_covered, branches_total, original_coverage, original_lines_covered, original_lines_total, functions_covered, functions_total, rules_version FROM coverage
+WHERE repo_name = $1
+  AND project_name = $2
+  AND branch_name = $3
+  AND kind = $6
+ORDER BY
+case WHEN lower($7) = 'asc' THEN coverage_date END ASC,
+case WHEN lower($7) = 'desc' THEN coverage_date END DESC,
+coverage_date ASC
+OFFSET $4
+LIMIT $5
+`
+
+type ListCoverageParams struct {
+	RepoName       string
+	ProjectName    string
+	BranchName     string
+	Offset         int32
+	Limit          int32
+	Kind           string
+	OrderDirection string
+}
+
+func (q *Queries) ListCoverage(ctx context.Context, arg ListCoverageParams) ([]Coverage, error) {
+	rows, err := q.db.Query(ctx, listCoverage,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.BranchName,
+		arg.Offset,
+		arg.Limit,
+		arg.Kind,
+		arg.OrderDirection,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Coverage
+	for rows.Next() {
+		var i Coverage
+		if err := rows.Scan(
+			&i.ID,
+			&i.RepoName,
+			&i.ProjectName,
+			&i.BranchName,
+			&i.Commit,
+			&i.Coverage,
+			&i.CoverageDate,
+			&i.RawData,
+			&i.Format,
+			&i.Kind,
+			&i.LinesCovered,
+			&i.LinesTotal,
+			&i.BranchesCovered,
+			&i.BranchesTotal,
+			&i.OriginalCoverage,
+			&i.OriginalLinesCovered,
+			&i.OriginalLinesTotal,
+			&i.FunctionsCovered,
+			&i.FunctionsTotal,
+			&i.RulesVersion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCoverageFiles = `-- name: ListCoverageFiles :many
+SELECT id, coverage_id, path, coverage, lines_covered, lines_total, branches_covered, branches_total, statements_covered, statements_total FROM coverage_file WHERE coverage_id = $1 ORDER BY path
+`
+
+func (q *Queries) ListCoverageFiles(ctx context.Context, coverageID int32) ([]CoverageFile, error) {
+	rows, err := q.db.Query(ctx, listCoverageFiles, coverageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CoverageFile
+	for rows.Next() {
+		var i CoverageFile
+		if err := rows.Scan(
+			&i.ID,
+			&i.CoverageID,
+			&i.Path,
+			&i.Coverage,
+			&i.LinesCovered,
+			&i.LinesTotal,
+			&i.BranchesCovered,
+			&i.BranchesTotal,
+			&i.StatementsCovered,
+			&i.StatementsTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCoverageLineRanges = `-- name: ListCoverageLineRanges :many
+SELECT coverage_file.path, coverage_line_range.start_line, coverage_line_range.end_line, coverage_line_range.hits,
+    coverage_line_range.branches_covered, coverage_line_range.branches_total
+FROM coverage_line_range
+JOIN coverage_file ON coverage_file.id = coverage_line_range.coverage_file_id
+JOIN coverage ON coverage.id = coverage_file.coverage_id
+WHERE coverage.repo_name = $1
+    AND coverage.project_name = $2
+    AND coverage.branch_name = $3
+    AND coverage."commit" = $4
+    AND coverage.kind = $5
+ORDER BY coverage_file.path, coverage_line_range.start_line
+`
+
+type ListCoverageLineRangesParams struct {
+	RepoName    string
+	ProjectName string
+	BranchName  string
+	Commit      string
+	Kind        string
+}
+
+type ListCoverageLineRangesRow struct {
+	Path            string
+	StartLine       int32
+	EndLine         int32
+	Hits            int64
+	BranchesCovered int32
+	BranchesTotal   int32
+}
+
+func (q *Queries) ListCoverageLineRanges(ctx context.Context, arg ListCoverageLineRangesParams) ([]ListCoverageLineRangesRow, error) {
+	rows, err := q.db.Query(ctx, listCoverageLineRanges,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.BranchName,
+		arg.Commit,
+		arg.Kind,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCoverageLineRangesRow
+	for rows.Next() {
+		var i ListCoverageLineRangesRow
+		if err := rows.Scan(
+			&i.Path,
+			&i.StartLine,
+			&i.EndLine,
+			&i.Hits,
+			&i.BranchesCovered,
+			&i.BranchesTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCoverageShards = `-- name: ListCoverageShards :many
+SELECT id, repo_name, project_name, branch_name, commit, shard_id, format, content, expected_shards, uploaded_at FROM coverage_shard
+WHERE repo_name = $1
+    AND project_name = $2
+    AND branch_name = $3
+    AND "commit" = $4
+ORDER BY shard_id
+`
+
+type ListCoverageShardsParams struct {
+	RepoName    string
+	ProjectName string
+	BranchName  string
+	Commit      string
+}
+
+func (q *Queries) ListCoverageShards(ctx context.Context, arg ListCoverageShardsParams) ([]CoverageShard, error) {
+	rows, err := q.db.Query(ctx, listCoverageShards,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.BranchName,
+		arg.Commit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CoverageShard
 	for rows.Next() {
-		var branch_name string
-		if err := rows.Scan(&branch_name); err != nil {
+		var i CoverageShard
+		if err := rows.Scan(
+			&i.ID,
+			&i.RepoName,
+			&i.ProjectName,
+			&i.BranchName,
+			&i.Commit,
+			&i.ShardID,
+			&i.Format,
+			&i.Content,
+			&i.ExpectedShards,
+			&i.UploadedAt,
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, branch_name)
+		items = append(items, i)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -99,53 +790,79 @@ func (q *Queries) ListBranches(ctx context.Context, arg ListBranchesParams) ([]s
 	return items, nil
 }
 
-const listCoverage = `-- name: ListCoverage :many
-SELECT id, repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data FROM coverage
+const listCoverageSummary = `-- name: ListCoverageSummary :many
+SELECT repo_name, project_name, branch_name, commit, coverage, coverage_date, format, kind,
+    branches_covered, branches_total, original_coverage, functions_covered, functions_total
+FROM coverage
 WHERE repo_name = $1
   AND project_name = $2
   AND branch_name = $3
+  AND kind = $6
 ORDER BY
-case WHEN lower($6) = 'asc' THEN coverage_date END ASC,
-case WHEN lower($6) = 'desc' THEN coverage_date END DESC,
+case WHEN lower($7) = 'asc' THEN coverage_date END ASC,
+case WHEN lower($7) = 'desc' THEN coverage_date END DESC,
 coverage_date ASC
 OFFSET $4
 LIMIT $5
 `
 
-type ListCoverageParams struct {
+type ListCoverageSummaryParams struct {
 	RepoName       string
 	ProjectName    string
 	BranchName     string
 	Offset         int32
 	Limit          int32
+	Kind           string
 	OrderDirection string
 }
 
-func (q *Queries) ListCoverage(ctx context.Context, arg ListCoverageParams) ([]Coverage, error) {
-	rows, err := q.db.Query(ctx, listCoverage,
+type ListCoverageSummaryRow struct {
+	RepoName         string
+	ProjectName      string
+	BranchName       string
+	Commit           string
+	Coverage         float64
+	CoverageDate     pgtype.Timestamptz
+	Format           string
+	Kind             string
+	BranchesCovered  int32
+	BranchesTotal    int32
+	OriginalCoverage float64
+	FunctionsCovered int32
+	FunctionsTotal   int32
+}
+
+func (q *Queries) ListCoverageSummary(ctx context.Context, arg ListCoverageSummaryParams) ([]ListCoverageSummaryRow, error) {
+	rows, err := q.db.Query(ctx, listCoverageSummary,
 		arg.RepoName,
 		arg.ProjectName,
 		arg.BranchName,
 		arg.Offset,
 		arg.Limit,
+		arg.Kind,
 		arg.OrderDirection,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Coverage
+	var items []ListCoverageSummaryRow
 	for rows.Next() {
-		var i Coverage
+		var i ListCoverageSummaryRow
 		if err := rows.Scan(
-			&i.ID,
 			&i.RepoName,
 			&i.ProjectName,
 			&i.BranchName,
 			&i.Commit,
 			&i.Coverage,
 			&i.CoverageDate,
-			&i.RawData,
+			&i.Format,
+			&i.Kind,
+			&i.BranchesCovered,
+			&i.BranchesTotal,
+			&i.OriginalCoverage,
+			&i.FunctionsCovered,
+			&i.FunctionsTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -157,60 +874,214 @@ func (q *Queries) ListCoverage(ctx context.Context, arg ListCoverageParams) ([]C
 	return items, nil
 }
 
-const listCoverageSummary = `-- name: ListCoverageSummary :many
-SELECT repo_name, project_name, branch_name, commit, coverage, coverage_date FROM coverage
-WHERE repo_name = $1
-  AND project_name = $2
-  AND branch_name = $3
+const listExclusionRules = `-- name: ListExclusionRules :many
+SELECT id, repo_name, project_name, pattern, created_at FROM exclusion_rule WHERE repo_name = $1 AND project_name = $2 ORDER BY pattern
+`
+
+type ListExclusionRulesParams struct {
+	RepoName    string
+	ProjectName string
+}
+
+func (q *Queries) ListExclusionRules(ctx context.Context, arg ListExclusionRulesParams) ([]ExclusionRule, error) {
+	rows, err := q.db.Query(ctx, listExclusionRules, arg.RepoName, arg.ProjectName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExclusionRule
+	for rows.Next() {
+		var i ExclusionRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.RepoName,
+			&i.ProjectName,
+			&i.Pattern,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFileCoverageHistory = `-- name: ListFileCoverageHistory :many
+SELECT "commit", coverage_date, path, coverage, lines_covered, lines_total, branches_covered, branches_total
+FROM (
+    SELECT DISTINCT ON (coverage.id) coverage."commit", coverage.coverage_date, coverage_file.path,
+        coverage_file.coverage, coverage_file.lines_covered, coverage_file.lines_total,
+        coverage_file.branches_covered, coverage_file.branches_total
+    FROM coverage
+    JOIN coverage_file ON coverage_file.coverage_id = coverage.id
+    WHERE coverage.repo_name = $1
+      AND coverage.project_name = $2
+      AND coverage.branch_name = $3
+      AND coverage.kind = $6
+      AND coverage_file.path = ANY($7::text[])
+    ORDER BY coverage.id, array_position($7::text[], coverage_file.path)
+) history
 ORDER BY
-case WHEN lower($6) = 'asc' THEN coverage_date END ASC,
-case WHEN lower($6) = 'desc' THEN coverage_date END DESC,
+case WHEN lower($8) = 'asc' THEN coverage_date END ASC,
+case WHEN lower($8) = 'desc' THEN coverage_date END DESC,
 coverage_date ASC
 OFFSET $4
 LIMIT $5
 `
 
-type ListCoverageSummaryParams struct {
+type ListFileCoverageHistoryParams struct {
 	RepoName       string
 	ProjectName    string
 	BranchName     string
 	Offset         int32
 	Limit          int32
+	Kind           string
+	Paths          []string
 	OrderDirection string
 }
 
-type ListCoverageSummaryRow struct {
-	RepoName     string
-	ProjectName  string
-	BranchName   string
-	Commit       string
-	Coverage     float64
-	CoverageDate pgtype.Timestamptz
+type ListFileCoverageHistoryRow struct {
+	Commit          string
+	CoverageDate    pgtype.Timestamptz
+	Path            string
+	Coverage        float64
+	LinesCovered    int32
+	LinesTotal      int32
+	BranchesCovered int32
+	BranchesTotal   int32
 }
 
-func (q *Queries) ListCoverageSummary(ctx context.Context, arg ListCoverageSummaryParams) ([]ListCoverageSummaryRow, error) {
-	rows, err := q.db.Query(ctx, listCoverageSummary,
+func (q *Queries) ListFileCoverageHistory(ctx context.Context, arg ListFileCoverageHistoryParams) ([]ListFileCoverageHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listFileCoverageHistory,
 		arg.RepoName,
 		arg.ProjectName,
 		arg.BranchName,
 		arg.Offset,
 		arg.Limit,
+		arg.Kind,
+		arg.Paths,
 		arg.OrderDirection,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListCoverageSummaryRow
+	var items []ListFileCoverageHistoryRow
 	for rows.Next() {
-		var i ListCoverageSummaryRow
+		var i ListFileCoverageHistoryRow
+		if err := rows.Scan(
+			&i.Commit,
+			&i.CoverageDate,
+			&i.Path,
+			&i.Coverage,
+			&i.LinesCovered,
+			&i.LinesTotal,
+			&i.BranchesCovered,
+			&i.BranchesTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFileRenames = `-- name: ListFileRenames :many
+SELECT old_path, new_path FROM file_rename
+WHERE repo_name = $1 AND project_name = $2 AND branch_name = $3
+ORDER BY created_at, old_path, new_path
+`
+
+type ListFileRenamesParams struct {
+	RepoName    string
+	ProjectName string
+	BranchName  string
+}
+
+type ListFileRenamesRow struct {
+	OldPath string
+	NewPath string
+}
+
+func (q *Queries) ListFileRenames(ctx context.Context, arg ListFileRenamesParams) ([]ListFileRenamesRow, error) {
+	rows, err := q.db.Query(ctx, listFileRenames, arg.RepoName, arg.ProjectName, arg.BranchName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFileRenamesRow
+	for rows.Next() {
+		var i ListFileRenamesRow
+		if err := rows.Scan(&i.OldPath, &i.NewPath); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLatestProjectCoverage = `-- name: ListLatestProjectCoverage :many
+SELECT DISTINCT ON (coverage.project_name)
+    coverage.project_name, coverage.commit, coverage.coverage, coverage.coverage_date,
+    coverage.lines_covered, coverage.lines_total,
+    COALESCE(files.statements_covered, 0)::int AS statements_covered,
+    COALESCE(files.statements_total, 0)::int AS statements_total
+FROM coverage
+LEFT JOIN LATERAL (
+    SELECT SUM(statements_covered) AS statements_covered, SUM(statements_total) AS statements_total
+    FROM coverage_file
+    WHERE coverage_file.coverage_id = coverage.id
+) AS files ON true
+WHERE coverage.repo_name = $1
+    AND coverage.branch_name = $2
+    AND coverage.kind = $3
+ORDER BY coverage.project_name, coverage.coverage_date DESC
+`
+
+type ListLatestProjectCoverageParams struct {
+	RepoName   string
+	BranchName string
+	Kind       string
+}
+
+type ListLatestProjectCoverageRow struct {
+	ProjectName       string
+	Commit            string
+	Coverage          float64
+	CoverageDate      pgtype.Timestamptz
+	LinesCovered      int32
+	LinesTotal        int32
+	StatementsCovered int32
+	StatementsTotal   int32
+}
+
+func (q *Queries) ListLatestProjectCoverage(ctx context.Context, arg ListLatestProjectCoverageParams) ([]ListLatestProjectCoverageRow, error) {
+	rows, err := q.db.Query(ctx, listLatestProjectCoverage, arg.RepoName, arg.BranchName, arg.Kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLatestProjectCoverageRow
+	for rows.Next() {
+		var i ListLatestProjectCoverageRow
 		if err := rows.Scan(
-			&i.RepoName,
 			&i.ProjectName,
-			&i.BranchName,
 			&i.Commit,
 			&i.Coverage,
 			&i.CoverageDate,
+			&i.LinesCovered,
+			&i.LinesTotal,
+			&i.StatementsCovered,
+			&i.StatementsTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -222,6 +1093,58 @@ func (q *Queries) ListCoverageSummary(ctx context.Context, arg ListCoverageSumma
 	return items, nil
 }
 
+const listProjectCommitsByPrefix = `-- name: ListProjectCommitsByPrefix :many
+SELECT DISTINCT "commit" FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND kind = $3
+    AND ("commit" LIKE $4::text || '%'
+        OR (length("commit") = 8 AND $4::text LIKE "commit" || '%'))
+    AND NOT (length("commit") = 8 AND EXISTS (
+        SELECT 1 FROM coverage full_row
+        WHERE full_row.repo_name = coverage.repo_name
+            AND full_row.project_name = coverage.project_name
+            AND full_row.kind = coverage.kind
+            AND length(full_row."commit") > 8
+            AND full_row."commit" LIKE coverage."commit" || '%'
+            AND full_row."commit" LIKE $4::text || '%'
+    ))
+ORDER BY "commit"
+LIMIT 10
+`
+
+type ListProjectCommitsByPrefixParams struct {
+	RepoName    string
+	ProjectName string
+	Kind        string
+	Prefix      string
+}
+
+func (q *Queries) ListProjectCommitsByPrefix(ctx context.Context, arg ListProjectCommitsByPrefixParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listProjectCommitsByPrefix,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.Kind,
+		arg.Prefix,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var commit string
+		if err := rows.Scan(&commit); err != nil {
+			return nil, err
+		}
+		items = append(items, commit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProjects = `-- name: ListProjects :many
 SELECT DISTINCT project_name FROM coverage WHERE repo_name = $1 order by project_name
 `
@@ -270,22 +1193,161 @@ func (q *Queries) ListRepositories(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
-const upsertCoverage = `-- name: UpsertCoverage :one
-INSERT INTO coverage (repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-ON CONFLICT (repo_name, project_name, branch_name, commit)
-    DO UPDATE SET coverage = $5, coverage_date = $6, raw_data = $7
-RETURNING id, repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data
+const listStaleProjectCoverage = `-- name: ListStaleProjectCoverage :many
+SELECT id, repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data, format, kind, lines_covered, lines_total, branches_covered, branches_total, original_coverage, original_lines_covered, original_lines_total, functions_covered, functions_total, rules_version FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND id > $4
+    AND rules_version <> $5
+ORDER BY id
+LIMIT $3
 `
 
-type UpsertCoverageParams struct {
+type ListStaleProjectCoverageParams struct {
 	RepoName     string
 	ProjectName  string
-	BranchName   string
-	Commit       string
-	Coverage     float64
-	CoverageDate pgtype.Timestamptz
-	RawData      []byte
+	Limit        int32
+	AfterID      int32
+	RulesVersion string
+}
+
+func (q *Queries) ListStaleProjectCoverage(ctx context.Context, arg ListStaleProjectCoverageParams) ([]Coverage, error) {
+	rows, err := q.db.Query(ctx, listStaleProjectCoverage,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.Limit,
+		arg.AfterID,
+		arg.RulesVersion,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Coverage
+	for rows.Next() {
+		var i Coverage
+		if err := rows.Scan(
+			&i.ID,
+			&i.RepoName,
+			&i.ProjectName,
+			&i.BranchName,
+			&i.Commit,
+			&i.Coverage,
+			&i.CoverageDate,
+			&i.RawData,
+			&i.Format,
+			&i.Kind,
+			&i.LinesCovered,
+			&i.LinesTotal,
+			&i.BranchesCovered,
+			&i.BranchesTotal,
+			&i.OriginalCoverage,
+			&i.OriginalLinesCovered,
+			&i.OriginalLinesTotal,
+			&i.FunctionsCovered,
+			&i.FunctionsTotal,
+			&i.RulesVersion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const promoteTruncatedCommit = `-- name: PromoteTruncatedCommit :exec
+UPDATE coverage SET "commit" = $5::text
+WHERE repo_name = $1
+    AND project_name = $2
+    AND branch_name = $3
+    AND kind = $4
+    AND "commit" = left($5::text, 8)
+`
+
+type PromoteTruncatedCommitParams struct {
+	RepoName    string
+	ProjectName string
+	BranchName  string
+	Kind        string
+	Commit      string
+}
+
+func (q *Queries) PromoteTruncatedCommit(ctx context.Context, arg PromoteTruncatedCommitParams) error {
+	_, err := q.db.Exec(ctx, promoteTruncatedCommit,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.BranchName,
+		arg.Kind,
+		arg.Commit,
+	)
+	return err
+}
+
+const upsertBadgeSettings = `-- name: UpsertBadgeSettings :one
+INSERT INTO badge_settings (repo_name, project_name, color_scale)
+VALUES ($1, $2, $3)
+ON CONFLICT (repo_name, project_name)
+    DO UPDATE SET color_scale = $3, updated_at = now()
+RETURNING id, repo_name, project_name, color_scale, updated_at
+`
+
+type UpsertBadgeSettingsParams struct {
+	RepoName    string
+	ProjectName string
+	ColorScale  string
+}
+
+func (q *Queries) UpsertBadgeSettings(ctx context.Context, arg UpsertBadgeSettingsParams) (BadgeSetting, error) {
+	row := q.db.QueryRow(ctx, upsertBadgeSettings, arg.RepoName, arg.ProjectName, arg.ColorScale)
+	var i BadgeSetting
+	err := row.Scan(
+		&i.ID,
+		&i.RepoName,
+		&i.ProjectName,
+		&i.ColorScale,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertCoverage = `-- name: UpsertCoverage :one
+INSERT INTO coverage (
+    repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data, format, kind,
+    lines_covered, lines_total, branches_covered, branches_total,
+    original_coverage, original_lines_covered, original_lines_total, functions_covered, functions_total, rules_version
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+ON CONFLICT (repo_name, project_name, branch_name, commit, kind)
+    DO UPDATE SET coverage = $5, coverage_date = $6, raw_data = $7, format = $8,
+        lines_covered = $10, lines_total = $11, branches_covered = $12, branches_total = $13,
+        original_coverage = $14, original_lines_covered = $15, original_lines_total = $16,
+        functions_covered = $17, functions_total = $18, rules_version = $19
+RETURNING id, repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data, format, kind, lines_covered, lines_total, branches_covered, branches_total, original_coverage, original_lines_covered, original_lines_total, functions_covered, functions_total, rules_version
+`
+
+type UpsertCoverageParams struct {
+	RepoName             string
+	ProjectName          string
+	BranchName           string
+	Commit               string
+	Coverage             float64
+	CoverageDate         pgtype.Timestamptz
+	RawData              []byte
+	Format               string
+	Kind                 string
+	LinesCovered         int32
+	LinesTotal           int32
+	BranchesCovered      int32
+	BranchesTotal        int32
+	OriginalCoverage     float64
+	OriginalLinesCovered int32
+	OriginalLinesTotal   int32
+	FunctionsCovered     int32
+	FunctionsTotal       int32
+	RulesVersion         string
 }
 
 func (q *Queries) UpsertCoverage(ctx context.Context, arg UpsertCoverageParams) (Coverage, error) {
@@ -297,6 +1359,18 @@ func (q *Queries) UpsertCoverage(ctx context.Context, arg UpsertCoverageParams)
 		arg.Coverage,
 		arg.CoverageDate,
 		arg.RawData,
+		arg.Format,
+		arg.Kind,
+		arg.LinesCovered,
+		arg.LinesTotal,
+		arg.BranchesCovered,
+		arg.BranchesTotal,
+		arg.OriginalCoverage,
+		arg.OriginalLinesCovered,
+		arg.OriginalLinesTotal,
+		arg.FunctionsCovered,
+		arg.FunctionsTotal,
+		arg.RulesVersion,
 	)
 	var i Coverage
 	err := row.Scan(
@@ -308,6 +1382,108 @@ func (q *Queries) UpsertCoverage(ctx context.Context, arg UpsertCoverageParams)
 		&i.Coverage,
 		&i.CoverageDate,
 		&i.RawData,
+		&i.Format,
+		&i.Kind,
+		&i.LinesCovered,
+		&i.LinesTotal,
+		&i.BranchesCovered,
+		&i.BranchesTotal,
+		&i.OriginalCoverage,
+		&i.OriginalLinesCovered,
+		&i.OriginalLinesTotal,
+		&i.FunctionsCovered,
+		&i.FunctionsTotal,
+		&i.RulesVersion,
+	)
+	return i, err
+}
+
+const upsertCoverageConfig = `-- name: UpsertCoverageConfig :exec
+INSERT INTO coverage_config (coverage_id, content)
+VALUES ($1, $2)
+ON CONFLICT (coverage_id) DO UPDATE SET content = $2, uploaded_at = now()
+`
+
+type UpsertCoverageConfigParams struct {
+	CoverageID int32
+	Content    string
+}
+
+func (q *Queries) UpsertCoverageConfig(ctx context.Context, arg UpsertCoverageConfigParams) error {
+	_, err := q.db.Exec(ctx, upsertCoverageConfig, arg.CoverageID, arg.Content)
+	return err
+}
+
+const upsertCoverageShard = `-- name: UpsertCoverageShard :exec
+INSERT INTO coverage_shard (
+    repo_name, project_name, branch_name, commit, shard_id, format, content, expected_shards
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (repo_name, project_name, branch_name, commit, shard_id)
+    DO UPDATE SET format = $6, content = $7, expected_shards = $8, uploaded_at = now()
+`
+
+type UpsertCoverageShardParams struct {
+	RepoName       string
+	ProjectName    string
+	BranchName     string
+	Commit         string
+	ShardID        string
+	Format         string
+	Content        []byte
+	ExpectedShards pgtype.Int4
+}
+
+func (q *Queries) UpsertCoverageShard(ctx context.Context, arg UpsertCoverageShardParams) error {
+	_, err := q.db.Exec(ctx, upsertCoverageShard,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.BranchName,
+		arg.Commit,
+		arg.ShardID,
+		arg.Format,
+		arg.Content,
+		arg.ExpectedShards,
+	)
+	return err
+}
+
+const upsertGatePolicy = `-- name: UpsertGatePolicy :one
+INSERT INTO gate_policy (repo_name, project_name, min_coverage, max_drop, min_patch_coverage, path_rules)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (repo_name, project_name)
+    DO UPDATE SET min_coverage = $3, max_drop = $4, min_patch_coverage = $5, path_rules = $6, updated_at = now()
+RETURNING id, repo_name, project_name, min_coverage, max_drop, min_patch_coverage, path_rules, updated_at
+`
+
+type UpsertGatePolicyParams struct {
+	RepoName         string
+	ProjectName      string
+	MinCoverage      pgtype.Float8
+	MaxDrop          pgtype.Float8
+	MinPatchCoverage pgtype.Float8
+	PathRules        []byte
+}
+
+func (q *Queries) UpsertGatePolicy(ctx context.Context, arg UpsertGatePolicyParams) (GatePolicy, error) {
+	row := q.db.QueryRow(ctx, upsertGatePolicy,
+		arg.RepoName,
+		arg.ProjectName,
+		arg.MinCoverage,
+		arg.MaxDrop,
+		arg.MinPatchCoverage,
+		arg.PathRules,
+	)
+	var i GatePolicy
+	err := row.Scan(
+		&i.ID,
+		&i.RepoName,
+		&i.ProjectName,
+		&i.MinCoverage,
+		&i.MaxDrop,
+		&i.MinPatchCoverage,
+		&i.PathRules,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
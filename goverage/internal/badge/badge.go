@@ -0,0 +1,267 @@
+// Package badge renders the SVG badges of the public routes, laid out as the
+// badges of shields.io without calling it.
+package badge
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/samber/lo"
+)
+
+// Named colors, with the values shields.io gives them.
+var colors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+	"grey":        "#555",
+}
+
+var hexColorPattern = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ValidColor reports whether color is a named or hex color.
+func ValidColor(color string) bool {
+	_, ok := colors[color]
+	return ok || hexColorPattern.MatchString(color)
+}
+
+// ColorHex returns the hex value of a named or hex color, lightgrey for
+// anything else.
+func ColorHex(color string) string {
+	if hex, ok := colors[color]; ok {
+		return hex
+	}
+
+	if hexColorPattern.MatchString(color) {
+		if color[0] != '#' {
+			return "#" + color
+		}
+		return color
+	}
+
+	return colors["lightgrey"]
+}
+
+// Styles of the badges, named as in shields.io.
+const (
+	StyleFlat        = "flat"
+	StyleFlatSquare  = "flat-square"
+	StylePlastic     = "plastic"
+	StyleForTheBadge = "for-the-badge"
+)
+
+// style holds the geometry of a badge style, positions are in tenths of pixels
+// as texts are drawn scaled down for precision.
+type style struct {
+	height int
+	radius int
+	// gradient holds the stops of the gradient drawn over the badge, none when empty.
+	gradient string
+	fontSize float64
+	padding  int
+	// shadowY is the baseline of the text shadow, none when zero.
+	shadowY       int
+	textY         int
+	uppercase     bool
+	letterSpacing float64
+	// boldMessage draws the message in bold, which is about a tenth wider than
+	// Verdana regular.
+	boldMessage bool
+}
+
+var styles = map[string]style{
+	StyleFlat: {
+		height:   20,
+		radius:   3,
+		gradient: `<stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/>`,
+		fontSize: 11,
+		padding:  5,
+		shadowY:  150,
+		textY:    140,
+	},
+	StyleFlatSquare: {
+		height:   20,
+		fontSize: 11,
+		padding:  5,
+		textY:    140,
+	},
+	StylePlastic: {
+		height: 18,
+		radius: 4,
+		gradient: `<stop offset="0" stop-color="#fff" stop-opacity=".7"/><stop offset=".1" stop-color="#aaa" stop-opacity=".1"/>` +
+			`<stop offset=".9" stop-opacity=".3"/><stop offset="1" stop-opacity=".5"/>`,
+		fontSize: 11,
+		padding:  5,
+		shadowY:  140,
+		textY:    130,
+	},
+	StyleForTheBadge: {
+		height:        28,
+		fontSize:      10,
+		padding:       9,
+		textY:         175,
+		uppercase:     true,
+		letterSpacing: 1.25,
+		boldMessage:   true,
+	},
+}
+
+// ValidStyle reports whether name is one of the styles.
+func ValidStyle(name string) bool {
+	_, ok := styles[name]
+	return ok
+}
+
+// Badge is a label on grey followed by a message on a color.
+type Badge struct {
+	Label   string
+	Message string
+	// Color is a named color such as blue, or a hex color.
+	Color string
+	// Style is one of the styles, flat when empty.
+	Style string
+	// Trend draws a sparkline of its values between the label and the message,
+	// unless it holds less than two of them.
+	Trend []float64
+}
+
+const (
+	// sparklineWidth is the width of the sparkline of a trend.
+	sparklineWidth = 60
+	// sparklineMargin is the space between a sparkline and the badge borders.
+	sparklineMargin = 4
+)
+
+// sparkline returns the points of the polyline drawing values in a box of
+// width and height at x, lowest values at the bottom.
+func sparkline(values []float64, x, width, height int) string {
+	low, high := lo.Min(values), lo.Max(values)
+	innerWidth := float64(width - 2*sparklineMargin)
+	innerHeight := float64(height - 2*sparklineMargin)
+
+	points := make([]string, 0, len(values))
+	for i, value := range values {
+		// A flat trend is drawn across the middle.
+		ratio := 0.5
+		if high > low {
+			ratio = (value - low) / (high - low)
+		}
+
+		pointX := float64(x+sparklineMargin) + innerWidth*float64(i)/float64(len(values)-1)
+		pointY := float64(height-sparklineMargin) - innerHeight*ratio
+		points = append(points, fmt.Sprintf("%.1f,%.1f", pointX, pointY))
+	}
+
+	return strings.Join(points, " ")
+}
+
+// part is a laid out half of a badge, positions are in tenths of pixels as
+// texts are drawn scaled down for precision.
+type part struct {
+	Text       string
+	Bold       bool
+	X          int
+	Width      int
+	TextX      int
+	TextLength int
+}
+
+func layout(text string, x int, style style, bold bool) part {
+	if style.uppercase {
+		text = strings.ToUpper(text)
+	}
+
+	textWidth := TextWidth(text, style.fontSize) + style.letterSpacing*float64(utf8.RuneCountInString(text))
+	if bold {
+		textWidth *= 1.1
+	}
+	width := roundUpToOdd(textWidth) + 2*style.padding
+
+	return part{
+		Text:       html.EscapeString(text),
+		Bold:       bold,
+		X:          x,
+		Width:      width,
+		TextX:      int(math.Round((float64(x) + float64(width)/2) * 10)),
+		TextLength: int(math.Round(textWidth * 10)),
+	}
+}
+
+// roundUpToOdd rounds width up to an odd number of pixels, which centers
+// texts on a pixel.
+func roundUpToOdd(width float64) int {
+	rounded := int(math.Ceil(width))
+	if rounded%2 == 0 {
+		rounded++
+	}
+
+	return rounded
+}
+
+var badgeTemplate = template.Must(template.New("badge").Parse(
+	`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" role="img" aria-label="{{.Title}}">` +
+		`<title>{{.Title}}</title>` +
+		`{{if .Gradient}}<linearGradient id="s" x2="0" y2="100%">{{.Gradient}}</linearGradient>{{end}}` +
+		`<clipPath id="r"><rect width="{{.Width}}" height="{{.Height}}" rx="{{.Radius}}" fill="#fff"/></clipPath>` +
+		`<g clip-path="url(#r)">` +
+		`<rect width="{{.Message.X}}" height="{{.Height}}" fill="#555"/>` +
+		`<rect x="{{.Message.X}}" width="{{.Message.Width}}" height="{{.Height}}" fill="{{.Color}}"/>` +
+		`{{if .Gradient}}<rect width="{{.Width}}" height="{{.Height}}" fill="url(#s)"/>{{end}}` +
+		`</g>` +
+		`{{if .Sparkline}}<polyline points="{{.Sparkline}}" fill="none" stroke="#fff" stroke-width="1.5" stroke-linejoin="round" stroke-linecap="round"/>{{end}}` +
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" text-rendering="geometricPrecision" font-size="{{.FontSize}}"` +
+		`{{if .LetterSpacing}} letter-spacing="{{.LetterSpacing}}"{{end}}>` +
+		`{{range .Parts}}` +
+		`{{if $.ShadowY}}<text aria-hidden="true" x="{{.TextX}}" y="{{$.ShadowY}}" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="{{.TextLength}}"{{if .Bold}} font-weight="bold"{{end}}>{{.Text}}</text>{{end}}` +
+		`<text x="{{.TextX}}" y="{{$.TextY}}" transform="scale(.1)" fill="#fff" textLength="{{.TextLength}}"{{if .Bold}} font-weight="bold"{{end}}>{{.Text}}</text>` +
+		`{{end}}` +
+		`</g></svg>`,
+))
+
+// SVG renders the badge.
+func (b Badge) SVG() []byte {
+	style, ok := styles[b.Style]
+	if !ok {
+		style = styles[StyleFlat]
+	}
+
+	label := layout(b.Label, 0, style, false)
+	trendWidth, points := 0, ""
+	if len(b.Trend) > 1 {
+		trendWidth = sparklineWidth
+		points = sparkline(b.Trend, label.Width, trendWidth, style.height)
+	}
+	message := layout(b.Message, label.Width+trendWidth, style, style.boldMessage)
+
+	var svg bytes.Buffer
+	// The template only fails on a broken writer, which a buffer isn't.
+	_ = badgeTemplate.Execute(&svg, map[string]interface{}{
+		"Width":         label.Width + trendWidth + message.Width,
+		"Height":        style.height,
+		"Radius":        style.radius,
+		"Gradient":      style.gradient,
+		"FontSize":      style.fontSize * 10,
+		"LetterSpacing": style.letterSpacing * 10,
+		"ShadowY":       style.shadowY,
+		"TextY":         style.textY,
+		"Title":         html.EscapeString(b.Label + ": " + b.Message),
+		"Color":         ColorHex(b.Color),
+		"Label":         label,
+		"Message":       message,
+		"Sparkline":     points,
+		"Parts":         []part{label, message},
+	})
+
+	return svg.Bytes()
+}
@@ -0,0 +1,131 @@
+package badge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextWidth(t *testing.T) {
+	assert.InDelta(t, 3.87, TextWidth(" ", 11), 0.01)
+	assert.InDelta(t, 6.99*2+13.30, TextWidth("90%", 11), 0.01)
+	assert.Greater(t, TextWidth("WWW", 11), TextWidth("iii", 11))
+	assert.InDelta(t, TextWidth("W", 11), TextWidth("é", 11), 0.01)
+}
+
+func TestColorHex(t *testing.T) {
+	assert.Equal(t, "#007ec6", ColorHex("blue"))
+	assert.Equal(t, "#ff0000", ColorHex("ff0000"))
+	assert.Equal(t, "#abc", ColorHex("#abc"))
+	assert.Equal(t, "#9f9f9f", ColorHex("not a color"))
+}
+
+func TestSVG(t *testing.T) {
+	svg := string(Badge{Label: "repo/project main", Message: "90%", Color: "blue"}.SVG())
+
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="`))
+	assert.Contains(t, svg, `<title>repo/project main: 90%</title>`)
+	assert.Contains(t, svg, `fill="#007ec6"`)
+	assert.Contains(t, svg, `>90%</text>`)
+
+	t.Run("EscapesTexts", func(t *testing.T) {
+		svg := string(Badge{Label: `<a href="x">`, Message: "1 & 2", Color: "red"}.SVG())
+
+		assert.NotContains(t, svg, `<a href`)
+		assert.Contains(t, svg, `&lt;a href=&#34;x&#34;&gt;`)
+		assert.Contains(t, svg, `1 &amp; 2`)
+	})
+
+	t.Run("FitsTexts", func(t *testing.T) {
+		short := Badge{Label: "a", Message: "1%", Color: "blue"}.SVG()
+		long := Badge{Label: "a much longer label", Message: "1%", Color: "blue"}.SVG()
+
+		assert.Greater(t, len(long), len(short))
+		assert.Contains(t, string(short), `<rect width="17" height="20" fill="#555"/>`)
+	})
+}
+
+func TestStyles(t *testing.T) {
+	t.Run("DrawsEveryStyle", func(t *testing.T) {
+		for _, style := range []string{StyleFlat, StyleFlatSquare, StylePlastic, StyleForTheBadge} {
+			assert.True(t, ValidStyle(style))
+		}
+		assert.False(t, ValidStyle("round"))
+
+		plastic := string(Badge{Label: "a", Message: "1%", Color: "blue", Style: StylePlastic}.SVG())
+		assert.Contains(t, plastic, `height="18"`)
+		assert.Contains(t, plastic, `rx="4"`)
+	})
+
+	t.Run("SquaresWithoutGradient", func(t *testing.T) {
+		svg := string(Badge{Label: "a", Message: "1%", Color: "blue", Style: StyleFlatSquare}.SVG())
+
+		assert.Contains(t, svg, `rx="0"`)
+		assert.NotContains(t, svg, `linearGradient`)
+		assert.NotContains(t, svg, `fill-opacity=".3"`)
+	})
+
+	t.Run("UppercasesForTheBadge", func(t *testing.T) {
+		svg := string(Badge{Label: "coverage", Message: "1%", Color: "blue", Style: StyleForTheBadge}.SVG())
+
+		assert.Contains(t, svg, `height="28"`)
+		assert.Contains(t, svg, `>COVERAGE</text>`)
+		assert.Contains(t, svg, `<title>coverage: 1%</title>`)
+		assert.Contains(t, svg, `font-weight="bold">1%</text>`)
+	})
+
+	t.Run("FallsBackToFlat", func(t *testing.T) {
+		badge := Badge{Label: "a", Message: "1%", Color: "blue"}
+
+		assert.Equal(t, badge.SVG(), Badge{Label: "a", Message: "1%", Color: "blue", Style: StyleFlat}.SVG())
+	})
+}
+
+func TestScale(t *testing.T) {
+	t.Run("PicksColorOfPercent", func(t *testing.T) {
+		scale, err := ParseScale("red:60, yellow:80,green")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "red", scale.Color(59.9))
+		assert.Equal(t, "yellow", scale.Color(60))
+		assert.Equal(t, "green", scale.Color(80))
+		assert.Equal(t, "red:60,yellow:80,green", scale.String())
+	})
+
+	t.Run("AcceptsSingleColor", func(t *testing.T) {
+		scale, err := ParseScale("ff0000")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ff0000", scale.Color(100))
+	})
+
+	t.Run("RejectsInvalidScales", func(t *testing.T) {
+		for _, text := range []string{"", "red:60", "pink:60,green", "red,green", "red:80,yellow:60,green", "red:120,green"} {
+			_, err := ParseScale(text)
+
+			assert.ErrorIs(t, err, ErrInvalidScale, text)
+		}
+	})
+}
+
+func TestTrend(t *testing.T) {
+	t.Run("DrawsSparklineBeforeMessage", func(t *testing.T) {
+		plain := string(Badge{Label: "a", Message: "1%", Color: "blue"}.SVG())
+		svg := string(Badge{Label: "a", Message: "1%", Color: "blue", Trend: []float64{50, 100, 75}}.SVG())
+
+		assert.NotContains(t, plain, "<polyline")
+		assert.Contains(t, svg, `<polyline points="21.0,16.0 47.0,4.0 73.0,10.0"`)
+		assert.Contains(t, svg, `<rect width="77" height="20" fill="#555"/>`)
+	})
+
+	t.Run("DrawsFlatTrendAcrossMiddle", func(t *testing.T) {
+		assert.Equal(t, "4.0,10.0 56.0,10.0", sparkline([]float64{80, 80}, 0, sparklineWidth, 20))
+	})
+
+	t.Run("SkipsSingleValue", func(t *testing.T) {
+		svg := string(Badge{Label: "a", Message: "1%", Color: "blue", Trend: []float64{50}}.SVG())
+
+		assert.NotContains(t, svg, "<polyline")
+	})
+}
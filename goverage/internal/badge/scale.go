@@ -0,0 +1,84 @@
+package badge
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxSteps bounds the steps of a scale.
+const maxSteps = 10
+
+// ErrInvalidScale is returned when a color scale can't be parsed.
+var ErrInvalidScale = errors.New("invalid color scale")
+
+// Step colors the percentages below Below.
+type Step struct {
+	Color string
+	Below float64
+}
+
+// Scale picks the color of a percentage from thresholds, written as
+// "red:60,yellow:80,green" for red below 60, yellow below 80 and green above.
+type Scale struct {
+	// Steps have increasing bounds.
+	Steps []Step
+	// Above colors the percentages reaching the bound of the last step.
+	Above string
+}
+
+// ParseScale parses and validates a scale written as Scale.String does.
+func ParseScale(text string) (Scale, error) {
+	items := strings.Split(text, ",")
+	if len(items) > maxSteps {
+		return Scale{}, fmt.Errorf("%w: at most %d colors", ErrInvalidScale, maxSteps)
+	}
+
+	var scale Scale
+	for i, item := range items {
+		color, bound, hasBound := strings.Cut(strings.TrimSpace(item), ":")
+		if !ValidColor(color) {
+			return Scale{}, fmt.Errorf("%w: unknown color %q", ErrInvalidScale, color)
+		}
+
+		if i == len(items)-1 {
+			if hasBound {
+				return Scale{}, fmt.Errorf("%w: the last color must have no bound", ErrInvalidScale)
+			}
+			scale.Above = color
+			break
+		}
+
+		below, err := strconv.ParseFloat(bound, 64)
+		if !hasBound || err != nil || below < 0 || below > 100 {
+			return Scale{}, fmt.Errorf("%w: %q must be a color and a percentage bound", ErrInvalidScale, item)
+		}
+		if len(scale.Steps) > 0 && below <= scale.Steps[len(scale.Steps)-1].Below {
+			return Scale{}, fmt.Errorf("%w: bounds must increase", ErrInvalidScale)
+		}
+		scale.Steps = append(scale.Steps, Step{Color: color, Below: below})
+	}
+
+	return scale, nil
+}
+
+// Color returns the color of percent.
+func (s Scale) Color(percent float64) string {
+	for _, step := range s.Steps {
+		if percent < step.Below {
+			return step.Color
+		}
+	}
+
+	return s.Above
+}
+
+func (s Scale) String() string {
+	items := make([]string, 0, len(s.Steps)+1)
+	for _, step := range s.Steps {
+		items = append(items, step.Color+":"+strconv.FormatFloat(step.Below, 'f', -1, 64))
+	}
+
+	return strings.Join(append(items, s.Above), ",")
+}
@@ -0,0 +1,33 @@
+package badge
+
+// verdanaWidths holds the advance widths of the printable ASCII characters of
+// Verdana, in units of its 2048 units em, starting at the space.
+var verdanaWidths = [...]int{
+	720, 806, 940, 1716, 1302, 2476, 1538, 550, 1002, 1002, 1302, 1716, 748, 1002, 748, 1002, // space to /
+	1302, 1302, 1302, 1302, 1302, 1302, 1302, 1302, 1302, 1302, // 0 to 9
+	1002, 1002, 1716, 1716, 1716, 1120, 2048, // : to @
+	1401, 1405, 1430, 1577, 1294, 1178, 1587, 1540, 862, 1002, 1425, 1145, 1744, 1540, 1612, 1233, // A to P
+	1612, 1427, 1399, 1237, 1501, 1401, 2025, 1403, 1237, 1403, // Q to Z
+	1002, 1002, 1002, 1716, 1302, 1302, // [ to `
+	1229, 1276, 1067, 1276, 1208, 720, 1276, 1296, 562, 705, 1186, 562, 1992, 1296, 1243, 1276, // a to p
+	1276, 874, 1067, 807, 1296, 1186, 1673, 1186, 1186, 1075, // q to z
+	1303, 1002, 1303, 1716, // { to ~
+}
+
+// wideWidth is the width assumed for characters outside printable ASCII, the
+// one of the widest latin letter.
+const wideWidth = 2025
+
+// TextWidth returns the width in pixels of text rendered in Verdana at size pixels.
+func TextWidth(text string, size float64) float64 {
+	units := 0
+	for _, char := range text {
+		if char >= ' ' && int(char-' ') < len(verdanaWidths) {
+			units += verdanaWidths[char-' ']
+		} else {
+			units += wideWidth
+		}
+	}
+
+	return float64(units) * size / 2048
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
@@ -9,10 +10,19 @@ import (
 type config struct {
 	DBConnStr string
 	APIKey    string
+	// BadgeCacheControl is the Cache-Control header of the public badges.
+	BadgeCacheControl string
+	// BadgeCacheTTL is how long the rows read by badges are cached, 0 to disable it.
+	BadgeCacheTTL time.Duration
 }
 
 var Config *config
 
+const (
+	defaultBadgeCacheControl = "max-age=300"
+	defaultBadgeCacheTTL     = time.Minute
+)
+
 func LoadConfig() {
 	dbConnStr := os.Getenv("GOVERAGE_DB_CONN_STR")
 	if dbConnStr == "" {
@@ -24,8 +34,24 @@ func LoadConfig() {
 		log.Fatal().Msg("GOVERAGE_API_KEY is required")
 	}
 
+	badgeCacheControl := os.Getenv("GOVERAGE_BADGE_CACHE_CONTROL")
+	if badgeCacheControl == "" {
+		badgeCacheControl = defaultBadgeCacheControl
+	}
+
+	badgeCacheTTL := defaultBadgeCacheTTL
+	if value := os.Getenv("GOVERAGE_BADGE_CACHE_TTL"); value != "" {
+		var err error
+		badgeCacheTTL, err = time.ParseDuration(value)
+		if err != nil || badgeCacheTTL < 0 {
+			log.Fatal().Msg("GOVERAGE_BADGE_CACHE_TTL must be a duration such as 30s, 0 disabling the cache")
+		}
+	}
+
 	Config = &config{
-		DBConnStr: dbConnStr,
-		APIKey:    apiKey,
+		DBConnStr:         dbConnStr,
+		APIKey:            apiKey,
+		BadgeCacheControl: badgeCacheControl,
+		BadgeCacheTTL:     badgeCacheTTL,
 	}
 }
@@ -0,0 +1,34 @@
+package coverage
+
+// Weighted is a coverage weighing in a mean, such as the coverage of a project
+// weighted by its number of statements.
+type Weighted struct {
+	Coverage float64
+	Weight   int
+}
+
+// Weight returns the weight of a report in an aggregate: its statements, or
+// its lines for formats that don't count statements.
+func Weight(statements, lines int) int {
+	if statements > 0 {
+		return statements
+	}
+
+	return lines
+}
+
+// WeightedMean returns the mean of coverages weighted by their weights, or 0
+// when nothing weighs.
+func WeightedMean(coverages []Weighted) float64 {
+	var sum, weights float64
+	for _, weighted := range coverages {
+		sum += weighted.Coverage * float64(weighted.Weight)
+		weights += float64(weighted.Weight)
+	}
+
+	if weights == 0 {
+		return 0
+	}
+
+	return sum / weights
+}
@@ -0,0 +1,18 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeight(t *testing.T) {
+	assert.Equal(t, 10, Weight(10, 12))
+	assert.Equal(t, 12, Weight(0, 12))
+}
+
+func TestWeightedMean(t *testing.T) {
+	assert.Equal(t, 80.0, WeightedMean([]Weighted{{Coverage: 100, Weight: 300}, {Coverage: 20, Weight: 100}}))
+	assert.Equal(t, 0.0, WeightedMean([]Weighted{{Coverage: 100, Weight: 0}}))
+	assert.Equal(t, 0.0, WeightedMean(nil))
+}
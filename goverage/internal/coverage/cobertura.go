@@ -0,0 +1,218 @@
+package coverage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//nolint:tagliatelle // Cobertura attributes are kebab-case.
+type coberturaXML struct {
+	LineRate        float64 `xml:"line-rate,attr"`
+	LinesCovered    *int    `xml:"lines-covered,attr"`
+	LinesValid      *int    `xml:"lines-valid,attr"`
+	BranchesCovered *int    `xml:"branches-covered,attr"`
+	BranchesValid   *int    `xml:"branches-valid,attr"`
+	Timestamp       string  `xml:"timestamp,attr"`
+	Packages        []struct {
+		Name    string `xml:"name,attr"`
+		Classes []struct {
+			Name     string `xml:"name,attr"`
+			Filename string `xml:"filename,attr"`
+			Methods  []struct {
+				Name  string          `xml:"name,attr"`
+				Lines []coberturaLine `xml:"lines>line"`
+			} `xml:"methods>method"`
+			Lines []coberturaLine `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+//nolint:tagliatelle // Cobertura attributes are kebab-case.
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int64  `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr"`
+}
+
+type coberturaParser struct{}
+
+func (coberturaParser) Format() Format {
+	return FormatCobertura
+}
+
+// Detect matches a `<coverage>` root element carrying a `line-rate` attribute.
+func (coberturaParser) Detect(content []byte) bool {
+	root, ok := xmlRootElement(content)
+	if !ok || root.Name.Local != "coverage" {
+		return false
+	}
+
+	for _, attr := range root.Attr {
+		if attr.Name.Local == "line-rate" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (coberturaParser) Parse(content []byte) (*Report, error) {
+	var cobertura coberturaXML
+	if err := xml.Unmarshal(content, &cobertura); err != nil {
+		return nil, fmt.Errorf("failed to decode cobertura report: %w", err)
+	}
+
+	report := &Report{
+		Timestamp: parseCoberturaTimestamp(cobertura.Timestamp),
+		Coverage:  cobertura.LineRate * 100,
+		Files:     map[string]*File{},
+	}
+
+	lines := map[string]map[int]Line{}
+	for _, pkg := range cobertura.Packages {
+		for _, class := range pkg.Classes {
+			path := class.Filename
+			if path == "" {
+				path = class.Name
+			}
+
+			file, ok := report.Files[path]
+			if !ok {
+				file = &File{}
+				report.Files[path] = file
+				lines[path] = map[int]Line{}
+			}
+
+			// Inner classes are reported separately but share their file's lines.
+			for _, line := range class.Lines {
+				existing, ok := lines[path][line.Number]
+				if ok && existing.Hits >= line.Hits {
+					continue
+				}
+				lines[path][line.Number] = line.toLine()
+			}
+
+			for _, method := range class.Methods {
+				function := Function{Name: class.Name + "." + method.Name}
+				for i, line := range method.Lines {
+					if i == 0 || line.Number < function.Line {
+						function.Line = line.Number
+					}
+					function.Hits = max(function.Hits, line.Hits)
+				}
+				file.Functions = append(file.Functions, function)
+			}
+		}
+	}
+
+	for path, file := range report.Files {
+		file.Lines = make([]Line, 0, len(lines[path]))
+		for _, line := range lines[path] {
+			file.Lines = append(file.Lines, line)
+		}
+		sort.Slice(file.Lines, func(i, j int) bool { return file.Lines[i].Number < file.Lines[j].Number })
+
+		file.Totals = Totals{
+			Lines:     linesCounter(file.Lines),
+			Branches:  lineBranchesCounter(file.Lines),
+			Functions: functionsCounter(file.Functions),
+		}
+		report.Totals.Add(file.Totals)
+	}
+
+	// Prefer the totals written by the generator, they account for excluded sources.
+	if cobertura.LinesCovered != nil && cobertura.LinesValid != nil {
+		report.Totals.Lines = Counter{Covered: *cobertura.LinesCovered, Total: *cobertura.LinesValid}
+	}
+	if cobertura.BranchesCovered != nil && cobertura.BranchesValid != nil {
+		report.Totals.Branches = Counter{Covered: *cobertura.BranchesCovered, Total: *cobertura.BranchesValid}
+	}
+
+	return report, nil
+}
+
+func (l coberturaLine) toLine() Line {
+	line := Line{Number: l.Number, Hits: l.Hits}
+	if !l.Branch {
+		return line
+	}
+
+	// condition-coverage looks like "50% (1/2)".
+	_, conditions, ok := strings.Cut(l.ConditionCoverage, "(")
+	if !ok {
+		return line
+	}
+
+	coveredStr, totalStr, ok := strings.Cut(strings.TrimSuffix(conditions, ")"), "/")
+	if !ok {
+		return line
+	}
+
+	covered, err := strconv.Atoi(strings.TrimSpace(coveredStr))
+	if err != nil {
+		return line
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(totalStr))
+	if err != nil {
+		return line
+	}
+
+	line.Branches = &Counter{Covered: covered, Total: total}
+
+	return line
+}
+
+// parseCoberturaTimestamp handles the seconds and milliseconds variants written by
+// the different generators, a missing or invalid timestamp results in a zero time.
+func parseCoberturaTimestamp(value string) time.Time {
+	timestamp, err := strconv.ParseFloat(value, 64)
+	if err != nil || timestamp <= 0 {
+		return time.Time{}
+	}
+
+	if timestamp > 1e11 {
+		return time.UnixMilli(int64(timestamp)).UTC()
+	}
+
+	seconds, fraction := math.Modf(timestamp)
+
+	return time.Unix(int64(seconds), int64(fraction*1e9)).UTC()
+}
+
+func lineBranchesCounter(lines []Line) Counter {
+	var counter Counter
+	for _, line := range lines {
+		if line.Branches != nil {
+			counter.Add(*line.Branches)
+		}
+	}
+
+	return counter
+}
+
+// xmlRootElement returns the first element of an XML document.
+func xmlRootElement(content []byte) (xml.StartElement, bool) {
+	trimmed := bytes.TrimLeft(content, " \t\r\n\ufeff")
+	if !bytes.HasPrefix(trimmed, []byte("<")) {
+		return xml.StartElement{}, false
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, false
+		}
+
+		if element, ok := token.(xml.StartElement); ok {
+			return element, true
+		}
+	}
+}
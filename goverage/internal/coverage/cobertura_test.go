@@ -0,0 +1,91 @@
+package coverage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const coberturaReport = `<?xml version="1.0" ?>
+<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">
+<coverage line-rate="0.6667" branch-rate="0.5" lines-covered="4" lines-valid="6" branches-covered="1" branches-valid="2" version="7.4.4" timestamp="1711570913123">
+	<sources><source>/home/runner/app</source></sources>
+	<packages>
+		<package name="com.example" line-rate="0.6667" branch-rate="0.5">
+			<classes>
+				<class name="Main" filename="com/example/Main.java" line-rate="0.75" branch-rate="0.5">
+					<methods>
+						<method name="run" signature="()V" line-rate="1" branch-rate="0.5">
+							<lines><line number="3" hits="2"/></lines>
+						</method>
+						<method name="stop" signature="()V" line-rate="0" branch-rate="1">
+							<lines><line number="6" hits="0"/></lines>
+						</method>
+					</methods>
+					<lines>
+						<line number="3" hits="2" branch="true" condition-coverage="50% (1/2)"/>
+						<line number="4" hits="2"/>
+						<line number="6" hits="0"/>
+					</lines>
+				</class>
+				<class name="Main$Inner" filename="com/example/Main.java" line-rate="1" branch-rate="1">
+					<lines><line number="9" hits="1"/></lines>
+				</class>
+				<class name="Util" filename="com/example/Util.java" line-rate="0.5" branch-rate="1">
+					<lines>
+						<line number="1" hits="1"/>
+						<line number="2" hits="0"/>
+					</lines>
+				</class>
+			</classes>
+		</package>
+	</packages>
+</coverage>`
+
+func TestParseCobertura(t *testing.T) {
+	t.Run("UsesReportRatesAndTotals", func(t *testing.T) {
+		report, err := Parse([]byte(coberturaReport), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, FormatCobertura, report.Format)
+		assert.InDelta(t, 66.67, report.Coverage, 0.001)
+		assert.Equal(t, Counter{Covered: 4, Total: 6}, report.Totals.Lines)
+		assert.Equal(t, Counter{Covered: 1, Total: 2}, report.Totals.Branches)
+		assert.Equal(t, Counter{Covered: 1, Total: 2}, report.Totals.Functions)
+		assert.Equal(t, time.UnixMilli(1711570913123).UTC(), report.Timestamp)
+	})
+
+	t.Run("MergesClassesOfTheSameFile", func(t *testing.T) {
+		report, err := Parse([]byte(coberturaReport), FormatCobertura)
+
+		require.NoError(t, err)
+		require.Len(t, report.Files, 2)
+		main := report.Files["com/example/Main.java"]
+		assert.Equal(t, []Line{
+			{Number: 3, Hits: 2, Branches: &Counter{Covered: 1, Total: 2}},
+			{Number: 4, Hits: 2},
+			{Number: 6, Hits: 0},
+			{Number: 9, Hits: 1},
+		}, main.Lines)
+		assert.Equal(t, Counter{Covered: 3, Total: 4}, main.Totals.Lines)
+		assert.Equal(t, []Function{{Name: "Main.run", Line: 3, Hits: 2}, {Name: "Main.stop", Line: 6, Hits: 0}}, main.Functions)
+	})
+
+	t.Run("ComputesTotalsWhenMissing", func(t *testing.T) {
+		report, err := Parse([]byte(`<coverage line-rate="0.5" timestamp="1711570913"><packages><package name="p"><classes>
+			<class name="a.go" filename="a.go"><lines><line number="1" hits="1"/><line number="2" hits="0"/></lines></class>
+		</classes></package></packages></coverage>`), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, Counter{Covered: 1, Total: 2}, report.Totals.Lines)
+		assert.Equal(t, time.Unix(1711570913, 0).UTC(), report.Timestamp)
+	})
+
+	t.Run("IgnoresOtherXMLReports", func(t *testing.T) {
+		_, err := Parse([]byte(`<coverage generated="1711570913"><project/></coverage>`), "")
+
+		assert.ErrorIs(t, err, ErrUndetectedFormat)
+	})
+}
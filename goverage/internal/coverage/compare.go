@@ -0,0 +1,106 @@
+package coverage
+
+import "sort"
+
+// FileSummary is the stored coverage of a file of a commit.
+type FileSummary struct {
+	Coverage float64
+	Ranges   []LineRange
+}
+
+// FileComparison is the coverage of a file before and after a change.
+type FileComparison struct {
+	Path string
+	// Before is nil for added files, After for removed ones.
+	Before *float64
+	After  *float64
+	// NewlyCovered and NewlyUncovered list the lines of both versions whose
+	// coverage changed. Lines are compared by number, so lines moved by the
+	// change are compared with whatever line took their number.
+	NewlyCovered   []int
+	NewlyUncovered []int
+}
+
+// Comparison lists the files whose coverage differs between two commits.
+type Comparison struct {
+	Added   []FileComparison
+	Removed []FileComparison
+	Changed []FileComparison
+}
+
+// Compare compares the files of a base and head commit, keyed by path. Files
+// whose coverage and line hits didn't change are left out.
+func Compare(base, head map[string]FileSummary) Comparison {
+	comparison := Comparison{Added: []FileComparison{}, Removed: []FileComparison{}, Changed: []FileComparison{}}
+
+	for _, path := range sortedKeys(base, head) {
+		before, inBase := base[path]
+		after, inHead := head[path]
+
+		switch {
+		case !inBase:
+			comparison.Added = append(comparison.Added, FileComparison{
+				Path: path, After: &after.Coverage, NewlyCovered: []int{}, NewlyUncovered: []int{},
+			})
+		case !inHead:
+			comparison.Removed = append(comparison.Removed, FileComparison{
+				Path: path, Before: &before.Coverage, NewlyCovered: []int{}, NewlyUncovered: []int{},
+			})
+		default:
+			file := FileComparison{
+				Path:           path,
+				Before:         &before.Coverage,
+				After:          &after.Coverage,
+				NewlyCovered:   []int{},
+				NewlyUncovered: []int{},
+			}
+
+			beforeHits := lineHits(before.Ranges)
+			for number, hits := range lineHits(after.Ranges) {
+				previous, ok := beforeHits[number]
+				switch {
+				case !ok:
+				case previous == 0 && hits > 0:
+					file.NewlyCovered = append(file.NewlyCovered, number)
+				case previous > 0 && hits == 0:
+					file.NewlyUncovered = append(file.NewlyUncovered, number)
+				}
+			}
+			sort.Ints(file.NewlyCovered)
+			sort.Ints(file.NewlyUncovered)
+
+			if before.Coverage != after.Coverage || len(file.NewlyCovered) > 0 || len(file.NewlyUncovered) > 0 {
+				comparison.Changed = append(comparison.Changed, file)
+			}
+		}
+	}
+
+	return comparison
+}
+
+// lineHits expands ranges into the hits of every line.
+func lineHits(ranges []LineRange) map[int]int64 {
+	hits := map[int]int64{}
+	for _, lineRange := range ranges {
+		for number := lineRange.StartLine; number <= lineRange.EndLine; number++ {
+			hits[number] = lineRange.Hits
+		}
+	}
+
+	return hits
+}
+
+func sortedKeys(base, head map[string]FileSummary) []string {
+	paths := make([]string, 0, len(base)+len(head))
+	for path := range base {
+		paths = append(paths, path)
+	}
+	for path := range head {
+		if _, ok := base[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths
+}
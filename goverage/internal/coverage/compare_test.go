@@ -0,0 +1,43 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	base := map[string]FileSummary{
+		"main.go": {Coverage: 50, Ranges: []LineRange{
+			{StartLine: 1, EndLine: 2, Hits: 1},
+			{StartLine: 3, EndLine: 4, Hits: 0},
+		}},
+		"same.go":    {Coverage: 100, Ranges: []LineRange{{StartLine: 1, EndLine: 1, Hits: 1}}},
+		"removed.go": {Coverage: 0, Ranges: []LineRange{{StartLine: 1, EndLine: 1, Hits: 0}}},
+	}
+	head := map[string]FileSummary{
+		"main.go": {Coverage: 50, Ranges: []LineRange{
+			{StartLine: 1, EndLine: 1, Hits: 1},
+			{StartLine: 2, EndLine: 2, Hits: 0},
+			{StartLine: 3, EndLine: 3, Hits: 2},
+			{StartLine: 4, EndLine: 5, Hits: 0},
+		}},
+		"same.go":  {Coverage: 100, Ranges: []LineRange{{StartLine: 1, EndLine: 1, Hits: 3}}},
+		"added.go": {Coverage: 100, Ranges: []LineRange{{StartLine: 1, EndLine: 1, Hits: 1}}},
+	}
+
+	comparison := Compare(base, head)
+
+	assert.Equal(t, Comparison{
+		Added: []FileComparison{
+			{Path: "added.go", After: lo.ToPtr(100.0), NewlyCovered: []int{}, NewlyUncovered: []int{}},
+		},
+		Removed: []FileComparison{
+			{Path: "removed.go", Before: lo.ToPtr(0.0), NewlyCovered: []int{}, NewlyUncovered: []int{}},
+		},
+		Changed: []FileComparison{
+			{Path: "main.go", Before: lo.ToPtr(50.0), After: lo.ToPtr(50.0), NewlyCovered: []int{3}, NewlyUncovered: []int{2}},
+		},
+	}, comparison)
+}
@@ -0,0 +1,611 @@
+package coverage
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidGoCoverDir = errors.New("invalid go coverage directory")
+
+// Size limits of the coverage files extracted from an archive, which a small
+// compressed upload could otherwise expand into gigabytes.
+const (
+	maxCovFileSize = 16 << 20
+	maxCovDirSize  = 64 << 20
+)
+
+// Layout of the files written by binaries built with `go build -cover`, see
+// the internal/coverage package of the Go toolchain.
+const (
+	covMetaFilePrefix     = "covmeta."
+	covCounterFilePrefix  = "covcounters."
+	covMetaHeaderSize     = 56
+	covMetaPkgHeaderSize  = 44
+	covCounterHeaderSize  = 32
+	covCounterFooterSize  = 16
+	covModeSet            = 1
+	covGranularityPerFunc = 2
+	covFlavorRaw          = 1
+	covFlavorULEB128      = 2
+)
+
+var (
+	covMetaMagic    = []byte{0x00, 'c', 'v', 'm'}
+	covCounterMagic = []byte{0x00, 'c', 'w', 'm'}
+)
+
+type covUnit struct {
+	startLine, startCol, endLine, endCol, statements int
+}
+
+type covFunc struct {
+	file  string
+	units []covUnit
+}
+
+type covPackage struct {
+	path  string
+	funcs []covFunc
+}
+
+// covMetaFile is a decoded covmeta file, shared by every run of a binary.
+type covMetaFile struct {
+	mode        uint8
+	granularity uint8
+	packages    []covPackage
+}
+
+type covFuncKey struct {
+	metaHash        string
+	pkgIdx, funcIdx uint32
+}
+
+// ParseGoCoverDir parses a tar, gzipped tar or zip archive of a GOCOVERDIR
+// filled by binaries built with `go build -cover`.
+//
+// The counters of every run are merged the same way `go tool covdata merge`
+// does: summed in count and atomic modes, or-ed in set mode.
+func ParseGoCoverDir(archive []byte) (*Report, error) {
+	files, err := extractGoCoverDir(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	metas := map[string]*covMetaFile{}
+	for name, content := range files {
+		hash, ok := strings.CutPrefix(name, covMetaFilePrefix)
+		if !ok {
+			continue
+		}
+
+		meta, err := decodeCovMetaFile(content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidGoCoverDir, name, err)
+		}
+		metas[hash] = meta
+	}
+
+	if len(metas) == 0 {
+		return nil, fmt.Errorf("%w: no covmeta file", ErrInvalidGoCoverDir)
+	}
+
+	report := &Report{Files: map[string]*File{}, Packages: map[string]Totals{}}
+	counters := map[covFuncKey][]int64{}
+	for name, content := range files {
+		if !strings.HasPrefix(name, covCounterFilePrefix) {
+			continue
+		}
+
+		if err := decodeCovCounterFile(content, metas, counters); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidGoCoverDir, name, err)
+		}
+
+		// covcounters.<meta hash>.<pid>.<unix nanoseconds>
+		nanos, err := strconv.ParseInt(name[strings.LastIndex(name, ".")+1:], 10, 64)
+		if written := time.Unix(0, nanos).UTC(); err == nil && written.After(report.Timestamp) {
+			report.Timestamp = written
+		}
+	}
+
+	blocks := map[goCoverBlockKey]*Block{}
+	filePackages := map[string]string{}
+	for hash, meta := range metas {
+		for pkgIdx, pkg := range meta.packages {
+			for funcIdx, function := range pkg.funcs {
+				values := counters[covFuncKey{metaHash: hash, pkgIdx: uint32(pkgIdx), funcIdx: uint32(funcIdx)}]
+				filePackages[function.file] = pkg.path
+
+				for i, unit := range function.units {
+					var hits int64
+					if meta.granularity == covGranularityPerFunc && len(values) > 0 {
+						hits = values[0]
+					} else if i < len(values) {
+						hits = values[i]
+					}
+
+					if err := checkBlockLines(unit.startLine, unit.endLine); err != nil {
+						return nil, fmt.Errorf("%w: %s: %w", ErrInvalidGoCoverDir, function.file, err)
+					}
+
+					key := goCoverBlockKey{
+						file:      function.file,
+						startLine: unit.startLine,
+						startCol:  unit.startCol,
+						endLine:   unit.endLine,
+						endCol:    unit.endCol,
+					}
+
+					// Packages linked in several binaries are reported by each of them.
+					if existing, ok := blocks[key]; ok {
+						if meta.mode == covModeSet {
+							existing.Hits = max(existing.Hits, hits)
+						} else {
+							existing.Hits += hits
+						}
+
+						continue
+					}
+
+					blocks[key] = &Block{
+						StartLine:  unit.startLine,
+						StartCol:   unit.startCol,
+						EndLine:    unit.endLine,
+						EndCol:     unit.endCol,
+						Statements: unit.statements,
+						Hits:       hits,
+					}
+				}
+			}
+		}
+	}
+
+	if err := checkBlocksLines(blocks); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGoCoverDir, err)
+	}
+
+	for key, block := range blocks {
+		file, ok := report.Files[key.file]
+		if !ok {
+			file = &File{}
+			report.Files[key.file] = file
+		}
+
+		file.Blocks = append(file.Blocks, *block)
+	}
+
+	for filePath, file := range report.Files {
+		sort.Slice(file.Blocks, func(i, j int) bool {
+			if file.Blocks[i].StartLine != file.Blocks[j].StartLine {
+				return file.Blocks[i].StartLine < file.Blocks[j].StartLine
+			}
+
+			return file.Blocks[i].StartCol < file.Blocks[j].StartCol
+		})
+
+		file.Lines = linesFromBlocks(file.Blocks)
+		file.Totals = Totals{
+			Statements: statementsFromBlocks(file.Blocks),
+			Lines:      linesCounter(file.Lines),
+		}
+
+		report.Totals.Add(file.Totals)
+
+		pkgTotals := report.Packages[filePackages[filePath]]
+		pkgTotals.Add(file.Totals)
+		report.Packages[filePackages[filePath]] = pkgTotals
+	}
+
+	report.Coverage = report.Totals.Statements.Percent()
+	report.Format = FormatGoCoverDir
+
+	return report, nil
+}
+
+// extractGoCoverDir returns the covmeta and covcounters files of archive, keyed
+// by base name, failing when they exceed maxCovFileSize or maxCovDirSize.
+func extractGoCoverDir(archive []byte) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	remaining := int64(maxCovDirSize)
+	keep := func(name string) bool {
+		base := path.Base(name)
+		return strings.HasPrefix(base, covMetaFilePrefix) || strings.HasPrefix(base, covCounterFilePrefix)
+	}
+
+	switch {
+	case bytes.HasPrefix(archive, []byte("PK\x03\x04")):
+		reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidGoCoverDir, err)
+		}
+
+		for _, entry := range reader.File {
+			if entry.FileInfo().IsDir() || !keep(entry.Name) {
+				continue
+			}
+
+			content, err := readZipEntry(entry, &remaining)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrInvalidGoCoverDir, entry.Name, err)
+			}
+			files[path.Base(entry.Name)] = content
+		}
+	default:
+		var reader io.Reader = bytes.NewReader(archive)
+		if bytes.HasPrefix(archive, []byte{0x1f, 0x8b}) {
+			gzipReader, err := gzip.NewReader(reader)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidGoCoverDir, err)
+			}
+			defer gzipReader.Close()
+			reader = gzipReader
+		}
+
+		tarReader := tar.NewReader(reader)
+		for {
+			header, err := tarReader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidGoCoverDir, err)
+			}
+
+			if header.Typeflag != tar.TypeReg || !keep(header.Name) {
+				continue
+			}
+
+			content, err := readCovFile(tarReader, &remaining)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrInvalidGoCoverDir, header.Name, err)
+			}
+			files[path.Base(header.Name)] = content
+		}
+	}
+
+	return files, nil
+}
+
+func readZipEntry(entry *zip.File, remaining *int64) ([]byte, error) {
+	reader, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return readCovFile(reader, remaining)
+}
+
+// readCovFile reads a file of an archive of which remaining bytes may still
+// be extracted, and subtracts its size.
+func readCovFile(reader io.Reader, remaining *int64) ([]byte, error) {
+	limit := min(int64(maxCovFileSize), *remaining)
+	content, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("files exceed %d bytes or archive exceeds %d bytes", maxCovFileSize, maxCovDirSize)
+	}
+	*remaining -= int64(len(content))
+
+	return content, nil
+}
+
+// covReader decodes the little-endian and ULEB128 values of coverage data
+// files, the first out of bounds read is kept in err.
+type covReader struct {
+	content []byte
+	offset  int
+	err     error
+}
+
+func (r *covReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if n < 0 || r.offset+n > len(r.content) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+
+	value := r.content[r.offset : r.offset+n]
+	r.offset += n
+
+	return value
+}
+
+func (r *covReader) uint8() uint8 {
+	if value := r.bytes(1); value != nil {
+		return value[0]
+	}
+
+	return 0
+}
+
+func (r *covReader) uint32(order binary.ByteOrder) uint32 {
+	if value := r.bytes(4); value != nil {
+		return order.Uint32(value)
+	}
+
+	return 0
+}
+
+func (r *covReader) uint64() uint64 {
+	if value := r.bytes(8); value != nil {
+		return binary.LittleEndian.Uint64(value)
+	}
+
+	return 0
+}
+
+func (r *covReader) uleb128() uint64 {
+	var (
+		value uint64
+		shift uint
+	)
+
+	for {
+		b := r.uint8()
+		if r.err != nil {
+			return 0
+		}
+
+		value |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return value
+		}
+
+		shift += 7
+		if shift >= 64 {
+			r.err = errors.New("uleb128 value overflows")
+			return 0
+		}
+	}
+}
+
+func (r *covReader) stringTable() []string {
+	count := r.uleb128()
+	if count > uint64(len(r.content)) {
+		r.err = errors.New("invalid string table")
+		return nil
+	}
+
+	strs := make([]string, 0, count)
+	for range count {
+		strs = append(strs, string(r.bytes(int(r.uleb128()))))
+	}
+
+	return strs
+}
+
+func (r *covReader) seek(offset int) {
+	if offset < 0 || offset > len(r.content) {
+		r.err = io.ErrUnexpectedEOF
+		return
+	}
+
+	r.offset = offset
+}
+
+func decodeCovMetaFile(content []byte) (*covMetaFile, error) {
+	r := &covReader{content: content}
+	if !bytes.Equal(r.bytes(4), covMetaMagic) {
+		return nil, errors.New("not a covmeta file")
+	}
+
+	r.uint32(binary.LittleEndian) // version
+	r.uint64()                    // total length
+	numPackages := r.uint64()
+	r.bytes(16 + 4 + 4) // file hash and file level string table position
+	meta := &covMetaFile{mode: r.uint8(), granularity: r.uint8()}
+	r.seek(covMetaHeaderSize)
+
+	if r.err != nil || numPackages > uint64(len(content)) {
+		return nil, errors.New("truncated header")
+	}
+
+	offsets := make([]uint64, numPackages)
+	for i := range offsets {
+		offsets[i] = r.uint64()
+	}
+
+	for _, offset := range offsets {
+		if offset > uint64(len(content)) {
+			return nil, errors.New("invalid package offset")
+		}
+
+		pkg, err := decodeCovMetaPackage(content[offset:])
+		if err != nil {
+			return nil, err
+		}
+		meta.packages = append(meta.packages, pkg)
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return meta, nil
+}
+
+// decodeCovMetaPackage decodes the meta-data blob the compiler emits for a package.
+func decodeCovMetaPackage(content []byte) (covPackage, error) {
+	r := &covReader{content: content}
+	r.uint32(binary.LittleEndian) // length
+	r.uint32(binary.LittleEndian) // package name
+	pkgPathIdx := r.uint32(binary.LittleEndian)
+	r.uint32(binary.LittleEndian) // module path
+	r.bytes(16 + 4)               // package hash and padding
+	r.uint32(binary.LittleEndian) // number of files
+	numFuncs := r.uint32(binary.LittleEndian)
+	r.seek(covMetaPkgHeaderSize)
+
+	if r.err != nil || uint64(numFuncs)*4 > uint64(len(content)) {
+		return covPackage{}, errors.New("truncated package header")
+	}
+
+	offsets := make([]uint32, numFuncs)
+	for i := range offsets {
+		offsets[i] = r.uint32(binary.LittleEndian)
+	}
+
+	strs := r.stringTable()
+	if r.err != nil {
+		return covPackage{}, fmt.Errorf("invalid package string table: %w", r.err)
+	}
+	lookup := func(idx uint64) string {
+		if idx >= uint64(len(strs)) {
+			r.err = errors.New("invalid string table reference")
+			return ""
+		}
+
+		return strs[idx]
+	}
+
+	pkg := covPackage{path: lookup(uint64(pkgPathIdx)), funcs: make([]covFunc, 0, numFuncs)}
+	for _, offset := range offsets {
+		r.seek(int(offset))
+
+		numUnits := r.uleb128()
+		r.uleb128() // function name
+		function := covFunc{file: lookup(r.uleb128())}
+		if numUnits > uint64(len(content)) {
+			return covPackage{}, errors.New("invalid function unit count")
+		}
+
+		function.units = make([]covUnit, 0, numUnits)
+		for range numUnits {
+			function.units = append(function.units, covUnit{
+				startLine:  int(r.uleb128()),
+				startCol:   int(r.uleb128()),
+				endLine:    int(r.uleb128()),
+				endCol:     int(r.uleb128()),
+				statements: int(r.uleb128()),
+			})
+		}
+		r.uleb128() // function literal flag
+
+		pkg.funcs = append(pkg.funcs, function)
+	}
+
+	if r.err != nil {
+		return covPackage{}, fmt.Errorf("invalid package %s: %w", pkg.path, r.err)
+	}
+
+	return pkg, nil
+}
+
+// decodeCovCounterFile accumulates the counters of every segment of a
+// covcounters file into counters.
+func decodeCovCounterFile(content []byte, metas map[string]*covMetaFile, counters map[covFuncKey][]int64) error {
+	if len(content) < covCounterHeaderSize+covCounterFooterSize {
+		return errors.New("truncated file")
+	}
+
+	r := &covReader{content: content}
+	if !bytes.Equal(r.bytes(4), covCounterMagic) {
+		return errors.New("not a covcounters file")
+	}
+
+	r.uint32(binary.LittleEndian) // version
+	metaHash := hex.EncodeToString(r.bytes(16))
+	flavor := r.uint8()
+	var order binary.ByteOrder = binary.LittleEndian
+	if r.uint8() != 0 {
+		order = binary.BigEndian
+	}
+
+	meta, ok := metas[metaHash]
+	if !ok {
+		return fmt.Errorf("missing covmeta.%s file", metaHash)
+	}
+
+	readCounter := r.uleb128
+	switch flavor {
+	case covFlavorULEB128:
+	case covFlavorRaw:
+		readCounter = func() uint64 { return uint64(r.uint32(order)) }
+	default:
+		return fmt.Errorf("unknown counter flavor %d", flavor)
+	}
+
+	footer := &covReader{content: content, offset: len(content) - covCounterFooterSize}
+	if !bytes.Equal(footer.bytes(4), covCounterMagic) {
+		return errors.New("invalid footer")
+	}
+	footer.uint32(binary.LittleEndian)
+	numSegments := footer.uint32(binary.LittleEndian)
+
+	// A segment takes at least its function count and string table and
+	// arguments lengths.
+	if uint64(numSegments)*16 > uint64(len(content)) {
+		return errors.New("invalid segment count")
+	}
+
+	r.seek(covCounterHeaderSize)
+	for segment := range numSegments {
+		if r.err != nil {
+			return errors.New("truncated segment")
+		}
+
+		// Every segment but the first is preceded by the footer written with the previous one.
+		if segment > 0 {
+			r.bytes(covCounterFooterSize)
+		}
+
+		numFuncs := r.uint64()
+		strTabLen := r.uint32(binary.LittleEndian)
+		argsLen := r.uint32(binary.LittleEndian)
+		r.bytes(int(strTabLen) + int(argsLen))
+		if remainder := r.offset % 4; remainder != 0 {
+			r.bytes(4 - remainder)
+		}
+
+		for range numFuncs {
+			numCounters := readCounter()
+			key := covFuncKey{metaHash: metaHash, pkgIdx: uint32(readCounter()), funcIdx: uint32(readCounter())}
+			if r.err != nil || numCounters > uint64(len(content)) {
+				return errors.New("truncated counters")
+			}
+
+			values, ok := counters[key]
+			if !ok {
+				values = make([]int64, numCounters)
+				counters[key] = values
+			}
+
+			for i := range numCounters {
+				value := int64(readCounter())
+				if i >= uint64(len(values)) {
+					continue
+				}
+
+				if meta.mode == covModeSet {
+					values[i] = max(values[i], min(value, 1))
+				} else {
+					values[i] += value
+				}
+			}
+		}
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+
+	return nil
+}
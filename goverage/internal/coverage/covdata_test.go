@@ -0,0 +1,175 @@
+package coverage
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testdata/gocoverdir holds two runs, without and with an argument, of a
+// small program built with `go build -cover -covermode=count`.
+func readGoCoverDir(t *testing.T) map[string][]byte {
+	t.Helper()
+
+	entries, err := os.ReadDir("testdata/gocoverdir")
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join("testdata/gocoverdir", entry.Name()))
+		require.NoError(t, err)
+		files["cov/"+entry.Name()] = content
+	}
+
+	return files
+}
+
+func tarGoCoverDir(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tarWriter.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	return buf.Bytes()
+}
+
+func zipGoCoverDir(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for name, content := range files {
+		writer, err := zipWriter.Create(name)
+		require.NoError(t, err)
+		_, err = writer.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zipWriter.Close())
+
+	return buf.Bytes()
+}
+
+func TestParseGoCoverDir(t *testing.T) {
+	t.Run("MergesRunsOfTarArchive", func(t *testing.T) {
+		report, err := ParseGoCoverDir(tarGoCoverDir(t, readGoCoverDir(t)))
+
+		require.NoError(t, err)
+		assert.Equal(t, FormatGoCoverDir, report.Format)
+		assert.Equal(t, Counter{Covered: 6, Total: 8}, report.Totals.Statements)
+		assert.InDelta(t, 75.0, report.Coverage, 0.001)
+		assert.Equal(t, time.Date(2026, 10, 17, 6, 27, 18, 662051194, time.UTC), report.Timestamp)
+
+		assert.Equal(t, []Block{
+			{StartLine: 11, StartCol: 2, EndLine: 12, EndCol: 22, Statements: 2, Hits: 2},
+			{StartLine: 13, StartCol: 3, EndLine: 14, EndCol: 1, Statements: 1, Hits: 1},
+			{StartLine: 15, StartCol: 2, EndLine: 15, EndCol: 32, Statements: 1, Hits: 2},
+		}, report.Files["example.com/covapp/main.go"].Blocks)
+		assert.Equal(t, []Block{
+			{StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 16, Statements: 1, Hits: 2},
+			{StartLine: 5, StartCol: 3, EndLine: 6, EndCol: 1, Statements: 1, Hits: 0},
+			{StartLine: 8, StartCol: 2, EndLine: 8, EndCol: 24, Statements: 1, Hits: 2},
+			{StartLine: 12, StartCol: 2, EndLine: 13, EndCol: 1, Statements: 1, Hits: 0},
+		}, report.Files["example.com/covapp/greet/greet.go"].Blocks)
+
+		assert.Equal(t, map[string]Totals{
+			"example.com/covapp": {
+				Statements: Counter{Covered: 4, Total: 4},
+				Lines:      Counter{Covered: 5, Total: 5},
+			},
+			"example.com/covapp/greet": {
+				Statements: Counter{Covered: 2, Total: 4},
+				Lines:      Counter{Covered: 2, Total: 6},
+			},
+		}, report.Packages)
+	})
+
+	t.Run("ParsesZipArchive", func(t *testing.T) {
+		report, err := ParseGoCoverDir(zipGoCoverDir(t, readGoCoverDir(t)))
+
+		require.NoError(t, err)
+		assert.Len(t, report.Files, 2)
+		assert.Equal(t, Counter{Covered: 6, Total: 8}, report.Totals.Statements)
+	})
+
+	t.Run("ReportsUnexecutedBinaryAsUncovered", func(t *testing.T) {
+		files := map[string][]byte{}
+		for name, content := range readGoCoverDir(t) {
+			if strings.HasPrefix(filepath.Base(name), covMetaFilePrefix) {
+				files[name] = content
+			}
+		}
+
+		report, err := ParseGoCoverDir(tarGoCoverDir(t, files))
+
+		require.NoError(t, err)
+		assert.Equal(t, Counter{Covered: 0, Total: 8}, report.Totals.Statements)
+		assert.True(t, report.Timestamp.IsZero())
+	})
+
+	t.Run("RejectsCountersWithoutMetaFile", func(t *testing.T) {
+		files := readGoCoverDir(t)
+		files["cov/covmeta.00000000000000000000000000000000"] = files["cov/covmeta.7e0a0149d3e8851ba2372ca95489b074"]
+		delete(files, "cov/covmeta.7e0a0149d3e8851ba2372ca95489b074")
+
+		_, err := ParseGoCoverDir(tarGoCoverDir(t, files))
+
+		require.ErrorIs(t, err, ErrInvalidGoCoverDir)
+	})
+
+	t.Run("RejectsArchiveWithoutMetaFile", func(t *testing.T) {
+		_, err := ParseGoCoverDir(zipGoCoverDir(t, map[string][]byte{"README": []byte("hello")}))
+
+		require.ErrorIs(t, err, ErrInvalidGoCoverDir)
+	})
+
+	t.Run("RejectsOversizedFile", func(t *testing.T) {
+		files := readGoCoverDir(t)
+		files["cov/covcounters.7e0a0149d3e8851ba2372ca95489b074.1.1"] = make([]byte, maxCovFileSize+1)
+
+		for _, archive := range [][]byte{tarGoCoverDir(t, files), zipGoCoverDir(t, files)} {
+			_, err := ParseGoCoverDir(archive)
+
+			require.ErrorIs(t, err, ErrInvalidGoCoverDir)
+		}
+	})
+
+	t.Run("RejectsImplausibleSegmentCount", func(t *testing.T) {
+		files := readGoCoverDir(t)
+		name := "cov/covcounters.7e0a0149d3e8851ba2372ca95489b074.8256.1792218438643390226"
+		content := bytes.Clone(files[name])
+		binary.LittleEndian.PutUint32(content[len(content)-covCounterFooterSize+8:], 0xffffffff)
+		files[name] = content
+
+		_, err := ParseGoCoverDir(tarGoCoverDir(t, files))
+
+		require.ErrorIs(t, err, ErrInvalidGoCoverDir)
+	})
+
+	t.Run("RejectsTruncatedMetaFile", func(t *testing.T) {
+		files := readGoCoverDir(t)
+		name := "cov/covmeta.7e0a0149d3e8851ba2372ca95489b074"
+		files[name] = files[name][:100]
+
+		_, err := ParseGoCoverDir(tarGoCoverDir(t, files))
+
+		require.ErrorIs(t, err, ErrInvalidGoCoverDir)
+	})
+}
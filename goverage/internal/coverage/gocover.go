@@ -0,0 +1,283 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const goCoverModePrefix = "mode:"
+
+var ErrInvalidGoCoverProfile = errors.New("invalid go coverage profile")
+
+// Bounds of the lines blocks are spread over, so that a crafted report can't
+// make linesFromBlocks expand blocks over billions of lines.
+const (
+	maxLineNumber  = 1_000_000
+	maxBlocksLines = 10_000_000
+)
+
+type goCoverParser struct{}
+
+func (goCoverParser) Format() Format {
+	return FormatGo
+}
+
+// Detect matches the `mode:` header of `go test -coverprofile` output.
+func (goCoverParser) Detect(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(content, " \t\r\n"), []byte(goCoverModePrefix))
+}
+
+func (goCoverParser) Parse(content []byte) (*Report, error) {
+	return ParseGoCoverProfile(bytes.NewReader(content))
+}
+
+type goCoverBlockKey struct {
+	file                                 string
+	startLine, startCol, endLine, endCol int
+}
+
+// ParseGoCoverProfile parses a profile written by `go test -coverprofile` in
+// the set, count or atomic modes.
+//
+// Blocks reported more than once, which happens with -coverpkg, are merged the
+// same way `go tool cover` does.
+func ParseGoCoverProfile(r io.Reader) (*Report, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	mode := ""
+	blocks := map[goCoverBlockKey]*Block{}
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if mode == "" {
+			if !strings.HasPrefix(line, goCoverModePrefix) {
+				return nil, fmt.Errorf("%w: missing mode line", ErrInvalidGoCoverProfile)
+			}
+
+			mode = strings.TrimSpace(strings.TrimPrefix(line, goCoverModePrefix))
+			if mode != "set" && mode != "count" && mode != "atomic" {
+				return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidGoCoverProfile, mode)
+			}
+
+			continue
+		}
+
+		key, block, err := parseGoCoverLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidGoCoverProfile, lineNumber, err)
+		}
+
+		existing, ok := blocks[key]
+		if !ok {
+			blocks[key] = &block
+			continue
+		}
+
+		if mode == "set" {
+			existing.Hits = max(existing.Hits, block.Hits)
+		} else {
+			existing.Hits += block.Hits
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if mode == "" {
+		return nil, fmt.Errorf("%w: empty profile", ErrInvalidGoCoverProfile)
+	}
+
+	if err := checkBlocksLines(blocks); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGoCoverProfile, err)
+	}
+
+	report := &Report{Files: map[string]*File{}}
+	for key, block := range blocks {
+		file, ok := report.Files[key.file]
+		if !ok {
+			file = &File{}
+			report.Files[key.file] = file
+		}
+
+		file.Blocks = append(file.Blocks, *block)
+	}
+
+	for _, file := range report.Files {
+		sort.Slice(file.Blocks, func(i, j int) bool {
+			if file.Blocks[i].StartLine != file.Blocks[j].StartLine {
+				return file.Blocks[i].StartLine < file.Blocks[j].StartLine
+			}
+
+			return file.Blocks[i].StartCol < file.Blocks[j].StartCol
+		})
+
+		file.Lines = linesFromBlocks(file.Blocks)
+		file.Totals = Totals{
+			Statements: statementsFromBlocks(file.Blocks),
+			Lines:      linesCounter(file.Lines),
+		}
+
+		report.Totals.Add(file.Totals)
+	}
+
+	report.Coverage = report.Totals.Statements.Percent()
+
+	return report, nil
+}
+
+// parseGoCoverLine parses `file.go:startLine.startCol,endLine.endCol numStmt count`.
+func parseGoCoverLine(line string) (goCoverBlockKey, Block, error) {
+	var (
+		key   goCoverBlockKey
+		block Block
+	)
+
+	colon := strings.LastIndex(line, ":")
+	if colon <= 0 {
+		return key, block, errors.New("missing file name")
+	}
+	key.file = line[:colon]
+
+	fields := strings.Fields(line[colon+1:])
+	if len(fields) != 3 {
+		return key, block, errors.New("expected range, statements and count")
+	}
+
+	start, end, ok := strings.Cut(fields[0], ",")
+	if !ok {
+		return key, block, errors.New("invalid block range")
+	}
+
+	var err error
+	if key.startLine, key.startCol, err = parseGoCoverPosition(start); err != nil {
+		return key, block, err
+	}
+	if key.endLine, key.endCol, err = parseGoCoverPosition(end); err != nil {
+		return key, block, err
+	}
+
+	if block.Statements, err = strconv.Atoi(fields[1]); err != nil {
+		return key, block, fmt.Errorf("invalid statement count: %w", err)
+	}
+	if block.Hits, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+		return key, block, fmt.Errorf("invalid hit count: %w", err)
+	}
+
+	block.StartLine, block.StartCol = key.startLine, key.startCol
+	block.EndLine, block.EndCol = key.endLine, key.endCol
+
+	if err := checkBlockLines(block.StartLine, block.EndLine); err != nil {
+		return key, block, err
+	}
+
+	return key, block, nil
+}
+
+// checkBlockLines rejects the line span of a block unless it is ordered and
+// within maxLineNumber.
+func checkBlockLines(startLine, endLine int) error {
+	if startLine < 1 || endLine < startLine || endLine > maxLineNumber {
+		return fmt.Errorf("invalid block lines %d to %d", startLine, endLine)
+	}
+
+	return nil
+}
+
+// checkBlocksLines rejects blocks spanning more than maxBlocksLines lines in all.
+func checkBlocksLines(blocks map[goCoverBlockKey]*Block) error {
+	lines := 0
+	for _, block := range blocks {
+		if block.Statements == 0 {
+			continue
+		}
+
+		lines += block.EndLine - block.StartLine + 1
+		if lines > maxBlocksLines {
+			return fmt.Errorf("blocks span more than %d lines", maxBlocksLines)
+		}
+	}
+
+	return nil
+}
+
+func parseGoCoverPosition(position string) (int, int, error) {
+	lineStr, colStr, ok := strings.Cut(position, ".")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid position %q", position)
+	}
+
+	line, err := strconv.Atoi(lineStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid position %q: %w", position, err)
+	}
+
+	col, err := strconv.Atoi(colStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid position %q: %w", position, err)
+	}
+
+	return line, col, nil
+}
+
+// linesFromBlocks spreads block hits over the lines they span, a line shared
+// by several blocks keeps the highest count.
+func linesFromBlocks(blocks []Block) []Line {
+	hits := map[int]int64{}
+	for _, block := range blocks {
+		if block.Statements == 0 {
+			continue
+		}
+
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			current, ok := hits[line]
+			if !ok || block.Hits > current {
+				hits[line] = block.Hits
+			}
+		}
+	}
+
+	lines := make([]Line, 0, len(hits))
+	for number, count := range hits {
+		lines = append(lines, Line{Number: number, Hits: count})
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].Number < lines[j].Number })
+
+	return lines
+}
+
+func statementsFromBlocks(blocks []Block) Counter {
+	var counter Counter
+	for _, block := range blocks {
+		counter.Total += block.Statements
+		if block.Hits > 0 {
+			counter.Covered += block.Statements
+		}
+	}
+
+	return counter
+}
+
+func linesCounter(lines []Line) Counter {
+	counter := Counter{Total: len(lines)}
+	for _, line := range lines {
+		if line.Hits > 0 {
+			counter.Covered++
+		}
+	}
+
+	return counter
+}
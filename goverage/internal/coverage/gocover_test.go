@@ -0,0 +1,101 @@
+package coverage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goCoverProfile = `mode: count
+example.com/app/main.go:5.13,7.2 1 3
+example.com/app/main.go:9.20,10.12 1 1
+example.com/app/main.go:10.12,12.3 1 0
+example.com/app/main.go:13.2,13.10 1 1
+example.com/app/util/util.go:3.20,5.2 2 0
+`
+
+func TestParseGoCoverProfile(t *testing.T) {
+	t.Run("ComputesStatementCoverage", func(t *testing.T) {
+		report, err := ParseGoCoverProfile(strings.NewReader(goCoverProfile))
+
+		require.NoError(t, err)
+		assert.Equal(t, Counter{Covered: 3, Total: 6}, report.Totals.Statements)
+		assert.InDelta(t, 50.0, report.Coverage, 0.001)
+		assert.Len(t, report.Files, 2)
+
+		mainFile := report.Files["example.com/app/main.go"]
+		require.NotNil(t, mainFile)
+		assert.Equal(t, Counter{Covered: 3, Total: 4}, mainFile.Totals.Statements)
+		assert.Equal(t, []Line{
+			{Number: 5, Hits: 3},
+			{Number: 6, Hits: 3},
+			{Number: 7, Hits: 3},
+			{Number: 9, Hits: 1},
+			{Number: 10, Hits: 1},
+			{Number: 11, Hits: 0},
+			{Number: 12, Hits: 0},
+			{Number: 13, Hits: 1},
+		}, mainFile.Lines)
+		assert.Equal(t, Counter{Covered: 6, Total: 8}, mainFile.Totals.Lines)
+
+		utilFile := report.Files["example.com/app/util/util.go"]
+		require.NotNil(t, utilFile)
+		assert.Equal(t, Counter{Covered: 0, Total: 2}, utilFile.Totals.Statements)
+	})
+
+	t.Run("MergesDuplicateBlocksInCountMode", func(t *testing.T) {
+		report, err := ParseGoCoverProfile(strings.NewReader(`mode: atomic
+a.go:1.1,2.2 1 2
+a.go:1.1,2.2 1 5
+`))
+
+		require.NoError(t, err)
+		assert.Equal(t, []Block{{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, Statements: 1, Hits: 7}}, report.Files["a.go"].Blocks)
+	})
+
+	t.Run("MergesDuplicateBlocksInSetMode", func(t *testing.T) {
+		report, err := ParseGoCoverProfile(strings.NewReader(`mode: set
+a.go:1.1,2.2 1 0
+a.go:1.1,2.2 1 1
+`))
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), report.Files["a.go"].Blocks[0].Hits)
+		assert.InDelta(t, 100.0, report.Coverage, 0.001)
+	})
+
+	t.Run("RejectsMissingMode", func(t *testing.T) {
+		_, err := ParseGoCoverProfile(strings.NewReader("a.go:1.1,2.2 1 0\n"))
+
+		assert.ErrorIs(t, err, ErrInvalidGoCoverProfile)
+	})
+
+	t.Run("RejectsMalformedBlock", func(t *testing.T) {
+		_, err := ParseGoCoverProfile(strings.NewReader("mode: set\na.go:1.1 1 0\n"))
+
+		assert.ErrorIs(t, err, ErrInvalidGoCoverProfile)
+	})
+
+	t.Run("RejectsImplausibleBlockLines", func(t *testing.T) {
+		for _, block := range []string{"a.go:1.1,2000000000.1 1 1", "a.go:5.1,3.1 1 1", "a.go:0.1,2.1 1 1", "a.go:-4.1,2.1 1 1"} {
+			_, err := ParseGoCoverProfile(strings.NewReader("mode: set\n" + block + "\n"))
+
+			assert.ErrorIs(t, err, ErrInvalidGoCoverProfile, block)
+		}
+	})
+
+	t.Run("RejectsBlocksSpanningTooManyLines", func(t *testing.T) {
+		var profile strings.Builder
+		profile.WriteString("mode: set\n")
+		for i := range maxBlocksLines/maxLineNumber + 1 {
+			fmt.Fprintf(&profile, "f%d.go:1.1,%d.1 1 1\n", i, maxLineNumber)
+		}
+
+		_, err := ParseGoCoverProfile(strings.NewReader(profile.String()))
+
+		assert.ErrorIs(t, err, ErrInvalidGoCoverProfile)
+	})
+}
@@ -0,0 +1,235 @@
+package coverage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrInvalidIstanbul = errors.New("invalid istanbul report")
+
+type istanbulPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type istanbulLocation struct {
+	Start istanbulPosition `json:"start"`
+	End   istanbulPosition `json:"end"`
+}
+
+// istanbulFileCoverage is an entry of coverage-final.json.
+//
+//nolint:tagliatelle // Istanbul keys are camelCase.
+type istanbulFileCoverage struct {
+	Path         string                      `json:"path"`
+	StatementMap map[string]istanbulLocation `json:"statementMap"`
+	FnMap        map[string]struct {
+		Name string           `json:"name"`
+		Decl istanbulLocation `json:"decl"`
+		Line int              `json:"line"`
+	} `json:"fnMap"`
+	BranchMap map[string]struct {
+		Loc  istanbulLocation `json:"loc"`
+		Line int              `json:"line"`
+	} `json:"branchMap"`
+	S map[string]int64   `json:"s"`
+	F map[string]int64   `json:"f"`
+	B map[string][]int64 `json:"b"`
+}
+
+// istanbulSummary is an entry of coverage-summary.json.
+type istanbulSummary struct {
+	Lines      Counter `json:"lines"`
+	Statements Counter `json:"statements"`
+	Functions  Counter `json:"functions"`
+	Branches   Counter `json:"branches"`
+}
+
+func (s istanbulSummary) totals() Totals {
+	return Totals{
+		Statements: s.Statements,
+		Lines:      s.Lines,
+		Branches:   s.Branches,
+		Functions:  s.Functions,
+	}
+}
+
+type istanbulParser struct{}
+
+func (istanbulParser) Format() Format {
+	return FormatIstanbul
+}
+
+// Detect matches both coverage-final.json, whose entries carry a statementMap,
+// and coverage-summary.json, which has a `total` entry.
+func (istanbulParser) Detect(content []byte) bool {
+	var entries map[string]struct {
+		StatementMap json.RawMessage `json:"statementMap"` //nolint:tagliatelle // Istanbul keys are camelCase.
+		Statements   json.RawMessage `json:"statements"`
+	}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return false
+	}
+
+	if total, ok := entries["total"]; ok && total.Statements != nil {
+		return true
+	}
+
+	for _, entry := range entries {
+		if entry.StatementMap != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (istanbulParser) Parse(content []byte) (*Report, error) {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode istanbul report: %w", err)
+	}
+
+	if _, ok := entries["total"]; ok {
+		return parseIstanbulSummary(entries)
+	}
+
+	return parseIstanbulFinal(entries)
+}
+
+func parseIstanbulSummary(entries map[string]json.RawMessage) (*Report, error) {
+	report := &Report{Files: make(map[string]*File, len(entries)-1)}
+
+	for path, entry := range entries {
+		var summary istanbulSummary
+		if err := json.Unmarshal(entry, &summary); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidIstanbul, path, err)
+		}
+
+		if path == "total" {
+			report.Totals = summary.totals()
+			continue
+		}
+
+		report.Files[path] = &File{Totals: summary.totals(), Lines: []Line{}}
+	}
+
+	report.Coverage = report.Totals.Statements.Percent()
+
+	return report, nil
+}
+
+func parseIstanbulFinal(entries map[string]json.RawMessage) (*Report, error) {
+	report := &Report{Files: make(map[string]*File, len(entries))}
+
+	for key, entry := range entries {
+		var fileCoverage istanbulFileCoverage
+		if err := json.Unmarshal(entry, &fileCoverage); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidIstanbul, key, err)
+		}
+
+		path := fileCoverage.Path
+		if path == "" {
+			path = key
+		}
+
+		file := fileCoverage.toFile()
+		report.Files[path] = file
+		report.Totals.Add(file.Totals)
+	}
+
+	report.Coverage = report.Totals.Statements.Percent()
+
+	return report, nil
+}
+
+func (fc istanbulFileCoverage) toFile() *File {
+	file := &File{
+		Blocks:    make([]Block, 0, len(fc.StatementMap)),
+		Functions: make([]Function, 0, len(fc.FnMap)),
+	}
+
+	// Like istanbul, a line is as covered as the best statement starting on it.
+	lineHits := map[int]int64{}
+	for id, location := range fc.StatementMap {
+		hits := fc.S[id]
+		file.Blocks = append(file.Blocks, Block{
+			StartLine:  location.Start.Line,
+			StartCol:   location.Start.Column,
+			EndLine:    location.End.Line,
+			EndCol:     location.End.Column,
+			Statements: 1,
+			Hits:       hits,
+		})
+
+		if current, ok := lineHits[location.Start.Line]; !ok || hits > current {
+			lineHits[location.Start.Line] = hits
+		}
+	}
+	sort.Slice(file.Blocks, func(i, j int) bool {
+		if file.Blocks[i].StartLine != file.Blocks[j].StartLine {
+			return file.Blocks[i].StartLine < file.Blocks[j].StartLine
+		}
+
+		return file.Blocks[i].StartCol < file.Blocks[j].StartCol
+	})
+
+	// Branches are identified by their id in the branch map and their index.
+	lineBranches := map[int]map[string]bool{}
+	for id, branch := range fc.BranchMap {
+		line := branch.Line
+		if line == 0 {
+			line = branch.Loc.Start.Line
+		}
+
+		branches, ok := lineBranches[line]
+		if !ok {
+			branches = map[string]bool{}
+			lineBranches[line] = branches
+		}
+
+		for i, hits := range fc.B[id] {
+			branches[fmt.Sprintf("%s,%d", id, i)] = hits > 0
+		}
+	}
+
+	file.Lines = make([]Line, 0, len(lineHits))
+	for number, hits := range lineHits {
+		line := Line{Number: number, Hits: hits}
+		if branches, ok := lineBranches[number]; ok {
+			line.Branches = branchCounter(branches)
+			line.BranchesTaken = branches
+		}
+		file.Lines = append(file.Lines, line)
+	}
+	sort.Slice(file.Lines, func(i, j int) bool { return file.Lines[i].Number < file.Lines[j].Number })
+
+	for id, function := range fc.FnMap {
+		line := function.Decl.Start.Line
+		if line == 0 {
+			line = function.Line
+		}
+
+		file.Functions = append(file.Functions, Function{Name: function.Name, Line: line, Hits: fc.F[id]})
+	}
+	sort.Slice(file.Functions, func(i, j int) bool {
+		if file.Functions[i].Line != file.Functions[j].Line {
+			return file.Functions[i].Line < file.Functions[j].Line
+		}
+
+		return file.Functions[i].Name < file.Functions[j].Name
+	})
+
+	file.Totals = Totals{
+		Statements: statementsFromBlocks(file.Blocks),
+		Lines:      linesCounter(file.Lines),
+		Functions:  functionsCounter(file.Functions),
+	}
+	for _, branches := range lineBranches {
+		file.Totals.Branches.Add(*branchCounter(branches))
+	}
+
+	return file
+}
@@ -0,0 +1,89 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const istanbulFinalReport = `{
+	"/repo/src/app.js": {
+		"path": "/repo/src/app.js",
+		"statementMap": {
+			"0": {"start": {"line": 1, "column": 0}, "end": {"line": 1, "column": 20}},
+			"1": {"start": {"line": 3, "column": 2}, "end": {"line": 3, "column": 14}},
+			"2": {"start": {"line": 3, "column": 16}, "end": {"line": 3, "column": 30}},
+			"3": {"start": {"line": 6, "column": 2}, "end": {"line": 6, "column": null}}
+		},
+		"fnMap": {
+			"0": {"name": "main", "decl": {"start": {"line": 2, "column": 9}, "end": {"line": 2, "column": 13}}, "line": 2},
+			"1": {"name": "unused", "decl": {"start": {"line": 5, "column": 9}, "end": {"line": 5, "column": 15}}, "line": 5}
+		},
+		"branchMap": {
+			"0": {"loc": {"start": {"line": 3, "column": 2}, "end": {"line": 3, "column": 30}}, "type": "if", "line": 3}
+		},
+		"s": {"0": 1, "1": 2, "2": 0, "3": 0},
+		"f": {"0": 1, "1": 0},
+		"b": {"0": [2, 0]}
+	}
+}`
+
+const istanbulSummaryReport = `{
+	"total": {
+		"lines": {"total": 10, "covered": 8, "skipped": 0, "pct": 80},
+		"statements": {"total": 12, "covered": 9, "skipped": 0, "pct": 75},
+		"functions": {"total": 4, "covered": 2, "skipped": 0, "pct": 50},
+		"branches": {"total": 0, "covered": 0, "skipped": 0, "pct": "Unknown"}
+	},
+	"/repo/src/app.js": {
+		"lines": {"total": 10, "covered": 8, "skipped": 0, "pct": 80},
+		"statements": {"total": 12, "covered": 9, "skipped": 0, "pct": 75},
+		"functions": {"total": 4, "covered": 2, "skipped": 0, "pct": 50},
+		"branches": {"total": 0, "covered": 0, "skipped": 0, "pct": "Unknown"}
+	}
+}`
+
+func TestParseIstanbul(t *testing.T) {
+	t.Run("ParsesCoverageFinal", func(t *testing.T) {
+		report, err := Parse([]byte(istanbulFinalReport), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, FormatIstanbul, report.Format)
+		assert.Equal(t, Counter{Covered: 2, Total: 4}, report.Totals.Statements)
+		assert.Equal(t, Counter{Covered: 1, Total: 2}, report.Totals.Functions)
+		assert.Equal(t, Counter{Covered: 1, Total: 2}, report.Totals.Branches)
+		assert.InDelta(t, 50.0, report.Coverage, 0.001)
+
+		app := report.Files["/repo/src/app.js"]
+		require.NotNil(t, app)
+		assert.Equal(t, []Line{
+			{Number: 1, Hits: 1},
+			{
+				Number:        3,
+				Hits:          2,
+				Branches:      &Counter{Covered: 1, Total: 2},
+				BranchesTaken: map[string]bool{"0,0": true, "0,1": false},
+			},
+			{Number: 6, Hits: 0},
+		}, app.Lines)
+		assert.Equal(t, []Function{{Name: "main", Line: 2, Hits: 1}, {Name: "unused", Line: 5, Hits: 0}}, app.Functions)
+	})
+
+	t.Run("ParsesCoverageSummary", func(t *testing.T) {
+		report, err := Parse([]byte(istanbulSummaryReport), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, FormatIstanbul, report.Format)
+		assert.Equal(t, Counter{Covered: 9, Total: 12}, report.Totals.Statements)
+		assert.Equal(t, Counter{Covered: 8, Total: 10}, report.Totals.Lines)
+		assert.InDelta(t, 75.0, report.Coverage, 0.001)
+		assert.Equal(t, Counter{Covered: 2, Total: 4}, report.Files["/repo/src/app.js"].Totals.Functions)
+	})
+
+	t.Run("IgnoresOtherJSON", func(t *testing.T) {
+		_, err := Parse([]byte(`{"name": "package"}`), "")
+
+		assert.ErrorIs(t, err, ErrUndetectedFormat)
+	})
+}
@@ -0,0 +1,171 @@
+package coverage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type jacocoCounter struct {
+	Type    string `xml:"type,attr"`
+	Missed  int    `xml:"missed,attr"`
+	Covered int    `xml:"covered,attr"`
+}
+
+// jacocoGroup is either the report itself or a group of a multi-module report.
+type jacocoGroup struct {
+	Groups   []jacocoGroup   `xml:"group"`
+	Packages []jacocoPackage `xml:"package"`
+	Counters []jacocoCounter `xml:"counter"`
+}
+
+type jacocoReport struct {
+	jacocoGroup
+	Sessions []struct {
+		Dump int64 `xml:"dump,attr"`
+	} `xml:"sessioninfo"`
+}
+
+type jacocoPackage struct {
+	Name    string `xml:"name,attr"`
+	Classes []struct {
+		Name           string `xml:"name,attr"`
+		SourceFilename string `xml:"sourcefilename,attr"`
+		Methods        []struct {
+			Name     string          `xml:"name,attr"`
+			Line     int             `xml:"line,attr"`
+			Counters []jacocoCounter `xml:"counter"`
+		} `xml:"method"`
+	} `xml:"class"`
+	SourceFiles []struct {
+		Name  string `xml:"name,attr"`
+		Lines []struct {
+			Number          int   `xml:"nr,attr"`
+			MissedInstr     int64 `xml:"mi,attr"`
+			CoveredInstr    int64 `xml:"ci,attr"`
+			MissedBranches  int   `xml:"mb,attr"`
+			CoveredBranches int   `xml:"cb,attr"`
+		} `xml:"line"`
+		Counters []jacocoCounter `xml:"counter"`
+	} `xml:"sourcefile"`
+	Counters []jacocoCounter `xml:"counter"`
+}
+
+type jacocoParser struct{}
+
+func (jacocoParser) Format() Format {
+	return FormatJaCoCo
+}
+
+// Detect matches a `<report>` root element written by JaCoCo's XML formatter.
+func (jacocoParser) Detect(content []byte) bool {
+	root, ok := xmlRootElement(content)
+	if !ok || root.Name.Local != "report" {
+		return false
+	}
+
+	return bytes.Contains(content, []byte("JACOCO")) || bytes.Contains(content, []byte("<sessioninfo"))
+}
+
+func (jacocoParser) Parse(content []byte) (*Report, error) {
+	var jacoco jacocoReport
+	if err := xml.Unmarshal(content, &jacoco); err != nil {
+		return nil, fmt.Errorf("failed to decode jacoco report: %w", err)
+	}
+
+	report := &Report{
+		Totals:   jacocoTotals(jacoco.Counters),
+		Files:    map[string]*File{},
+		Packages: map[string]Totals{},
+	}
+	report.Coverage = report.Totals.Lines.Percent()
+
+	for _, session := range jacoco.Sessions {
+		dump := time.UnixMilli(session.Dump).UTC()
+		if session.Dump > 0 && dump.After(report.Timestamp) {
+			report.Timestamp = dump
+		}
+	}
+
+	var packages []jacocoPackage
+	groups := []jacocoGroup{jacoco.jacocoGroup}
+	for len(groups) > 0 {
+		group := groups[0]
+		groups = append(groups[1:], group.Groups...)
+		packages = append(packages, group.Packages...)
+	}
+
+	for _, pkg := range packages {
+		report.Packages[pkg.Name] = jacocoTotals(pkg.Counters)
+
+		for _, sourceFile := range pkg.SourceFiles {
+			file := &File{
+				Totals: jacocoTotals(sourceFile.Counters),
+				Lines:  make([]Line, 0, len(sourceFile.Lines)),
+			}
+
+			for _, jacocoLine := range sourceFile.Lines {
+				line := Line{Number: jacocoLine.Number, Hits: jacocoLine.CoveredInstr}
+				if branches := jacocoLine.MissedBranches + jacocoLine.CoveredBranches; branches > 0 {
+					line.Branches = &Counter{Covered: jacocoLine.CoveredBranches, Total: branches}
+				}
+				file.Lines = append(file.Lines, line)
+			}
+			sort.Slice(file.Lines, func(i, j int) bool { return file.Lines[i].Number < file.Lines[j].Number })
+
+			report.Files[jacocoPath(pkg.Name, sourceFile.Name)] = file
+		}
+
+		for _, class := range pkg.Classes {
+			file, ok := report.Files[jacocoPath(pkg.Name, class.SourceFilename)]
+			if !ok {
+				continue
+			}
+
+			className := class.Name[strings.LastIndex(class.Name, "/")+1:]
+			for _, method := range class.Methods {
+				instructions := jacocoTotals(method.Counters).Instructions
+				file.Functions = append(file.Functions, Function{
+					Name: className + "." + method.Name,
+					Line: method.Line,
+					Hits: int64(instructions.Covered),
+				})
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func jacocoPath(packageName, fileName string) string {
+	if packageName == "" {
+		return fileName
+	}
+
+	return packageName + "/" + fileName
+}
+
+func jacocoTotals(counters []jacocoCounter) Totals {
+	var totals Totals
+	for _, jacocoCounter := range counters {
+		counter := Counter{Covered: jacocoCounter.Covered, Total: jacocoCounter.Covered + jacocoCounter.Missed}
+
+		switch jacocoCounter.Type {
+		case "INSTRUCTION":
+			totals.Instructions = counter
+		case "LINE":
+			totals.Lines = counter
+		case "BRANCH":
+			totals.Branches = counter
+		case "METHOD":
+			totals.Functions = counter
+		case "CLASS":
+			totals.Classes = counter
+		}
+	}
+
+	return totals
+}
@@ -0,0 +1,86 @@
+package coverage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jacocoReportXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="service">
+	<sessioninfo id="a" start="1711570900000" dump="1711570913000"/>
+	<sessioninfo id="b" start="1711570900000" dump="1711570920000"/>
+	<group name="api">
+		<package name="com/example">
+			<class name="com/example/Main" sourcefilename="Main.kt">
+				<method name="run" desc="()V" line="3">
+					<counter type="INSTRUCTION" missed="0" covered="7"/>
+					<counter type="METHOD" missed="0" covered="1"/>
+				</method>
+				<method name="stop" desc="()V" line="8">
+					<counter type="INSTRUCTION" missed="3" covered="0"/>
+					<counter type="METHOD" missed="1" covered="0"/>
+				</method>
+			</class>
+			<sourcefile name="Main.kt">
+				<line nr="3" mi="0" ci="4" mb="1" cb="1"/>
+				<line nr="4" mi="0" ci="3" mb="0" cb="0"/>
+				<line nr="8" mi="3" ci="0" mb="0" cb="0"/>
+				<counter type="INSTRUCTION" missed="3" covered="7"/>
+				<counter type="LINE" missed="1" covered="2"/>
+				<counter type="BRANCH" missed="1" covered="1"/>
+				<counter type="METHOD" missed="1" covered="1"/>
+				<counter type="CLASS" missed="0" covered="1"/>
+			</sourcefile>
+			<counter type="INSTRUCTION" missed="3" covered="7"/>
+			<counter type="LINE" missed="1" covered="2"/>
+			<counter type="BRANCH" missed="1" covered="1"/>
+			<counter type="METHOD" missed="1" covered="1"/>
+			<counter type="CLASS" missed="0" covered="1"/>
+		</package>
+	</group>
+	<counter type="INSTRUCTION" missed="3" covered="7"/>
+	<counter type="LINE" missed="1" covered="2"/>
+	<counter type="BRANCH" missed="1" covered="1"/>
+	<counter type="METHOD" missed="1" covered="1"/>
+	<counter type="CLASS" missed="0" covered="1"/>
+	<counter type="COMPLEXITY" missed="2" covered="1"/>
+</report>`
+
+func TestParseJaCoCo(t *testing.T) {
+	t.Run("UsesReportCounters", func(t *testing.T) {
+		report, err := Parse([]byte(jacocoReportXML), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, FormatJaCoCo, report.Format)
+		assert.InDelta(t, 66.667, report.Coverage, 0.001)
+		assert.Equal(t, Totals{
+			Lines:        Counter{Covered: 2, Total: 3},
+			Branches:     Counter{Covered: 1, Total: 2},
+			Functions:    Counter{Covered: 1, Total: 2},
+			Instructions: Counter{Covered: 7, Total: 10},
+			Classes:      Counter{Covered: 1, Total: 1},
+		}, report.Totals)
+		assert.Equal(t, time.UnixMilli(1711570920000).UTC(), report.Timestamp)
+	})
+
+	t.Run("ReadsPackagesSourceFilesAndLines", func(t *testing.T) {
+		report, err := Parse([]byte(jacocoReportXML), FormatJaCoCo)
+
+		require.NoError(t, err)
+		assert.Equal(t, Counter{Covered: 7, Total: 10}, report.Packages["com/example"].Instructions)
+
+		main := report.Files["com/example/Main.kt"]
+		require.NotNil(t, main)
+		assert.Equal(t, Counter{Covered: 1, Total: 1}, main.Totals.Classes)
+		assert.Equal(t, []Line{
+			{Number: 3, Hits: 4, Branches: &Counter{Covered: 1, Total: 2}},
+			{Number: 4, Hits: 3},
+			{Number: 8, Hits: 0},
+		}, main.Lines)
+		assert.Equal(t, []Function{{Name: "Main.run", Line: 3, Hits: 7}, {Name: "Main.stop", Line: 8, Hits: 0}}, main.Functions)
+	})
+}
@@ -0,0 +1,310 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidLCOV = errors.New("invalid lcov tracefile")
+
+type lcovParser struct{}
+
+func (lcovParser) Format() Format {
+	return FormatLCOV
+}
+
+// Detect matches a tracefile starting with a test name or source file record.
+func (lcovParser) Detect(content []byte) bool {
+	trimmed := bytes.TrimLeft(content, " \t\r\n")
+
+	return bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.HasPrefix(trimmed, []byte("SF:"))
+}
+
+// lcovFile accumulates the records of a source file, which may appear in
+// several sections when the tracefile holds more than one test name.
+type lcovFile struct {
+	lines     map[int]int64
+	branches  map[int]map[string]bool
+	functions map[string]*Function
+
+	// Summary records, only used when the detailed records are missing.
+	linesFound, linesHit         int
+	branchesFound, branchesHit   int
+	functionsFound, functionsHit int
+}
+
+func (lcovParser) Parse(content []byte) (*Report, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	files := map[string]*lcovFile{}
+	var current *lcovFile
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if line == "end_of_record" {
+			current = nil
+			continue
+		}
+
+		record, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d: missing record type", ErrInvalidLCOV, lineNumber)
+		}
+
+		if record == "TN" {
+			continue
+		}
+
+		if record == "SF" {
+			file, ok := files[value]
+			if !ok {
+				file = &lcovFile{
+					lines:     map[int]int64{},
+					branches:  map[int]map[string]bool{},
+					functions: map[string]*Function{},
+				}
+				files[value] = file
+			}
+			current = file
+
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("%w: line %d: %s record outside of a source file", ErrInvalidLCOV, lineNumber, record)
+		}
+
+		if err := current.parseRecord(record, value); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidLCOV, lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no source file records", ErrInvalidLCOV)
+	}
+
+	report := &Report{Files: make(map[string]*File, len(files))}
+	for path, lcov := range files {
+		file := lcov.toFile()
+		report.Files[path] = file
+		report.Totals.Add(file.Totals)
+	}
+
+	report.Coverage = report.Totals.Lines.Percent()
+
+	return report, nil
+}
+
+func (f *lcovFile) parseRecord(record, value string) error {
+	fields := strings.Split(value, ",")
+
+	switch record {
+	case "DA":
+		// DA:<line>,<hits>[,<checksum>]
+		if len(fields) < 2 {
+			return errors.New("invalid DA record")
+		}
+
+		number, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid DA line: %w", err)
+		}
+
+		hits, err := parseLCOVHits(fields[1])
+		if err != nil {
+			return fmt.Errorf("invalid DA hits: %w", err)
+		}
+
+		f.lines[number] += hits
+	case "BRDA":
+		// BRDA:<line>,[<exception>]<block>,<branch>,<taken>
+		if len(fields) != 4 {
+			return errors.New("invalid BRDA record")
+		}
+
+		number, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid BRDA line: %w", err)
+		}
+
+		taken := false
+		if fields[3] != "-" {
+			hits, err := parseLCOVHits(fields[3])
+			if err != nil {
+				return fmt.Errorf("invalid BRDA taken count: %w", err)
+			}
+			taken = hits > 0
+		}
+
+		branches, ok := f.branches[number]
+		if !ok {
+			branches = map[string]bool{}
+			f.branches[number] = branches
+		}
+		id := fields[1] + "," + fields[2]
+		branches[id] = branches[id] || taken
+	case "FN":
+		// FN:<line>,<name> or FN:<line>,<end line>,<name>
+		if len(fields) < 2 {
+			return errors.New("invalid FN record")
+		}
+
+		number, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid FN line: %w", err)
+		}
+
+		name := strings.Join(fields[1:], ",")
+		if _, err := strconv.Atoi(fields[1]); err == nil && len(fields) > 2 {
+			name = strings.Join(fields[2:], ",")
+		}
+
+		if function, ok := f.functions[name]; ok {
+			function.Line = number
+		} else {
+			f.functions[name] = &Function{Name: name, Line: number}
+		}
+	case "FNDA":
+		// FNDA:<hits>,<name>
+		if len(fields) < 2 {
+			return errors.New("invalid FNDA record")
+		}
+
+		hits, err := parseLCOVHits(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid FNDA hits: %w", err)
+		}
+
+		name := strings.Join(fields[1:], ",")
+		function, ok := f.functions[name]
+		if !ok {
+			function = &Function{Name: name}
+			f.functions[name] = function
+		}
+		function.Hits += hits
+	case "LF":
+		return parseLCOVSummary(value, &f.linesFound)
+	case "LH":
+		return parseLCOVSummary(value, &f.linesHit)
+	case "BRF":
+		return parseLCOVSummary(value, &f.branchesFound)
+	case "BRH":
+		return parseLCOVSummary(value, &f.branchesHit)
+	case "FNF":
+		return parseLCOVSummary(value, &f.functionsFound)
+	case "FNH":
+		return parseLCOVSummary(value, &f.functionsHit)
+	}
+
+	// Unknown records, such as VER or FNL, are ignored.
+	return nil
+}
+
+func (f *lcovFile) toFile() *File {
+	file := &File{
+		Lines:     make([]Line, 0, len(f.lines)),
+		Functions: make([]Function, 0, len(f.functions)),
+	}
+
+	for number, hits := range f.lines {
+		line := Line{Number: number, Hits: hits}
+		if branches, ok := f.branches[number]; ok {
+			line.Branches = branchCounter(branches)
+			line.BranchesTaken = branches
+		}
+		file.Lines = append(file.Lines, line)
+	}
+	sort.Slice(file.Lines, func(i, j int) bool { return file.Lines[i].Number < file.Lines[j].Number })
+
+	for _, function := range f.functions {
+		file.Functions = append(file.Functions, *function)
+	}
+	sort.Slice(file.Functions, func(i, j int) bool {
+		if file.Functions[i].Line != file.Functions[j].Line {
+			return file.Functions[i].Line < file.Functions[j].Line
+		}
+
+		return file.Functions[i].Name < file.Functions[j].Name
+	})
+
+	file.Totals.Lines = linesCounter(file.Lines)
+	if len(f.lines) == 0 {
+		file.Totals.Lines = Counter{Covered: f.linesHit, Total: f.linesFound}
+	}
+
+	for _, branches := range f.branches {
+		file.Totals.Branches.Add(*branchCounter(branches))
+	}
+	if len(f.branches) == 0 {
+		file.Totals.Branches = Counter{Covered: f.branchesHit, Total: f.branchesFound}
+	}
+
+	file.Totals.Functions = functionsCounter(file.Functions)
+	if len(f.functions) == 0 {
+		file.Totals.Functions = Counter{Covered: f.functionsHit, Total: f.functionsFound}
+	}
+
+	return file
+}
+
+func branchCounter(branches map[string]bool) *Counter {
+	counter := &Counter{Total: len(branches)}
+	for _, taken := range branches {
+		if taken {
+			counter.Covered++
+		}
+	}
+
+	return counter
+}
+
+func functionsCounter(functions []Function) Counter {
+	counter := Counter{Total: len(functions)}
+	for _, function := range functions {
+		if function.Hits > 0 {
+			counter.Covered++
+		}
+	}
+
+	return counter
+}
+
+// parseLCOVHits parses a hit count, some generators emit floats or negative values.
+func parseLCOVHits(value string) (int64, error) {
+	hits, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if hits < 0 {
+		return 0, nil
+	}
+
+	return int64(hits), nil
+}
+
+func parseLCOVSummary(value string, target *int) error {
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid summary count: %w", err)
+	}
+	*target += count
+
+	return nil
+}
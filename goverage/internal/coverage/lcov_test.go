@@ -0,0 +1,93 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lcovTracefile = `TN:unit
+SF:src/app.js
+FN:1,main
+FN:8,helper
+FNDA:3,main
+FNDA:0,helper
+FNF:2
+FNH:1
+DA:1,3
+DA:2,3
+DA:3,0
+DA:8,0
+BRDA:2,0,0,3
+BRDA:2,0,1,-
+BRF:2
+BRH:1
+LF:4
+LH:2
+end_of_record
+TN:integration
+SF:src/app.js
+DA:3,1
+BRDA:2,0,1,1
+end_of_record
+SF:src/util.cpp
+FN:4,9,ns::add<int, int>
+FNDA:1,ns::add<int, int>
+DA:4,1
+DA:5,1
+end_of_record
+`
+
+func TestParseLCOV(t *testing.T) {
+	t.Run("DerivesLineBranchAndFunctionTotals", func(t *testing.T) {
+		report, err := Parse([]byte(lcovTracefile), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, FormatLCOV, report.Format)
+		assert.Equal(t, Counter{Covered: 5, Total: 6}, report.Totals.Lines)
+		assert.Equal(t, Counter{Covered: 2, Total: 2}, report.Totals.Branches)
+		assert.Equal(t, Counter{Covered: 2, Total: 3}, report.Totals.Functions)
+		assert.InDelta(t, 83.333, report.Coverage, 0.001)
+	})
+
+	t.Run("MergesSectionsOfTheSameFile", func(t *testing.T) {
+		report, err := Parse([]byte(lcovTracefile), FormatLCOV)
+
+		require.NoError(t, err)
+		app := report.Files["src/app.js"]
+		require.NotNil(t, app)
+		assert.Equal(t, []Line{
+			{Number: 1, Hits: 3},
+			{
+				Number:        2,
+				Hits:          3,
+				Branches:      &Counter{Covered: 2, Total: 2},
+				BranchesTaken: map[string]bool{"0,0": true, "0,1": true},
+			},
+			{Number: 3, Hits: 1},
+			{Number: 8, Hits: 0},
+		}, app.Lines)
+		assert.Equal(t, []Function{{Name: "main", Line: 1, Hits: 3}, {Name: "helper", Line: 8, Hits: 0}}, app.Functions)
+	})
+
+	t.Run("KeepsCommasInFunctionNames", func(t *testing.T) {
+		report, err := Parse([]byte(lcovTracefile), FormatLCOV)
+
+		require.NoError(t, err)
+		assert.Equal(t, []Function{{Name: "ns::add<int, int>", Line: 4, Hits: 1}}, report.Files["src/util.cpp"].Functions)
+	})
+
+	t.Run("FallsBackToSummaryRecords", func(t *testing.T) {
+		report, err := Parse([]byte("SF:a.c\nLF:10\nLH:4\nend_of_record\n"), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, Counter{Covered: 4, Total: 10}, report.Totals.Lines)
+	})
+
+	t.Run("RejectsRecordsOutsideOfSourceFile", func(t *testing.T) {
+		_, err := Parse([]byte("TN:\nDA:1,1\n"), "")
+
+		assert.ErrorIs(t, err, ErrInvalidLCOV)
+	})
+}
@@ -0,0 +1,226 @@
+package coverage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrMixedFormats = errors.New("cannot merge reports of different formats")
+
+// Merge combines the reports of test shards run for the same commit. Line,
+// block and function hits are summed, and totals are recomputed from them.
+// A branch identified by its format is taken when a shard took it. Counters
+// that can't be recomputed, such as the branches of formats only reporting
+// counts (Cobertura, JaCoCo), keep the highest value reported by a shard,
+// since a shard can't tell which of them the other shards covered: they are a
+// lower bound, which the headline coverage of these formats leaves out.
+func Merge(reports ...*Report) (*Report, error) {
+	if len(reports) == 0 {
+		return nil, errors.New("no report to merge")
+	}
+
+	merged := &Report{Format: reports[0].Format, Files: map[string]*File{}}
+	shardFiles := map[string][]*File{}
+	for _, report := range reports {
+		if report.Format != merged.Format {
+			return nil, fmt.Errorf("%w: %s and %s", ErrMixedFormats, merged.Format, report.Format)
+		}
+
+		if report.Timestamp.After(merged.Timestamp) {
+			merged.Timestamp = report.Timestamp
+		}
+
+		for path, file := range report.Files {
+			shardFiles[path] = append(shardFiles[path], file)
+		}
+	}
+
+	for path, files := range shardFiles {
+		file := mergeFiles(files)
+		merged.Files[path] = file
+		merged.Totals.Add(file.Totals)
+	}
+
+	merged.Coverage = headlineCoverage(merged.Format, merged.Totals)
+
+	// coverage_data returns coverage.py reports as uploaded, which a merge
+	// rewrites from the merged lines and arcs.
+	if merged.Format == FormatPython {
+		raw, err := pythonRawData(merged)
+		if err != nil {
+			return nil, err
+		}
+		merged.Raw = raw
+	}
+
+	return merged, nil
+}
+
+// headlineCoverage computes the coverage of totals the way the parser of format does.
+func headlineCoverage(format Format, totals Totals) float64 {
+	switch format {
+	case FormatPython:
+		// coverage.py includes branches in its percentage when they are measured.
+		return Counter{
+			Covered: totals.Lines.Covered + totals.Branches.Covered,
+			Total:   totals.Lines.Total + totals.Branches.Total,
+		}.Percent()
+	case FormatGo, FormatGoCoverDir, FormatIstanbul:
+		return totals.Statements.Percent()
+	default:
+		return totals.Lines.Percent()
+	}
+}
+
+func mergeFiles(files []*File) *File {
+	var (
+		lines     = map[int]*Line{}
+		blocks    = map[Block]*Block{}
+		functions = map[Function]*Function{}
+		reported  Totals
+	)
+
+	for _, file := range files {
+		for _, line := range file.Lines {
+			existing, ok := lines[line.Number]
+			if !ok {
+				line.Branches = copyCounter(line.Branches)
+				line.BranchesTaken = copyBranches(line.BranchesTaken)
+				lines[line.Number] = &line
+				continue
+			}
+
+			existing.Hits += line.Hits
+			mergeBranches(existing, line)
+		}
+
+		for _, block := range file.Blocks {
+			key := block
+			key.Hits = 0
+			if existing, ok := blocks[key]; ok {
+				existing.Hits += block.Hits
+				continue
+			}
+			blocks[key] = &block
+		}
+
+		for _, function := range file.Functions {
+			key := function
+			key.Hits = 0
+			if existing, ok := functions[key]; ok {
+				existing.Hits += function.Hits
+				continue
+			}
+			functions[key] = &function
+		}
+
+		reported = maxTotals(reported, file.Totals)
+	}
+
+	merged := &File{Totals: reported}
+
+	for _, line := range lines {
+		merged.Lines = append(merged.Lines, *line)
+	}
+	sort.Slice(merged.Lines, func(i, j int) bool { return merged.Lines[i].Number < merged.Lines[j].Number })
+
+	for _, block := range blocks {
+		merged.Blocks = append(merged.Blocks, *block)
+	}
+	sort.Slice(merged.Blocks, func(i, j int) bool {
+		if merged.Blocks[i].StartLine != merged.Blocks[j].StartLine {
+			return merged.Blocks[i].StartLine < merged.Blocks[j].StartLine
+		}
+
+		return merged.Blocks[i].StartCol < merged.Blocks[j].StartCol
+	})
+
+	for _, function := range functions {
+		merged.Functions = append(merged.Functions, *function)
+	}
+	sort.Slice(merged.Functions, func(i, j int) bool {
+		if merged.Functions[i].Line != merged.Functions[j].Line {
+			return merged.Functions[i].Line < merged.Functions[j].Line
+		}
+
+		return merged.Functions[i].Name < merged.Functions[j].Name
+	})
+
+	if len(merged.Lines) > 0 {
+		merged.Totals.Lines = linesCounter(merged.Lines)
+		if branches := lineBranchesCounter(merged.Lines); branches.Total > 0 {
+			merged.Totals.Branches = branches
+		}
+	}
+	if len(merged.Blocks) > 0 {
+		merged.Totals.Statements = statementsFromBlocks(merged.Blocks)
+	}
+	if len(merged.Functions) > 0 {
+		merged.Totals.Functions = functionsCounter(merged.Functions)
+	}
+
+	return merged
+}
+
+// mergeBranches adds the branches of line to existing, a line of another shard.
+func mergeBranches(existing *Line, line Line) {
+	if line.Branches == nil {
+		return
+	}
+
+	if existing.Branches == nil {
+		existing.Branches = copyCounter(line.Branches)
+		existing.BranchesTaken = copyBranches(line.BranchesTaken)
+		return
+	}
+
+	if existing.BranchesTaken == nil || line.BranchesTaken == nil {
+		*existing.Branches = maxCounter(*existing.Branches, *line.Branches)
+		existing.BranchesTaken = nil
+		return
+	}
+
+	for id, taken := range line.BranchesTaken {
+		existing.BranchesTaken[id] = existing.BranchesTaken[id] || taken
+	}
+	existing.Branches = branchCounter(existing.BranchesTaken)
+}
+
+func copyBranches(branches map[string]bool) map[string]bool {
+	if branches == nil {
+		return nil
+	}
+
+	copied := make(map[string]bool, len(branches))
+	for id, taken := range branches {
+		copied[id] = taken
+	}
+
+	return copied
+}
+
+func copyCounter(counter *Counter) *Counter {
+	if counter == nil {
+		return nil
+	}
+
+	copied := *counter
+
+	return &copied
+}
+
+func maxCounter(a, b Counter) Counter {
+	return Counter{Covered: max(a.Covered, b.Covered), Total: max(a.Total, b.Total)}
+}
+
+func maxTotals(a, b Totals) Totals {
+	return Totals{
+		Statements:   maxCounter(a.Statements, b.Statements),
+		Lines:        maxCounter(a.Lines, b.Lines),
+		Branches:     maxCounter(a.Branches, b.Branches),
+		Functions:    maxCounter(a.Functions, b.Functions),
+		Instructions: maxCounter(a.Instructions, b.Instructions),
+		Classes:      maxCounter(a.Classes, b.Classes),
+	}
+}
@@ -0,0 +1,141 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pythonShardA = `{
+		"meta": {"timestamp": "2024-03-27T20:01:53.123456"},
+		"files": {"app/main.py": {"executed_lines": [1, 2], "missing_lines": [3, 4], "summary": {"covered_lines": 2, "num_statements": 4}}},
+		"totals": {"covered_lines": 2, "num_statements": 4, "percent_covered": 50}
+	}`
+	pythonShardB = `{
+		"meta": {"timestamp": "2024-03-27T20:05:00.000000"},
+		"files": {
+			"app/main.py": {"executed_lines": [1, 3], "missing_lines": [2, 4], "summary": {"covered_lines": 2, "num_statements": 4}},
+			"app/util.py": {"executed_lines": [1], "missing_lines": [], "summary": {"covered_lines": 1, "num_statements": 1}}
+		},
+		"totals": {"covered_lines": 3, "num_statements": 5, "percent_covered": 60}
+	}`
+)
+
+func TestMerge(t *testing.T) {
+	t.Run("SumsLineHitsAcrossShards", func(t *testing.T) {
+		shardA, err := Parse([]byte(pythonShardA), FormatPython)
+		require.NoError(t, err)
+		shardB, err := Parse([]byte(pythonShardB), FormatPython)
+		require.NoError(t, err)
+
+		merged, err := Merge(shardA, shardB)
+
+		require.NoError(t, err)
+		assert.Equal(t, FormatPython, merged.Format)
+		assert.Equal(t, time.Date(2024, 3, 27, 20, 5, 0, 0, time.UTC), merged.Timestamp)
+		assert.Equal(t, []Line{
+			{Number: 1, Hits: 2},
+			{Number: 2, Hits: 1},
+			{Number: 3, Hits: 1},
+			{Number: 4, Hits: 0},
+		}, merged.Files["app/main.py"].Lines)
+		assert.Equal(t, Counter{Covered: 3, Total: 4}, merged.Files["app/main.py"].Totals.Lines)
+		assert.Equal(t, Counter{Covered: 4, Total: 5}, merged.Totals.Lines)
+		assert.InDelta(t, 80.0, merged.Coverage, 0.001)
+	})
+
+	t.Run("StoresMergedPythonReportsAsUploaded", func(t *testing.T) {
+		shardA, err := Parse([]byte(pythonShardA), FormatPython)
+		require.NoError(t, err)
+		shardB, err := Parse([]byte(pythonShardB), FormatPython)
+		require.NoError(t, err)
+		merged, err := Merge(shardA, shardB)
+		require.NoError(t, err)
+
+		raw, err := merged.RawData()
+		require.NoError(t, err)
+		parsed, err := ParseRawData(raw, FormatPython)
+
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), `"executed_lines":[1,2,3]`)
+		assert.Equal(t, merged.Timestamp, parsed.Timestamp)
+		assert.Equal(t, merged.Totals, parsed.Totals)
+		assert.InDelta(t, merged.Coverage, parsed.Coverage, 0.001)
+		assert.Equal(t, Counter{Covered: 3, Total: 4}, parsed.Files["app/main.py"].Totals.Lines)
+	})
+
+	t.Run("SumsBlockHitsAcrossShards", func(t *testing.T) {
+		shardA, err := ParseGoCoverProfile(strings.NewReader(
+			"mode: count\nexample.com/app/main.go:3.13,5.2 3 1\nexample.com/app/main.go:7.13,9.2 1 0\n",
+		))
+		require.NoError(t, err)
+		shardB, err := ParseGoCoverProfile(strings.NewReader(
+			"mode: count\nexample.com/app/main.go:3.13,5.2 3 2\nexample.com/app/main.go:7.13,9.2 1 4\n",
+		))
+		require.NoError(t, err)
+
+		merged, err := Merge(shardA, shardB)
+
+		require.NoError(t, err)
+		file := merged.Files["example.com/app/main.go"]
+		assert.Equal(t, []int64{3, 4}, []int64{file.Blocks[0].Hits, file.Blocks[1].Hits})
+		assert.Equal(t, Counter{Covered: 4, Total: 4}, merged.Totals.Statements)
+		assert.InDelta(t, 100.0, merged.Coverage, 0.001)
+	})
+
+	t.Run("TakesUnionOfIdentifiedBranches", func(t *testing.T) {
+		shardA, err := Parse([]byte(`{
+			"meta": {"timestamp": "2024-03-27T20:01:53"},
+			"files": {"app/main.py": {
+				"executed_lines": [1, 2], "missing_lines": [3],
+				"executed_branches": [[1, 2]], "missing_branches": [[1, 3]],
+				"summary": {"covered_lines": 2, "num_statements": 3, "num_branches": 2, "covered_branches": 1}
+			}},
+			"totals": {"covered_lines": 2, "num_statements": 3, "num_branches": 2, "covered_branches": 1, "percent_covered": 60}
+		}`), FormatPython)
+		require.NoError(t, err)
+		shardB, err := Parse([]byte(`{
+			"meta": {"timestamp": "2024-03-27T20:02:53"},
+			"files": {"app/main.py": {
+				"executed_lines": [1, 3], "missing_lines": [2],
+				"executed_branches": [[1, 3]], "missing_branches": [[1, 2]],
+				"summary": {"covered_lines": 2, "num_statements": 3, "num_branches": 2, "covered_branches": 1}
+			}},
+			"totals": {"covered_lines": 2, "num_statements": 3, "num_branches": 2, "covered_branches": 1, "percent_covered": 60}
+		}`), FormatPython)
+		require.NoError(t, err)
+
+		merged, err := Merge(shardA, shardB)
+
+		require.NoError(t, err)
+		assert.Equal(t, Counter{Covered: 2, Total: 2}, merged.Totals.Branches)
+		assert.Equal(t, map[string]bool{"2": true, "3": true}, merged.Files["app/main.py"].Lines[0].BranchesTaken)
+		assert.InDelta(t, 100.0, merged.Coverage, 0.001)
+		assert.Equal(t, map[string]bool{"2": true, "3": false}, shardA.Files["app/main.py"].Lines[0].BranchesTaken)
+	})
+
+	t.Run("KeepsHighestBranchesWithoutIds", func(t *testing.T) {
+		shardA := &Report{Format: FormatCobertura, Files: map[string]*File{
+			"a.c": {Lines: []Line{{Number: 1, Hits: 1, Branches: &Counter{Covered: 1, Total: 2}}}},
+		}}
+		shardB := &Report{Format: FormatCobertura, Files: map[string]*File{
+			"a.c": {Lines: []Line{{Number: 1, Hits: 1, Branches: &Counter{Covered: 2, Total: 2}}}},
+		}}
+
+		merged, err := Merge(shardA, shardB)
+
+		require.NoError(t, err)
+		assert.Equal(t, Counter{Covered: 2, Total: 2}, merged.Totals.Branches)
+		assert.Equal(t, &Counter{Covered: 1, Total: 2}, shardA.Files["a.c"].Lines[0].Branches)
+	})
+
+	t.Run("RejectsMixedFormats", func(t *testing.T) {
+		_, err := Merge(&Report{Format: FormatPython}, &Report{Format: FormatGo})
+
+		assert.ErrorIs(t, err, ErrMixedFormats)
+	})
+}
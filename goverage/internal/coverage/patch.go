@@ -0,0 +1,130 @@
+package coverage
+
+import (
+	"sort"
+	"strings"
+)
+
+// PatchFile is the coverage of the changed lines of a file.
+type PatchFile struct {
+	Path string `json:"path"`
+	// Lines counts the changed lines that are executable.
+	Lines          Counter `json:"lines"`
+	UncoveredLines []int   `json:"uncovered_lines"`
+}
+
+// Patch is the coverage of the lines changed by a diff.
+type Patch struct {
+	Lines Counter     `json:"lines"`
+	Files []PatchFile `json:"files"`
+}
+
+// PatchCoverage computes the coverage of the changed lines, keyed by the paths
+// of a diff, from the line ranges of a report. Changed lines outside of every
+// range aren't executable and are left out, as are files the report doesn't
+// cover.
+func PatchCoverage(changed map[string][]int, ranges map[string][]LineRange) Patch {
+	reportPaths := make([]string, 0, len(ranges))
+	for path := range ranges {
+		reportPaths = append(reportPaths, path)
+	}
+
+	diffPaths := make([]string, 0, len(changed))
+	for path := range changed {
+		diffPaths = append(diffPaths, path)
+	}
+	sort.Strings(diffPaths)
+
+	patch := Patch{Files: []PatchFile{}}
+	for _, diffPath := range diffPaths {
+		reportPath, ok := MatchPath(diffPath, reportPaths)
+		if !ok {
+			continue
+		}
+
+		file := PatchFile{Path: diffPath, UncoveredLines: []int{}}
+		for _, number := range changed[diffPath] {
+			lineRange, ok := findLineRange(ranges[reportPath], number)
+			if !ok {
+				continue
+			}
+
+			file.Lines.Total++
+			if lineRange.Hits > 0 {
+				file.Lines.Covered++
+			} else {
+				file.UncoveredLines = append(file.UncoveredLines, number)
+			}
+		}
+
+		if file.Lines.Total == 0 {
+			continue
+		}
+
+		patch.Lines.Add(file.Lines)
+		patch.Files = append(patch.Files, file)
+	}
+
+	return patch
+}
+
+// ApproximateChangedLines approximates the lines changed between two commits
+// from the line ranges of their reports, for when no diff is at hand: the
+// executable lines of head that weren't executable in base. Lines are compared
+// by number, as in Compare, so lines shifted by the change count as changed,
+// and lines modified but executable in both commits are missed. It isn't patch
+// coverage, which needs a diff.
+func ApproximateChangedLines(base, head map[string][]LineRange) map[string][]int {
+	changed := map[string][]int{}
+	for path, ranges := range head {
+		baseHits := lineHits(base[path])
+
+		lines := []int{}
+		for number := range lineHits(ranges) {
+			if _, ok := baseHits[number]; !ok {
+				lines = append(lines, number)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		sort.Ints(lines)
+		changed[path] = lines
+	}
+
+	return changed
+}
+
+// MatchPath returns the report path of a repository relative path. Reports
+// may hold paths relative to another root, such as Go import paths, so a
+// path also matches the report path it is a suffix of, or the other way
+// around, as long as a single report path does.
+func MatchPath(path string, reportPaths []string) (string, bool) {
+	var matches []string
+	for _, reportPath := range reportPaths {
+		if reportPath == path {
+			return reportPath, true
+		}
+
+		if strings.HasSuffix(reportPath, "/"+path) || strings.HasSuffix(path, "/"+reportPath) {
+			matches = append(matches, reportPath)
+		}
+	}
+
+	if len(matches) != 1 {
+		return "", false
+	}
+
+	return matches[0], true
+}
+
+// findLineRange returns the range holding line in ranges sorted by start line.
+func findLineRange(ranges []LineRange, line int) (LineRange, bool) {
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].EndLine >= line })
+	if i == len(ranges) || ranges[i].StartLine > line {
+		return LineRange{}, false
+	}
+
+	return ranges[i], true
+}
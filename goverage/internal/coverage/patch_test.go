@@ -0,0 +1,84 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatchCoverage(t *testing.T) {
+	ranges := map[string][]LineRange{
+		"example.com/app/main.go": {
+			{StartLine: 3, EndLine: 5, Hits: 1},
+			{StartLine: 7, EndLine: 9, Hits: 0},
+		},
+		"app/util.py": {{StartLine: 1, EndLine: 2, Hits: 0}},
+	}
+
+	t.Run("CountsExecutableChangedLines", func(t *testing.T) {
+		patch := PatchCoverage(map[string][]int{
+			"main.go":     {1, 4, 5, 6, 8},
+			"app/util.py": {10},
+			"README.md":   {1},
+		}, ranges)
+
+		assert.Equal(t, Patch{
+			Lines: Counter{Covered: 2, Total: 3},
+			Files: []PatchFile{
+				{Path: "main.go", Lines: Counter{Covered: 2, Total: 3}, UncoveredLines: []int{8}},
+			},
+		}, patch)
+	})
+
+	t.Run("ReturnsNoFilesWithoutChangedCode", func(t *testing.T) {
+		patch := PatchCoverage(map[string][]int{"README.md": {1}}, ranges)
+
+		assert.Equal(t, Patch{Files: []PatchFile{}}, patch)
+	})
+}
+
+func TestApproximateChangedLines(t *testing.T) {
+	changed := ApproximateChangedLines(map[string][]LineRange{
+		"app/main.go": {{StartLine: 3, EndLine: 5, Hits: 1}},
+		"app/old.go":  {{StartLine: 1, EndLine: 2, Hits: 1}},
+	}, map[string][]LineRange{
+		"app/main.go": {{StartLine: 3, EndLine: 4, Hits: 0}, {StartLine: 6, EndLine: 7, Hits: 1}},
+		"app/new.go":  {{StartLine: 2, EndLine: 3, Hits: 0}},
+	})
+
+	assert.Equal(t, map[string][]int{
+		"app/main.go": {6, 7},
+		"app/new.go":  {2, 3},
+	}, changed)
+}
+
+func TestMatchPath(t *testing.T) {
+	reportPaths := []string{"example.com/app/main.go", "example.com/app/cmd/main.go", "app/util.py"}
+
+	t.Run("MatchesExactPath", func(t *testing.T) {
+		path, ok := MatchPath("app/util.py", reportPaths)
+
+		assert.True(t, ok)
+		assert.Equal(t, "app/util.py", path)
+	})
+
+	t.Run("MatchesPathSuffix", func(t *testing.T) {
+		path, ok := MatchPath("cmd/main.go", reportPaths)
+
+		assert.True(t, ok)
+		assert.Equal(t, "example.com/app/cmd/main.go", path)
+	})
+
+	t.Run("MatchesReportPathSuffix", func(t *testing.T) {
+		path, ok := MatchPath("services/api/app/util.py", reportPaths)
+
+		assert.True(t, ok)
+		assert.Equal(t, "app/util.py", path)
+	})
+
+	t.Run("RejectsAmbiguousPath", func(t *testing.T) {
+		_, ok := MatchPath("main.go", reportPaths)
+
+		assert.False(t, ok)
+	})
+}
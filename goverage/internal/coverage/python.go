@@ -0,0 +1,212 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const pythonTimestampLayout = "2006-01-02T15:04:05"
+
+// PythonCoverageJSONFile is the report written by `coverage json`.
+type PythonCoverageJSONFile struct {
+	Meta struct {
+		Format    int    `json:"format"`
+		Version   string `json:"version"`
+		Timestamp string `json:"timestamp"`
+	} `json:"meta"`
+	Totals struct {
+		CoveredLines   int     `json:"covered_lines"`
+		NumStatements  int     `json:"num_statements"`
+		PercentCovered float64 `json:"percent_covered"`
+		MissingLines   int     `json:"missing_lines"`
+		// The branch counts are only written with `coverage run --branch`.
+		NumBranches        int `json:"num_branches"`
+		NumPartialBranches int `json:"num_partial_branches"`
+		CoveredBranches    int `json:"covered_branches"`
+		MissingBranches    int `json:"missing_branches"`
+	} `json:"totals"`
+	Files map[string]PythonCoverageJSONFileEntry `json:"files"`
+}
+
+// PythonCoverageJSONFileEntry is the coverage of a file of a `coverage json` report.
+type PythonCoverageJSONFileEntry struct {
+	ExecutedLines []int `json:"executed_lines"`
+	MissingLines  []int `json:"missing_lines"`
+	// Branches are arcs between a source and a destination line, the
+	// destination is negative when the arc exits the code object.
+	ExecutedBranches [][2]int `json:"executed_branches"`
+	MissingBranches  [][2]int `json:"missing_branches"`
+	Summary          struct {
+		CoveredLines    int `json:"covered_lines"`
+		NumStatements   int `json:"num_statements"`
+		NumBranches     int `json:"num_branches"`
+		CoveredBranches int `json:"covered_branches"`
+	} `json:"summary"`
+}
+
+type pythonParser struct{}
+
+func (pythonParser) Format() Format {
+	return FormatPython
+}
+
+func (pythonParser) Detect(content []byte) bool {
+	var probe struct {
+		Meta   *json.RawMessage `json:"meta"`
+		Totals *json.RawMessage `json:"totals"`
+	}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return false
+	}
+
+	return probe.Meta != nil && probe.Totals != nil
+}
+
+func (pythonParser) Parse(content []byte) (*Report, error) {
+	var pythonCoverage PythonCoverageJSONFile
+	if err := json.Unmarshal(content, &pythonCoverage); err != nil {
+		return nil, fmt.Errorf("failed to decode python coverage: %w", err)
+	}
+
+	timestamp, err := time.Parse(pythonTimestampLayout, pythonCoverage.Meta.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse python coverage timestamp: %w", err)
+	}
+
+	report := &Report{
+		Timestamp: timestamp,
+		Coverage:  pythonCoverage.Totals.PercentCovered,
+		Totals: Totals{
+			Lines:    Counter{Covered: pythonCoverage.Totals.CoveredLines, Total: pythonCoverage.Totals.NumStatements},
+			Branches: Counter{Covered: pythonCoverage.Totals.CoveredBranches, Total: pythonCoverage.Totals.NumBranches},
+		},
+		Files: make(map[string]*File, len(pythonCoverage.Files)),
+		// coverage_data has always returned the coverage.py report as uploaded.
+		Raw: content,
+	}
+
+	for path, pythonFile := range pythonCoverage.Files {
+		// Arcs are identified within their source line by their destination.
+		lineBranches := map[int]map[string]bool{}
+		addArcs := func(arcs [][2]int, executed bool) {
+			for _, arc := range arcs {
+				branches, ok := lineBranches[arc[0]]
+				if !ok {
+					branches = map[string]bool{}
+					lineBranches[arc[0]] = branches
+				}
+
+				id := strconv.Itoa(arc[1])
+				branches[id] = branches[id] || executed
+			}
+		}
+		addArcs(pythonFile.ExecutedBranches, true)
+		addArcs(pythonFile.MissingBranches, false)
+
+		newLine := func(number int, hits int64) Line {
+			line := Line{Number: number, Hits: hits}
+			if branches, ok := lineBranches[number]; ok {
+				line.Branches = branchCounter(branches)
+				line.BranchesTaken = branches
+			}
+
+			return line
+		}
+
+		lines := make([]Line, 0, len(pythonFile.ExecutedLines)+len(pythonFile.MissingLines))
+		for _, number := range pythonFile.ExecutedLines {
+			lines = append(lines, newLine(number, 1))
+		}
+		for _, number := range pythonFile.MissingLines {
+			lines = append(lines, newLine(number, 0))
+		}
+		sort.Slice(lines, func(i, j int) bool { return lines[i].Number < lines[j].Number })
+
+		report.Files[path] = &File{
+			Totals: Totals{
+				Lines: Counter{Covered: pythonFile.Summary.CoveredLines, Total: pythonFile.Summary.NumStatements},
+				Branches: Counter{
+					Covered: pythonFile.Summary.CoveredBranches,
+					Total:   pythonFile.Summary.NumBranches,
+				},
+			},
+			Lines: lines,
+		}
+	}
+
+	return report, nil
+}
+
+// pythonRawData writes report, merged from coverage.py reports, as a
+// `coverage json` report, so its raw data is still in the uploaded format.
+func pythonRawData(report *Report) ([]byte, error) {
+	var pythonCoverage PythonCoverageJSONFile
+	// Version 2 of the format is the first one holding arcs.
+	pythonCoverage.Meta.Format = 2
+	pythonCoverage.Meta.Timestamp = report.Timestamp.Format(pythonTimestampLayout + ".000000")
+	pythonCoverage.Totals.CoveredLines = report.Totals.Lines.Covered
+	pythonCoverage.Totals.NumStatements = report.Totals.Lines.Total
+	pythonCoverage.Totals.MissingLines = report.Totals.Lines.Total - report.Totals.Lines.Covered
+	pythonCoverage.Totals.PercentCovered = report.Coverage
+	pythonCoverage.Totals.NumBranches = report.Totals.Branches.Total
+	pythonCoverage.Totals.CoveredBranches = report.Totals.Branches.Covered
+	pythonCoverage.Totals.MissingBranches = report.Totals.Branches.Total - report.Totals.Branches.Covered
+	pythonCoverage.Files = make(map[string]PythonCoverageJSONFileEntry, len(report.Files))
+
+	for path, file := range report.Files {
+		entry := PythonCoverageJSONFileEntry{
+			ExecutedLines:    []int{},
+			MissingLines:     []int{},
+			ExecutedBranches: [][2]int{},
+			MissingBranches:  [][2]int{},
+		}
+		entry.Summary.CoveredLines = file.Totals.Lines.Covered
+		entry.Summary.NumStatements = file.Totals.Lines.Total
+		entry.Summary.NumBranches = file.Totals.Branches.Total
+		entry.Summary.CoveredBranches = file.Totals.Branches.Covered
+
+		for _, line := range file.Lines {
+			if line.Hits > 0 {
+				entry.ExecutedLines = append(entry.ExecutedLines, line.Number)
+			} else {
+				entry.MissingLines = append(entry.MissingLines, line.Number)
+			}
+
+			if line.Branches != nil && line.Branches.Covered > 0 && line.Branches.Covered < line.Branches.Total {
+				pythonCoverage.Totals.NumPartialBranches++
+			}
+
+			for id, taken := range line.BranchesTaken {
+				destination, err := strconv.Atoi(id)
+				if err != nil {
+					return nil, fmt.Errorf("invalid python branch %q of line %d: %w", id, line.Number, err)
+				}
+
+				if taken {
+					entry.ExecutedBranches = append(entry.ExecutedBranches, [2]int{line.Number, destination})
+				} else {
+					entry.MissingBranches = append(entry.MissingBranches, [2]int{line.Number, destination})
+				}
+			}
+		}
+		sortArcs(entry.ExecutedBranches)
+		sortArcs(entry.MissingBranches)
+
+		pythonCoverage.Files[path] = entry
+	}
+
+	return json.Marshal(pythonCoverage)
+}
+
+func sortArcs(arcs [][2]int) {
+	sort.Slice(arcs, func(i, j int) bool {
+		if arcs[i][0] != arcs[j][0] {
+			return arcs[i][0] < arcs[j][0]
+		}
+
+		return arcs[i][1] < arcs[j][1]
+	})
+}
@@ -0,0 +1,56 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pythonBranchReport = `{
+	"meta": {"format": 2, "version": "7.4.4", "timestamp": "2024-03-27T20:01:53.123456", "branch_coverage": true},
+	"files": {
+		"app/main.py": {
+			"executed_lines": [1, 2, 3, 5],
+			"missing_lines": [4],
+			"executed_branches": [[2, 3], [2, 5], [3, 5]],
+			"missing_branches": [[3, 4]],
+			"summary": {
+				"covered_lines": 4, "num_statements": 5, "percent_covered": 77.77777777777777,
+				"num_branches": 4, "covered_branches": 3, "missing_branches": 1, "num_partial_branches": 1
+			}
+		}
+	},
+	"totals": {
+		"covered_lines": 4, "num_statements": 5, "percent_covered": 77.77777777777777, "missing_lines": 1,
+		"num_branches": 4, "covered_branches": 3, "missing_branches": 1, "num_partial_branches": 1
+	}
+}`
+
+func TestPythonParser(t *testing.T) {
+	t.Run("ParsesBranchCoverage", func(t *testing.T) {
+		report, err := pythonParser{}.Parse([]byte(pythonBranchReport))
+
+		require.NoError(t, err)
+		assert.Equal(t, Counter{Covered: 3, Total: 4}, report.Totals.Branches)
+
+		file := report.Files["app/main.py"]
+		require.NotNil(t, file)
+		assert.Equal(t, Counter{Covered: 3, Total: 4}, file.Totals.Branches)
+		assert.Equal(t, []Line{
+			{Number: 1, Hits: 1},
+			{Number: 2, Hits: 1, Branches: &Counter{Covered: 2, Total: 2}, BranchesTaken: map[string]bool{"3": true, "5": true}},
+			{Number: 3, Hits: 1, Branches: &Counter{Covered: 1, Total: 2}, BranchesTaken: map[string]bool{"4": false, "5": true}},
+			{Number: 4, Hits: 0},
+			{Number: 5, Hits: 1},
+		}, file.Lines)
+	})
+
+	t.Run("LeavesBranchesEmptyWithoutBranchData", func(t *testing.T) {
+		report, err := pythonParser{}.Parse([]byte(pythonReport))
+
+		require.NoError(t, err)
+		assert.Equal(t, Counter{}, report.Totals.Branches)
+		assert.Nil(t, report.Files["app/main.py"].Lines[0].Branches)
+	})
+}
@@ -0,0 +1,115 @@
+package coverage
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Format string
+
+const (
+	FormatPython    Format = "python"
+	FormatGo        Format = "go"
+	FormatLCOV      Format = "lcov"
+	FormatCobertura Format = "cobertura"
+	FormatJaCoCo    Format = "jacoco"
+	FormatIstanbul  Format = "istanbul"
+
+	// FormatGoCoverDir archives are uploaded to their own endpoint, it isn't
+	// registered as a parser since it can't be told apart from any archive.
+	FormatGoCoverDir Format = "gocoverdir"
+)
+
+var (
+	ErrUnknownFormat    = errors.New("unknown coverage format")
+	ErrUndetectedFormat = errors.New("could not detect coverage format")
+)
+
+// Parser turns an upload of a single format into a normalized Report.
+type Parser interface {
+	Format() Format
+	// Detect sniffs content and reports whether it looks like this parser's format.
+	Detect(content []byte) bool
+	Parse(content []byte) (*Report, error)
+}
+
+// parsers are tried in order during detection, stricter formats must come first.
+var parsers = []Parser{
+	pythonParser{},
+	goCoverParser{},
+	lcovParser{},
+	coberturaParser{},
+	jacocoParser{},
+	istanbulParser{},
+}
+
+// Register adds a parser to the registry, replacing any parser of the same format.
+func Register(parser Parser) {
+	for i, existing := range parsers {
+		if existing.Format() == parser.Format() {
+			parsers[i] = parser
+			return
+		}
+	}
+
+	parsers = append(parsers, parser)
+}
+
+// Formats lists the registered formats in detection order.
+func Formats() []Format {
+	formats := make([]Format, 0, len(parsers))
+	for _, parser := range parsers {
+		formats = append(formats, parser.Format())
+	}
+
+	return formats
+}
+
+// Lookup returns the parser registered for format.
+func Lookup(format Format) (Parser, bool) {
+	for _, parser := range parsers {
+		if parser.Format() == format {
+			return parser, true
+		}
+	}
+
+	return nil, false
+}
+
+// Detect returns the first registered parser recognizing content.
+func Detect(content []byte) (Parser, error) {
+	for _, parser := range parsers {
+		if parser.Detect(content) {
+			return parser, nil
+		}
+	}
+
+	return nil, ErrUndetectedFormat
+}
+
+// Parse parses content with the parser of format, or the detected one when format is empty.
+func Parse(content []byte, format Format) (*Report, error) {
+	var parser Parser
+
+	if format == "" {
+		detected, err := Detect(content)
+		if err != nil {
+			return nil, err
+		}
+		parser = detected
+	} else {
+		found, ok := Lookup(format)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+		}
+		parser = found
+	}
+
+	report, err := parser.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	report.Format = parser.Format()
+
+	return report, nil
+}
@@ -0,0 +1,67 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pythonReport = `{
+	"meta": {"format": 2, "version": "7.4.4", "timestamp": "2024-03-27T20:01:53.123456"},
+	"files": {
+		"app/main.py": {
+			"executed_lines": [1, 2, 4],
+			"missing_lines": [5],
+			"summary": {"covered_lines": 3, "num_statements": 4, "percent_covered": 75.0}
+		}
+	},
+	"totals": {"covered_lines": 3, "num_statements": 4, "percent_covered": 75.0, "missing_lines": 1}
+}`
+
+func TestParse(t *testing.T) {
+	t.Run("DetectsPythonReport", func(t *testing.T) {
+		report, err := Parse([]byte(pythonReport), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, FormatPython, report.Format)
+		assert.InDelta(t, 75.0, report.Coverage, 0.001)
+		assert.Equal(t, Counter{Covered: 3, Total: 4}, report.Totals.Lines)
+		assert.Equal(t, []Line{{Number: 1, Hits: 1}, {Number: 2, Hits: 1}, {Number: 4, Hits: 1}, {Number: 5, Hits: 0}}, report.Files["app/main.py"].Lines)
+		assert.Equal(t, 2024, report.Timestamp.Year())
+
+		rawData, err := report.RawData()
+		require.NoError(t, err)
+		assert.Equal(t, pythonReport, string(rawData))
+	})
+
+	t.Run("DetectsGoCoverProfile", func(t *testing.T) {
+		report, err := Parse([]byte("mode: set\na.go:1.1,2.2 1 1\n"), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, FormatGo, report.Format)
+		assert.True(t, report.Timestamp.IsZero())
+
+		rawData, err := report.RawData()
+		require.NoError(t, err)
+		assert.Contains(t, string(rawData), `"format":"go"`)
+	})
+
+	t.Run("UsesForcedFormat", func(t *testing.T) {
+		_, err := Parse([]byte("mode: set\na.go:1.1,2.2 1 1\n"), FormatPython)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("RejectsUnknownFormat", func(t *testing.T) {
+		_, err := Parse([]byte(pythonReport), "clover")
+
+		assert.ErrorIs(t, err, ErrUnknownFormat)
+	})
+
+	t.Run("RejectsUndetectedFormat", func(t *testing.T) {
+		_, err := Parse([]byte("hello"), "")
+
+		assert.ErrorIs(t, err, ErrUndetectedFormat)
+	})
+}
@@ -0,0 +1,230 @@
+// Package coverage holds the normalized coverage report shared by every
+// upload format the service understands.
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Kinds of report stored for a commit, integration reports come from binaries
+// built with coverage and exercised by end-to-end tests.
+const (
+	KindUnit        = "unit"
+	KindIntegration = "integration"
+)
+
+// Counter tracks how many items of a kind (statements, lines...) are covered.
+type Counter struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Percent returns the covered ratio in percent, or 0 when nothing is tracked.
+func (c Counter) Percent() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+
+	return float64(c.Covered) / float64(c.Total) * 100
+}
+
+// Add accumulates another counter into c.
+func (c *Counter) Add(other Counter) {
+	c.Covered += other.Covered
+	c.Total += other.Total
+}
+
+type Totals struct {
+	Statements   Counter `json:"statements"`
+	Lines        Counter `json:"lines"`
+	Branches     Counter `json:"branches"`
+	Functions    Counter `json:"functions"`
+	Instructions Counter `json:"instructions"`
+	Classes      Counter `json:"classes"`
+}
+
+// Add accumulates every counter of other into t.
+func (t *Totals) Add(other Totals) {
+	t.Statements.Add(other.Statements)
+	t.Lines.Add(other.Lines)
+	t.Branches.Add(other.Branches)
+	t.Functions.Add(other.Functions)
+	t.Instructions.Add(other.Instructions)
+	t.Classes.Add(other.Classes)
+}
+
+// Line is the hit count of a single executable source line.
+type Line struct {
+	Number int   `json:"line"`
+	Hits   int64 `json:"hits"`
+	// Branches is set when the line holds conditional branches.
+	Branches *Counter `json:"branches,omitempty"`
+	// BranchesTaken tells whether each branch of the line was taken, keyed by
+	// an id unique within the line, for formats identifying branches.
+	BranchesTaken map[string]bool `json:"branches_taken,omitempty"`
+}
+
+type Function struct {
+	Name string `json:"name"`
+	Line int    `json:"line"`
+	Hits int64  `json:"hits"`
+}
+
+// Block is a source range counted as a whole, as reported by Go's cover tool.
+type Block struct {
+	StartLine  int   `json:"start_line"`
+	StartCol   int   `json:"start_col"`
+	EndLine    int   `json:"end_line"`
+	EndCol     int   `json:"end_col"`
+	Statements int   `json:"statements"`
+	Hits       int64 `json:"hits"`
+}
+
+type File struct {
+	Totals    Totals     `json:"totals"`
+	Lines     []Line     `json:"lines"`
+	Blocks    []Block    `json:"blocks,omitempty"`
+	Functions []Function `json:"functions,omitempty"`
+}
+
+// Coverage returns the headline coverage of the file: statement coverage for
+// formats counting statements, line coverage otherwise.
+func (f *File) Coverage() float64 {
+	if f.Totals.Statements.Total > 0 {
+		return f.Totals.Statements.Percent()
+	}
+
+	return f.Totals.Lines.Percent()
+}
+
+// LineRange is a run of consecutive lines with the same hits and branches.
+type LineRange struct {
+	StartLine int   `json:"start_line"`
+	EndLine   int   `json:"end_line"`
+	Hits      int64 `json:"hits"`
+	// Branches is zero for lines without conditional branches.
+	Branches Counter `json:"branches"`
+}
+
+// LineRanges collapses lines, sorted by number, into ranges. Lines missing
+// from the report, such as comments, end a range.
+func LineRanges(lines []Line) []LineRange {
+	var ranges []LineRange
+	for _, line := range lines {
+		var branches Counter
+		if line.Branches != nil {
+			branches = *line.Branches
+		}
+
+		if last := len(ranges) - 1; last >= 0 &&
+			ranges[last].EndLine == line.Number-1 &&
+			ranges[last].Hits == line.Hits &&
+			ranges[last].Branches == branches {
+			ranges[last].EndLine = line.Number
+			continue
+		}
+
+		ranges = append(ranges, LineRange{
+			StartLine: line.Number,
+			EndLine:   line.Number,
+			Hits:      line.Hits,
+			Branches:  branches,
+		})
+	}
+
+	return ranges
+}
+
+// Report is a parsed coverage upload, keyed by source file path.
+type Report struct {
+	Format Format `json:"format"`
+	// Timestamp is when the report was generated, zero when the format doesn't record it.
+	Timestamp time.Time        `json:"timestamp"`
+	Coverage  float64          `json:"coverage"`
+	Totals    Totals           `json:"totals"`
+	Files     map[string]*File `json:"files"`
+	// Packages holds package level totals, for formats reporting them.
+	Packages map[string]Totals `json:"packages,omitempty"`
+
+	// Raw is the original upload, kept for formats whose upload is stored as is.
+	Raw json.RawMessage `json:"-"`
+}
+
+// RawData returns the JSON stored as the report's raw data: the original
+// upload when it is kept as is, the normalized report otherwise.
+func (r *Report) RawData() ([]byte, error) {
+	if r.Raw != nil {
+		return r.Raw, nil
+	}
+
+	return json.Marshal(r)
+}
+
+// ParseRawData parses the raw data stored for a report of format, as returned by RawData.
+// coverage.py reports merged from shards used to be stored normalized, they
+// are told apart by their missing meta.
+func ParseRawData(raw []byte, format Format) (*Report, error) {
+	if format == FormatPython && (pythonParser{}).Detect(raw) {
+		return Parse(raw, FormatPython)
+	}
+
+	report := &Report{}
+	if err := json.Unmarshal(raw, report); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+
+	return report, nil
+}
+
+// RewritePaths returns a copy of r with its files renamed by rewrite, which
+// drops the files it returns false for. Files renamed to the same path are
+// merged the way Merge merges the shards of a file. Totals are recomputed when
+// a file is dropped or merged, and package totals, which can't be split by
+// file, are left out.
+func (r *Report) RewritePaths(rewrite func(path string) (string, bool)) *Report {
+	rewritten := *r
+	rewritten.Files = make(map[string]*File, len(r.Files))
+
+	paths := make([]string, 0, len(r.Files))
+	for path := range r.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var (
+		renamed = make(map[string][]*File, len(r.Files))
+		changed bool
+	)
+	for _, path := range paths {
+		newPath, ok := rewrite(path)
+		if !ok {
+			changed = true
+			continue
+		}
+
+		renamed[newPath] = append(renamed[newPath], r.Files[path])
+	}
+
+	var totals Totals
+	for path, files := range renamed {
+		file := files[0]
+		if len(files) > 1 {
+			file = mergeFiles(files)
+			changed = true
+		}
+
+		rewritten.Files[path] = file
+		totals.Add(file.Totals)
+	}
+
+	if changed {
+		rewritten.Totals = totals
+		rewritten.Coverage = headlineCoverage(r.Format, totals)
+		rewritten.Packages = nil
+	}
+
+	return &rewritten
+}
@@ -0,0 +1,158 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineRanges(t *testing.T) {
+	t.Run("CollapsesConsecutiveLinesWithSameHits", func(t *testing.T) {
+		ranges := LineRanges([]Line{
+			{Number: 1, Hits: 2},
+			{Number: 2, Hits: 2},
+			{Number: 3, Hits: 0},
+			{Number: 4, Hits: 0},
+			{Number: 6, Hits: 0},
+			{Number: 7, Hits: 1},
+		})
+
+		assert.Equal(t, []LineRange{
+			{StartLine: 1, EndLine: 2, Hits: 2},
+			{StartLine: 3, EndLine: 4, Hits: 0},
+			{StartLine: 6, EndLine: 6, Hits: 0},
+			{StartLine: 7, EndLine: 7, Hits: 1},
+		}, ranges)
+	})
+
+	t.Run("SplitsLinesWithDifferentBranches", func(t *testing.T) {
+		ranges := LineRanges([]Line{
+			{Number: 1, Hits: 1},
+			{Number: 2, Hits: 1, Branches: &Counter{Covered: 1, Total: 2}},
+			{Number: 3, Hits: 1, Branches: &Counter{Covered: 1, Total: 2}},
+			{Number: 4, Hits: 1},
+		})
+
+		assert.Equal(t, []LineRange{
+			{StartLine: 1, EndLine: 1, Hits: 1},
+			{StartLine: 2, EndLine: 3, Hits: 1, Branches: Counter{Covered: 1, Total: 2}},
+			{StartLine: 4, EndLine: 4, Hits: 1},
+		}, ranges)
+	})
+
+	t.Run("ReturnsNothingWithoutLines", func(t *testing.T) {
+		assert.Empty(t, LineRanges(nil))
+	})
+}
+
+func TestFileCoverage(t *testing.T) {
+	t.Run("PrefersStatements", func(t *testing.T) {
+		file := &File{Totals: Totals{Statements: Counter{Covered: 1, Total: 4}, Lines: Counter{Covered: 1, Total: 2}}}
+
+		assert.InDelta(t, 25.0, file.Coverage(), 0.001)
+	})
+
+	t.Run("FallsBackToLines", func(t *testing.T) {
+		file := &File{Totals: Totals{Lines: Counter{Covered: 1, Total: 2}}}
+
+		assert.InDelta(t, 50.0, file.Coverage(), 0.001)
+	})
+}
+
+func TestRewritePaths(t *testing.T) {
+	report := &Report{
+		Format:   FormatGo,
+		Coverage: 50,
+		Totals:   Totals{Statements: Counter{Covered: 4, Total: 8}},
+		Files: map[string]*File{
+			"main.go":       {Totals: Totals{Statements: Counter{Covered: 3, Total: 4}}},
+			"db/queries.go": {Totals: Totals{Statements: Counter{Covered: 1, Total: 4}}},
+		},
+		Packages: map[string]Totals{"app": {Statements: Counter{Covered: 4, Total: 8}}},
+	}
+
+	t.Run("RecomputesTotalsOfKeptFiles", func(t *testing.T) {
+		rewritten := report.RewritePaths(func(path string) (string, bool) {
+			return "app/" + path, path != "db/queries.go"
+		})
+
+		assert.Equal(t, []string{"app/main.go"}, lo.Keys(rewritten.Files))
+		assert.Equal(t, Totals{Statements: Counter{Covered: 3, Total: 4}}, rewritten.Totals)
+		assert.Equal(t, 75.0, rewritten.Coverage)
+		assert.Nil(t, rewritten.Packages)
+		assert.Len(t, report.Files, 2)
+	})
+
+	t.Run("MergesFilesRenamedToTheSamePath", func(t *testing.T) {
+		report := &Report{
+			Format: FormatGo,
+			Totals: Totals{Statements: Counter{Covered: 2, Total: 3}},
+			Files: map[string]*File{
+				"a/main.go": {
+					Totals: Totals{Statements: Counter{Covered: 1, Total: 2}},
+					Blocks: []Block{
+						{StartLine: 3, EndLine: 4, Statements: 1, Hits: 1},
+						{StartLine: 6, EndLine: 6, Statements: 1, Hits: 0},
+					},
+				},
+				"b/main.go": {
+					Totals: Totals{Statements: Counter{Covered: 1, Total: 1}},
+					Blocks: []Block{{StartLine: 6, EndLine: 6, Statements: 1, Hits: 2}},
+				},
+			},
+		}
+
+		rewritten := report.RewritePaths(func(path string) (string, bool) { return "main.go", true })
+
+		assert.Equal(t, []string{"main.go"}, lo.Keys(rewritten.Files))
+		assert.Equal(t, Totals{Statements: Counter{Covered: 2, Total: 2}}, rewritten.Totals)
+		assert.Equal(t, rewritten.Totals, rewritten.Files["main.go"].Totals)
+		assert.Equal(t, 100.0, rewritten.Coverage)
+	})
+
+	t.Run("KeepsTotalsWhenNothingIsDropped", func(t *testing.T) {
+		rewritten := report.RewritePaths(func(path string) (string, bool) { return path, true })
+
+		assert.Equal(t, report.Totals, rewritten.Totals)
+		assert.Equal(t, report.Packages, rewritten.Packages)
+	})
+}
+
+func TestParseRawData(t *testing.T) {
+	report := &Report{
+		Format:   FormatGo,
+		Coverage: 75,
+		Totals:   Totals{Statements: Counter{Covered: 3, Total: 4}},
+		Files: map[string]*File{
+			"main.go": {Totals: Totals{Statements: Counter{Covered: 3, Total: 4}}, Lines: []Line{{Number: 3, Hits: 1}}},
+		},
+	}
+	raw, err := report.RawData()
+	require.NoError(t, err)
+
+	parsed, err := ParseRawData(raw, FormatGo)
+
+	require.NoError(t, err)
+	assert.Equal(t, report, parsed)
+}
+
+func TestParseRawDataOfNormalizedPythonReport(t *testing.T) {
+	// Merged coverage.py reports used to be stored normalized.
+	report := &Report{
+		Format:   FormatPython,
+		Coverage: 50,
+		Totals:   Totals{Lines: Counter{Covered: 1, Total: 2}},
+		Files: map[string]*File{
+			"app/main.py": {Totals: Totals{Lines: Counter{Covered: 1, Total: 2}}, Lines: []Line{{Number: 1, Hits: 2}, {Number: 2}}},
+		},
+	}
+	raw, err := report.RawData()
+	require.NoError(t, err)
+
+	parsed, err := ParseRawData(raw, FormatPython)
+
+	require.NoError(t, err)
+	assert.Equal(t, report, parsed)
+}
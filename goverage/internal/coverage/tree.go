@@ -0,0 +1,159 @@
+package coverage
+
+import (
+	"sort"
+	"strings"
+)
+
+// Types of the nodes of a tree.
+const (
+	NodeDirectory = "directory"
+	NodeFile      = "file"
+)
+
+// TreeFile is the stored coverage of a file, rolled up by Tree.
+type TreeFile struct {
+	Path       string
+	Statements Counter
+	Lines      Counter
+}
+
+// TreeNode is a directory, or Go package, or a file with the counters of every
+// file below it.
+type TreeNode struct {
+	Name       string
+	Path       string
+	Type       string
+	Statements Counter
+	Lines      Counter
+	// Children is nil for files and for directories below the requested depth.
+	Children []*TreeNode
+}
+
+// Coverage returns the headline coverage of the node, as File.Coverage does.
+func (n *TreeNode) Coverage() float64 {
+	if n.Statements.Total > 0 {
+		return n.Statements.Percent()
+	}
+
+	return n.Lines.Percent()
+}
+
+// Tree rolls files up into a tree of directories rooted at root, a directory
+// or file path, "" for every file. Nodes deeper than depth levels below root
+// are left out, depth 0 keeps the whole tree. It returns false when no file is
+// found at or below root.
+func Tree(files []TreeFile, root string, depth int) (*TreeNode, bool) {
+	root = strings.Trim(root, "/")
+
+	var rootNode *TreeNode
+	if root == "" {
+		rootNode = &TreeNode{Type: NodeDirectory}
+	}
+
+	for _, file := range files {
+		if !InPath(file.Path, root) {
+			continue
+		}
+
+		relative := strings.TrimPrefix(file.Path, "/")
+		if relative == root {
+			rootNode = &TreeNode{
+				Name:       lastSegment(root),
+				Path:       file.Path,
+				Type:       NodeFile,
+				Statements: file.Statements,
+				Lines:      file.Lines,
+			}
+			break
+		}
+
+		if root != "" {
+			relative = strings.TrimPrefix(relative, root+"/")
+		}
+
+		if rootNode == nil {
+			rootNode = &TreeNode{Name: lastSegment(root), Path: root, Type: NodeDirectory}
+		}
+		rootNode.add(strings.Split(relative, "/"), file)
+	}
+
+	if rootNode == nil {
+		return nil, false
+	}
+
+	rootNode.prune(depth)
+
+	return rootNode, true
+}
+
+// add accumulates file into n and its descendants at segments, the path of
+// file relative to n.
+func (n *TreeNode) add(segments []string, file TreeFile) {
+	n.Statements.Add(file.Statements)
+	n.Lines.Add(file.Lines)
+
+	if len(segments) == 0 {
+		return
+	}
+
+	var child *TreeNode
+	for _, existing := range n.Children {
+		if existing.Name == segments[0] {
+			child = existing
+			break
+		}
+	}
+
+	if child == nil {
+		child = &TreeNode{Name: segments[0], Path: joinPath(n.Path, segments[0]), Type: NodeDirectory}
+		if len(segments) == 1 {
+			child.Path = file.Path
+			child.Type = NodeFile
+		}
+		n.Children = append(n.Children, child)
+	}
+
+	child.add(segments[1:], file)
+}
+
+// prune sorts the children of n by name and drops the ones deeper than depth.
+func (n *TreeNode) prune(depth int) {
+	if n.Type == NodeDirectory && n.Children == nil {
+		n.Children = []*TreeNode{}
+	}
+
+	if depth == 1 {
+		for _, child := range n.Children {
+			child.Children = nil
+		}
+	}
+
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+	for _, child := range n.Children {
+		if depth != 1 && child.Type == NodeDirectory {
+			child.prune(max(depth-1, 0))
+		}
+	}
+}
+
+// InPath reports whether path is root or a file below the directory root, a
+// leading slash being ignored. Every path is in the "" root.
+func InPath(path, root string) bool {
+	root = strings.Trim(root, "/")
+	path = strings.TrimPrefix(path, "/")
+
+	return root == "" || path == root || strings.HasPrefix(path, root+"/")
+}
+
+func joinPath(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+
+	return dir + "/" + name
+}
+
+func lastSegment(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
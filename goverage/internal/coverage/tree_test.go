@@ -0,0 +1,66 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree(t *testing.T) {
+	files := []TreeFile{
+		{Path: "app/core/db.go", Statements: Counter{Covered: 3, Total: 4}, Lines: Counter{Covered: 5, Total: 6}},
+		{Path: "app/main.go", Statements: Counter{Covered: 0, Total: 4}, Lines: Counter{Covered: 0, Total: 4}},
+		{Path: "app/core/cache.go", Statements: Counter{Covered: 1, Total: 2}, Lines: Counter{Covered: 1, Total: 2}},
+		{Path: "cmd/main.go", Statements: Counter{Covered: 2, Total: 2}, Lines: Counter{Covered: 2, Total: 2}},
+	}
+
+	t.Run("RollsUpEveryLevel", func(t *testing.T) {
+		tree, ok := Tree(files, "", 0)
+
+		assert.True(t, ok)
+		assert.Equal(t, Counter{Covered: 6, Total: 12}, tree.Statements)
+		assert.Equal(t, 50.0, tree.Coverage())
+		assert.Len(t, tree.Children, 2)
+
+		app := tree.Children[0]
+		assert.Equal(t, "app", app.Path)
+		assert.Equal(t, Counter{Covered: 4, Total: 10}, app.Statements)
+		assert.Equal(t, []string{"core", "main.go"}, []string{app.Children[0].Name, app.Children[1].Name})
+
+		core := app.Children[0]
+		assert.Equal(t, NodeDirectory, core.Type)
+		assert.Equal(t, Counter{Covered: 6, Total: 8}, core.Lines)
+		assert.Equal(t, &TreeNode{
+			Name:       "cache.go",
+			Path:       "app/core/cache.go",
+			Type:       NodeFile,
+			Statements: Counter{Covered: 1, Total: 2},
+			Lines:      Counter{Covered: 1, Total: 2},
+		}, core.Children[0])
+	})
+
+	t.Run("DrillsDownToDepth", func(t *testing.T) {
+		tree, ok := Tree(files, "/app/", 1)
+
+		assert.True(t, ok)
+		assert.Equal(t, "app", tree.Name)
+		assert.Equal(t, Counter{Covered: 4, Total: 10}, tree.Statements)
+		assert.Len(t, tree.Children, 2)
+		assert.Nil(t, tree.Children[0].Children)
+		assert.Equal(t, Counter{Covered: 4, Total: 6}, tree.Children[0].Statements)
+	})
+
+	t.Run("ReturnsFile", func(t *testing.T) {
+		tree, ok := Tree(files, "cmd/main.go", 0)
+
+		assert.True(t, ok)
+		assert.Equal(t, NodeFile, tree.Type)
+		assert.Equal(t, 100.0, tree.Coverage())
+	})
+
+	t.Run("MissesUnknownPath", func(t *testing.T) {
+		_, ok := Tree(files, "ap", 0)
+
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,42 @@
+package coverage
+
+// UncoveredLines holds the lines of a file that tests missed, fully or partly.
+type UncoveredLines struct {
+	// Uncovered holds the runs of lines never hit, as [start, end] pairs.
+	Uncovered [][2]int
+	// Partial holds the runs of lines hit with some of their branches missed.
+	Partial []LineRange
+}
+
+// Empty reports whether every line of the file is fully covered.
+func (u UncoveredLines) Empty() bool {
+	return len(u.Uncovered) == 0 && len(u.Partial) == 0
+}
+
+// Uncovered collects the uncovered and partially covered lines of the ranges
+// of a file, sorted by line. Adjacent uncovered ranges are merged whatever
+// their branches, adjacent partial ranges when their branches are the same.
+func Uncovered(ranges []LineRange) UncoveredLines {
+	uncovered := UncoveredLines{Uncovered: [][2]int{}, Partial: []LineRange{}}
+	for _, lineRange := range ranges {
+		switch {
+		case lineRange.Hits == 0:
+			if last := len(uncovered.Uncovered) - 1; last >= 0 && uncovered.Uncovered[last][1] == lineRange.StartLine-1 {
+				uncovered.Uncovered[last][1] = lineRange.EndLine
+				continue
+			}
+			uncovered.Uncovered = append(uncovered.Uncovered, [2]int{lineRange.StartLine, lineRange.EndLine})
+		case lineRange.Branches.Covered < lineRange.Branches.Total:
+			if last := len(uncovered.Partial) - 1; last >= 0 &&
+				uncovered.Partial[last].EndLine == lineRange.StartLine-1 &&
+				uncovered.Partial[last].Branches == lineRange.Branches {
+				uncovered.Partial[last].EndLine = lineRange.EndLine
+				uncovered.Partial[last].Hits = max(uncovered.Partial[last].Hits, lineRange.Hits)
+				continue
+			}
+			uncovered.Partial = append(uncovered.Partial, lineRange)
+		}
+	}
+
+	return uncovered
+}
@@ -0,0 +1,33 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUncovered(t *testing.T) {
+	t.Run("CompressesLines", func(t *testing.T) {
+		uncovered := Uncovered([]LineRange{
+			{StartLine: 1, EndLine: 9, Hits: 1},
+			{StartLine: 10, EndLine: 12, Hits: 0},
+			{StartLine: 13, EndLine: 14, Hits: 0, Branches: Counter{Covered: 0, Total: 2}},
+			{StartLine: 20, EndLine: 20, Hits: 2, Branches: Counter{Covered: 1, Total: 2}},
+			{StartLine: 21, EndLine: 21, Hits: 1, Branches: Counter{Covered: 1, Total: 2}},
+			{StartLine: 22, EndLine: 22, Hits: 0},
+			{StartLine: 23, EndLine: 23, Hits: 1, Branches: Counter{Covered: 2, Total: 2}},
+		})
+
+		assert.Equal(t, UncoveredLines{
+			Uncovered: [][2]int{{10, 14}, {22, 22}},
+			Partial:   []LineRange{{StartLine: 20, EndLine: 21, Hits: 2, Branches: Counter{Covered: 1, Total: 2}}},
+		}, uncovered)
+		assert.False(t, uncovered.Empty())
+	})
+
+	t.Run("ReturnsEmptyForCoveredFile", func(t *testing.T) {
+		uncovered := Uncovered([]LineRange{{StartLine: 1, EndLine: 3, Hits: 1}})
+
+		assert.True(t, uncovered.Empty())
+	})
+}
@@ -0,0 +1,125 @@
+// Package diff reads the changed lines of unified diffs and the renamed files of
+// name status outputs, as produced by `git diff`.
+package diff
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidDiff = errors.New("invalid unified diff")
+
+// hunkHeaderPattern matches `@@ -oldStart[,oldCount] +newStart[,newCount] @@`.
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Parse returns the lines added or modified by a unified diff, numbered as in
+// the new version of each file and keyed by its path. Deleted files are left out.
+func Parse(r io.Reader) (map[string][]int, error) {
+	var (
+		changed     = map[string][]int{}
+		path        string
+		inFile      bool
+		newLine     int
+		oldLeft     int
+		newLeft     int
+		lineNumber  int
+		scanner     = bufio.NewScanner(r)
+		maxLineSize = 1024 * 1024
+	)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber++
+
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				if path != "" {
+					changed[path] = append(changed[path], newLine)
+				}
+				newLine++
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file" belongs to the previous line.
+			default:
+				// Context lines, some tools strip the leading space of empty ones.
+				newLine++
+				oldLeft--
+				newLeft--
+			}
+
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			path = parsePath(strings.TrimPrefix(line, "+++ "))
+			inFile = true
+		case strings.HasPrefix(line, "@@"):
+			if !inFile {
+				return nil, fmt.Errorf("%w: line %d: hunk without file header", ErrInvalidDiff, lineNumber)
+			}
+
+			match := hunkHeaderPattern.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("%w: line %d: invalid hunk header", ErrInvalidDiff, lineNumber)
+			}
+
+			oldLeft = hunkCount(match[1])
+			newLine, _ = strconv.Atoi(match[2])
+			newLeft = hunkCount(match[3])
+		case strings.HasPrefix(line, "diff "):
+			inFile = false
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if oldLeft > 0 || newLeft > 0 {
+		return nil, fmt.Errorf("%w: truncated hunk", ErrInvalidDiff)
+	}
+
+	return changed, nil
+}
+
+// parsePath returns the path of a `+++` header, without its `b/` prefix and
+// trailing timestamp. It returns "" for deleted files.
+func parsePath(header string) string {
+	if strings.HasPrefix(header, `"`) {
+		// Git quotes paths holding special characters.
+		if end := strings.LastIndex(header, `"`); end > 0 {
+			if unquoted, err := strconv.Unquote(header[:end+1]); err == nil {
+				header = unquoted
+			}
+		}
+	} else if tab := strings.IndexByte(header, '\t'); tab >= 0 {
+		header = header[:tab]
+	}
+
+	if header == "/dev/null" {
+		return ""
+	}
+
+	return strings.TrimPrefix(header, "b/")
+}
+
+// hunkCount parses the optional line count of a hunk range, which defaults to 1.
+func hunkCount(count string) int {
+	if count == "" {
+		return 1
+	}
+
+	n, _ := strconv.Atoi(count)
+
+	return n
+}
@@ -0,0 +1,81 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gitDiff = `diff --git a/app/main.py b/app/main.py
+index 83db48f..bf269f4 100644
+--- a/app/main.py
++++ b/app/main.py
+@@ -1,4 +1,5 @@
+ import os
+-import sys
++import sys, json
++import re
+
+ def main():
+@@ -10,2 +11,3 @@ def main():
+     run()
++    -- not a header
+     return 0
+\ No newline at end of file
+diff --git a/old.py b/old.py
+deleted file mode 100644
+index 83db48f..0000000
+--- a/old.py
++++ /dev/null
+@@ -1 +0,0 @@
+-print("bye")
+diff --git a/new file.py b/new file.py
+new file mode 100644
+--- /dev/null
++++ "b/new file.py"
+@@ -0,0 +1,2 @@
++print("hi")
++print("there")
+`
+
+func TestParse(t *testing.T) {
+	t.Run("ReturnsAddedLinesOfEveryFile", func(t *testing.T) {
+		changed, err := Parse(strings.NewReader(gitDiff))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]int{
+			"app/main.py": {2, 3, 12},
+			"new file.py": {1, 2},
+		}, changed)
+	})
+
+	t.Run("AcceptsPlainDiffHeaders", func(t *testing.T) {
+		changed, err := Parse(strings.NewReader(
+			"--- main.go\t2024-03-27 20:01:53\n+++ main.go\t2024-03-27 20:05:00\n@@ -3 +3 @@\n-a\n+b\n",
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]int{"main.go": {3}}, changed)
+	})
+
+	t.Run("RejectsHunkWithoutFile", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("@@ -1 +1 @@\n-a\n+b\n"))
+
+		assert.ErrorIs(t, err, ErrInvalidDiff)
+	})
+
+	t.Run("RejectsTruncatedHunk", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("+++ b/main.go\n@@ -1,3 +1,3 @@\n a\n"))
+
+		assert.ErrorIs(t, err, ErrInvalidDiff)
+	})
+
+	t.Run("ReturnsNothingForEmptyDiff", func(t *testing.T) {
+		changed, err := Parse(strings.NewReader(""))
+
+		require.NoError(t, err)
+		assert.Empty(t, changed)
+	})
+}
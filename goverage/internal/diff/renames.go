@@ -0,0 +1,55 @@
+package diff
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidRenames = errors.New("invalid name status output")
+
+// ParseRenames returns the renamed files of the output of `git diff
+// --name-status -M`, new paths keyed by old path. Other changes are ignored.
+func ParseRenames(r io.Reader) (map[string]string, error) {
+	var (
+		renames    = map[string]string{}
+		lineNumber int
+		scanner    = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber++
+
+		if !strings.HasPrefix(line, "R") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%w: line %d: rename without old and new path", ErrInvalidRenames, lineNumber)
+		}
+
+		renames[unquotePath(fields[1])] = unquotePath(fields[2])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return renames, nil
+}
+
+// unquotePath unquotes the paths git quotes for holding special characters.
+func unquotePath(path string) string {
+	if strings.HasPrefix(path, `"`) {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			return unquoted
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,27 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRenames(t *testing.T) {
+	t.Run("ReturnsRenames", func(t *testing.T) {
+		output := "M\tapp/main.py\nR087\tapp/db.py\tapp/core/db.py\nA\tapp/new.py\nR100\t\"old name.py\"\t\"new\\tname.py\"\n"
+
+		renames, err := ParseRenames(strings.NewReader(output))
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"app/db.py": "app/core/db.py", "old name.py": "new\tname.py"}, renames)
+	})
+
+	t.Run("RejectsRenameWithoutNewPath", func(t *testing.T) {
+		_, err := ParseRenames(strings.NewReader("M\tapp/main.py\nR100\tapp/db.py\n"))
+
+		assert.ErrorIs(t, err, ErrInvalidRenames)
+		assert.ErrorContains(t, err, "line 2")
+	})
+}
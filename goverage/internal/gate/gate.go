@@ -0,0 +1,176 @@
+// Package gate evaluates the quality gate policy of a project against the
+// coverage of a commit.
+package gate
+
+import (
+	"fmt"
+
+	"goverage/internal/coverage"
+	"goverage/internal/glob"
+)
+
+// Rules of a policy, as reported in the reasons of a result.
+const (
+	RuleMinCoverage      = "min_coverage"
+	RuleMaxDrop          = "max_drop"
+	RuleMinPatchCoverage = "min_patch_coverage"
+	RulePathMinCoverage  = "path_min_coverage"
+)
+
+// Statuses of a rule evaluation.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// PathRule requires the files matching a glob pattern to reach a coverage.
+type PathRule struct {
+	Pattern     string  `json:"pattern" yaml:"pattern"`
+	MinCoverage float64 `json:"min_coverage" yaml:"min_coverage"`
+}
+
+// Policy holds the thresholds of a project, nil thresholds aren't checked.
+type Policy struct {
+	MinCoverage *float64 `json:"min_coverage" yaml:"min_coverage"`
+	// MaxDrop is the largest decrease of coverage allowed against the base commit.
+	MaxDrop          *float64   `json:"max_drop" yaml:"max_drop"`
+	MinPatchCoverage *float64   `json:"min_patch_coverage" yaml:"min_patch_coverage"`
+	Paths            []PathRule `json:"paths" yaml:"paths"`
+}
+
+// File is the coverage of a file of the evaluated commit.
+type File struct {
+	Path       string
+	Statements coverage.Counter
+	Lines      coverage.Counter
+}
+
+// Input is the coverage a policy is evaluated against.
+type Input struct {
+	Coverage float64
+	// BaseCoverage is nil when no base commit is given.
+	BaseCoverage *float64
+	// Patch is nil when no diff is given.
+	Patch *coverage.Patch
+	Files []File
+}
+
+// Reason is the outcome of a rule of the policy.
+type Reason struct {
+	Rule string `json:"rule"`
+	// Path is the pattern of path rules.
+	Path     string   `json:"path,omitempty"`
+	Status   string   `json:"status"`
+	Expected float64  `json:"expected"`
+	Actual   *float64 `json:"actual"`
+	Message  string   `json:"message"`
+}
+
+// Result is the outcome of a policy, with a reason per evaluated rule.
+type Result struct {
+	Passed  bool     `json:"passed"`
+	Reasons []Reason `json:"reasons"`
+}
+
+// Evaluate checks every rule of policy against input. The gate passes unless
+// a rule fails, rules lacking the data they need are skipped.
+func Evaluate(policy Policy, input Input) Result {
+	result := Result{Passed: true, Reasons: []Reason{}}
+	add := func(reason Reason) {
+		if reason.Status == StatusFailed {
+			result.Passed = false
+		}
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	if policy.MinCoverage != nil {
+		add(atLeast(RuleMinCoverage, "", *policy.MinCoverage, &input.Coverage, "coverage"))
+	}
+
+	if policy.MaxDrop != nil {
+		if input.BaseCoverage == nil {
+			add(Reason{
+				Rule:     RuleMaxDrop,
+				Status:   StatusSkipped,
+				Expected: *policy.MaxDrop,
+				Message:  "no base commit to compare with",
+			})
+		} else {
+			drop := *input.BaseCoverage - input.Coverage
+			reason := Reason{Rule: RuleMaxDrop, Status: StatusPassed, Expected: *policy.MaxDrop, Actual: &drop}
+			if drop > *policy.MaxDrop {
+				reason.Status = StatusFailed
+			}
+			reason.Message = fmt.Sprintf(
+				"coverage dropped by %.2f%% against the base, at most %.2f%% allowed", drop, *policy.MaxDrop,
+			)
+			add(reason)
+		}
+	}
+
+	if policy.MinPatchCoverage != nil {
+		switch {
+		case input.Patch == nil:
+			add(Reason{
+				Rule:     RuleMinPatchCoverage,
+				Status:   StatusSkipped,
+				Expected: *policy.MinPatchCoverage,
+				Message:  "no diff given",
+			})
+		case input.Patch.Lines.Total == 0:
+			add(Reason{
+				Rule:     RuleMinPatchCoverage,
+				Status:   StatusSkipped,
+				Expected: *policy.MinPatchCoverage,
+				Message:  "the diff changes no executable line",
+			})
+		default:
+			patchCoverage := input.Patch.Lines.Percent()
+			add(atLeast(RuleMinPatchCoverage, "", *policy.MinPatchCoverage, &patchCoverage, "patch coverage"))
+		}
+	}
+
+	for _, rule := range policy.Paths {
+		var statements, lines coverage.Counter
+		for _, file := range input.Files {
+			if glob.Match(rule.Pattern, file.Path) {
+				statements.Add(file.Statements)
+				lines.Add(file.Lines)
+			}
+		}
+
+		// Paths are measured with the counter of the headline coverage, as
+		// min_coverage and max_drop are: statements when the report counts them.
+		counter := lines
+		if statements.Total > 0 {
+			counter = statements
+		}
+
+		if counter.Total == 0 {
+			add(Reason{
+				Rule:     RulePathMinCoverage,
+				Path:     rule.Pattern,
+				Status:   StatusSkipped,
+				Expected: rule.MinCoverage,
+				Message:  "no covered file matches " + rule.Pattern,
+			})
+			continue
+		}
+
+		pathCoverage := counter.Percent()
+		add(atLeast(RulePathMinCoverage, rule.Pattern, rule.MinCoverage, &pathCoverage, "coverage of "+rule.Pattern))
+	}
+
+	return result
+}
+
+func atLeast(rule, path string, expected float64, actual *float64, subject string) Reason {
+	reason := Reason{Rule: rule, Path: path, Status: StatusPassed, Expected: expected, Actual: actual}
+	if *actual < expected {
+		reason.Status = StatusFailed
+	}
+	reason.Message = fmt.Sprintf("%s is %.2f%%, at least %.2f%% required", subject, *actual, expected)
+
+	return reason
+}
@@ -0,0 +1,90 @@
+package gate
+
+import (
+	"testing"
+
+	"goverage/internal/coverage"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	policy := Policy{
+		MinCoverage:      lo.ToPtr(80.0),
+		MaxDrop:          lo.ToPtr(1.0),
+		MinPatchCoverage: lo.ToPtr(90.0),
+		Paths:            []PathRule{{Pattern: "app/core/**", MinCoverage: 95}},
+	}
+
+	t.Run("PassesWhenEveryRulePasses", func(t *testing.T) {
+		result := Evaluate(policy, Input{
+			Coverage:     85,
+			BaseCoverage: lo.ToPtr(85.5),
+			Patch:        &coverage.Patch{Lines: coverage.Counter{Covered: 9, Total: 10}},
+			Files: []File{
+				{Path: "app/core/db.py", Lines: coverage.Counter{Covered: 19, Total: 20}},
+				{Path: "app/main.py", Lines: coverage.Counter{Covered: 1, Total: 20}},
+			},
+		})
+
+		assert.True(t, result.Passed)
+		assert.Equal(t, []string{StatusPassed, StatusPassed, StatusPassed, StatusPassed},
+			lo.Map(result.Reasons, func(reason Reason, _ int) string { return reason.Status }))
+	})
+
+	t.Run("FailsWithReasons", func(t *testing.T) {
+		result := Evaluate(policy, Input{
+			Coverage:     70,
+			BaseCoverage: lo.ToPtr(75.0),
+			Patch:        &coverage.Patch{Lines: coverage.Counter{Covered: 1, Total: 2}},
+			Files:        []File{{Path: "app/core/db.py", Lines: coverage.Counter{Covered: 1, Total: 2}}},
+		})
+
+		assert.False(t, result.Passed)
+		assert.Equal(t, Reason{
+			Rule:     RuleMinCoverage,
+			Status:   StatusFailed,
+			Expected: 80,
+			Actual:   lo.ToPtr(70.0),
+			Message:  "coverage is 70.00%, at least 80.00% required",
+		}, result.Reasons[0])
+		assert.Equal(t, Reason{
+			Rule:     RuleMaxDrop,
+			Status:   StatusFailed,
+			Expected: 1,
+			Actual:   lo.ToPtr(5.0),
+			Message:  "coverage dropped by 5.00% against the base, at most 1.00% allowed",
+		}, result.Reasons[1])
+		assert.Equal(t, StatusFailed, result.Reasons[2].Status)
+		assert.Equal(t, "app/core/**", result.Reasons[3].Path)
+		assert.Equal(t, StatusFailed, result.Reasons[3].Status)
+	})
+
+	t.Run("MeasuresPathsWithStatementsWhenCounted", func(t *testing.T) {
+		result := Evaluate(Policy{Paths: policy.Paths}, Input{
+			Files: []File{{
+				Path:       "app/core/db.go",
+				Statements: coverage.Counter{Covered: 9, Total: 10},
+				Lines:      coverage.Counter{Covered: 20, Total: 20},
+			}},
+		})
+
+		assert.False(t, result.Passed)
+		assert.Equal(t, lo.ToPtr(90.0), result.Reasons[0].Actual)
+	})
+
+	t.Run("SkipsRulesWithoutData", func(t *testing.T) {
+		result := Evaluate(policy, Input{Coverage: 90})
+
+		assert.True(t, result.Passed)
+		assert.Equal(t, []string{StatusPassed, StatusSkipped, StatusSkipped, StatusSkipped},
+			lo.Map(result.Reasons, func(reason Reason, _ int) string { return reason.Status }))
+	})
+
+	t.Run("PassesWithoutRules", func(t *testing.T) {
+		result := Evaluate(Policy{}, Input{Coverage: 10})
+
+		assert.Equal(t, Result{Passed: true, Reasons: []Reason{}}, result)
+	})
+}
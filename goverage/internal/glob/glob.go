@@ -0,0 +1,79 @@
+// Package glob matches slash separated paths against patterns where `**`
+// stands for any number of directories, as in `src/**/*.py`.
+package glob
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+var ErrInvalidPattern = errors.New("invalid glob pattern")
+
+// Validate reports whether pattern is well formed.
+func Validate(pattern string) error {
+	if pattern == "" {
+		return ErrInvalidPattern
+	}
+
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "**" {
+			continue
+		}
+
+		if _, err := path.Match(segment, ""); err != nil {
+			return ErrInvalidPattern
+		}
+	}
+
+	return nil
+}
+
+// Match reports whether name matches pattern. Other segments of pattern are
+// matched against a single path segment with the syntax of path.Match, a
+// pattern ending with a slash matches everything below the directory.
+func Match(pattern, name string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches names against patterns the way wildcard patterns are
+// matched against strings, `**` standing for any sequence of names. On a
+// mismatch, only the last `**` met takes one more name, which bounds the work
+// by len(patterns)*len(names) however many `**` patterns holds.
+func matchSegments(patterns, names []string) bool {
+	var (
+		p, n         int
+		star, resume = -1, 0
+	)
+	for n < len(names) {
+		switch {
+		case p < len(patterns) && patterns[p] == "**":
+			star, resume = p, n
+			p++
+		case p < len(patterns) && matchSegment(patterns[p], names[n]):
+			p++
+			n++
+		case star >= 0:
+			resume++
+			p, n = star+1, resume
+		default:
+			return false
+		}
+	}
+
+	for p < len(patterns) && patterns[p] == "**" {
+		p++
+	}
+
+	return p == len(patterns)
+}
+
+func matchSegment(pattern, name string) bool {
+	matched, err := path.Match(pattern, name)
+
+	return err == nil && matched
+}
@@ -0,0 +1,60 @@
+package glob
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"app/main.py", "app/main.py", true},
+		{"app/*.py", "app/main.py", true},
+		{"app/*.py", "app/core/main.py", false},
+		{"app/**/*.py", "app/main.py", true},
+		{"app/**/*.py", "app/core/db/main.py", true},
+		{"app/**", "app/core/main.py", true},
+		{"app/", "app/core/main.py", true},
+		{"app/", "application/main.py", false},
+		{"**/*_test.go", "internal/diff/diff_test.go", true},
+		{"**/*_test.go", "internal/diff/diff.go", false},
+		{"*.go", "internal/diff.go", false},
+		{"**/core/**/*.py", "app/core/db/core/main.py", true},
+		{"**/core/**/*.py", "app/db/main.py", false},
+		{"app/**/**/main.py", "app/main.py", true},
+		{"app/**/db", "app/core/db/main.py", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestMatchManyWildcards(t *testing.T) {
+	pattern := strings.Repeat("**/a/", 200) + "x"
+	name := strings.Repeat("a/", 300) + "b"
+
+	done := make(chan bool)
+	go func() { done <- Match(pattern, name) }()
+
+	select {
+	case matched := <-done:
+		assert.False(t, matched)
+	case <-time.After(5 * time.Second):
+		t.Fatal("matching a pattern with many ** timed out")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("src/**/*.[ch]"))
+	assert.ErrorIs(t, Validate("src/[a-"), ErrInvalidPattern)
+	assert.ErrorIs(t, Validate(""), ErrInvalidPattern)
+}
@@ -0,0 +1,239 @@
+// Package repoconfig parses the .goverage.yml file a repository versions and
+// uploads along with its reports.
+package repoconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"goverage/internal/gate"
+	"goverage/internal/glob"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxSize is the size limit of a config file.
+const MaxSize = 64 * 1024
+
+// unsupportedKeys are the top-level keys of the config format not supported
+// yet, rejected rather than silently ignored.
+var unsupportedKeys = []string{"flags"}
+
+// Project holds the settings of a project of the repository.
+type Project struct {
+	// PathPrefix is the directory of the project in the repository, prepended
+	// to report paths relative to the project.
+	PathPrefix string `yaml:"path_prefix"`
+}
+
+type Config struct {
+	// Gate holds the thresholds used when the project has no gate policy stored through the API.
+	Gate gate.Policy `yaml:"gate"`
+	// Ignore holds the glob patterns of the files left out of the totals.
+	Ignore   []string           `yaml:"ignore"`
+	Projects map[string]Project `yaml:"projects"`
+}
+
+// Error is a problem found at a line of a config file.
+type Error struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the problems of an invalid config file.
+type ValidationError struct {
+	Errors []Error
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, fmt.Sprintf("line %d: %s", err.Line, err.Message))
+	}
+
+	return "invalid config: " + strings.Join(messages, "; ")
+}
+
+// yamlErrorPattern extracts the line of the messages of yaml errors.
+var yamlErrorPattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// Parse decodes and validates content, failing with a *ValidationError
+// locating every problem found.
+func Parse(content []byte) (*Config, error) {
+	if len(content) > MaxSize {
+		return nil, &ValidationError{Errors: []Error{{Line: 1, Message: "config must be at most 64KiB"}}}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, yamlError(err)
+	}
+
+	if errs := unsupported(&root); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, yamlError(err)
+	}
+
+	if errs := config.validate(&root); len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	return config, nil
+}
+
+// yamlError converts the syntax and type errors of yaml into a *ValidationError.
+func yamlError(err error) error {
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	errs := make([]Error, 0, len(messages))
+	for _, message := range messages {
+		match := yamlErrorPattern.FindStringSubmatch(message)
+		if match == nil {
+			errs = append(errs, Error{Line: 1, Message: strings.TrimPrefix(message, "yaml: ")})
+			continue
+		}
+
+		line, _ := strconv.Atoi(match[1])
+		errs = append(errs, Error{Line: line, Message: match[2]})
+	}
+
+	return &ValidationError{Errors: errs}
+}
+
+func (c *Config) validate(root *yaml.Node) []Error {
+	var errs []Error
+	check := func(valid bool, message string, path ...interface{}) {
+		if !valid {
+			errs = append(errs, Error{Line: line(root, path...), Message: message})
+		}
+	}
+
+	percent := func(value *float64, name string, path ...interface{}) {
+		check(value == nil || (*value >= 0 && *value <= 100), name+" must be >=0 and <=100", path...)
+	}
+
+	percent(c.Gate.MinCoverage, "min_coverage", "gate", "min_coverage")
+	percent(c.Gate.MaxDrop, "max_drop", "gate", "max_drop")
+	percent(c.Gate.MinPatchCoverage, "min_patch_coverage", "gate", "min_patch_coverage")
+	for i, rule := range c.Gate.Paths {
+		check(glob.Validate(rule.Pattern) == nil, "pattern must be a valid glob", "gate", "paths", i, "pattern")
+		percent(&rule.MinCoverage, "min_coverage", "gate", "paths", i, "min_coverage")
+	}
+
+	for i, pattern := range c.Ignore {
+		check(glob.Validate(pattern) == nil, "ignore pattern must be a valid glob", "ignore", i)
+	}
+
+	names := make([]string, 0, len(c.Projects))
+	for name := range c.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prefix := c.Projects[name].PathPrefix
+		check(
+			!strings.HasPrefix(prefix, "/") && !strings.Contains("/"+prefix+"/", "/../"),
+			"path_prefix must be relative to the repository root",
+			"projects", name, "path_prefix",
+		)
+	}
+
+	return errs
+}
+
+// unsupported reports the unsupported keys found in root.
+func unsupported(root *yaml.Node) []Error {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var errs []Error
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; slices.Contains(unsupportedKeys, key.Value) {
+			errs = append(errs, Error{Line: key.Line, Message: key.Value + " aren't supported yet"})
+		}
+	}
+
+	return errs
+}
+
+// line returns the line of the node at path, made of mapping keys and
+// sequence indexes, or of its closest existing parent.
+func line(root *yaml.Node, path ...interface{}) int {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, step := range path {
+		next := child(node, step)
+		if next == nil {
+			break
+		}
+		node = next
+	}
+
+	return max(node.Line, 1)
+}
+
+func child(node *yaml.Node, step interface{}) *yaml.Node {
+	switch step := step.(type) {
+	case string:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == step {
+				return node.Content[i+1]
+			}
+		}
+	case int:
+		if node.Kind == yaml.SequenceNode && step < len(node.Content) {
+			return node.Content[step]
+		}
+	}
+
+	return nil
+}
+
+// RewritePath returns the path in the repository of a report path of
+// project, and false when the path is ignored. Absolute paths and paths
+// already below the path prefix of the project aren't prefixed again.
+func (c *Config) RewritePath(project, reportPath string) (string, bool) {
+	reportPath = path.Clean(reportPath)
+	if prefix := path.Clean(c.Projects[project].PathPrefix); prefix != "." {
+		if !path.IsAbs(reportPath) && reportPath != prefix && !strings.HasPrefix(reportPath, prefix+"/") {
+			reportPath = path.Join(prefix, reportPath)
+		}
+	}
+
+	for _, pattern := range c.Ignore {
+		if glob.Match(pattern, reportPath) {
+			return reportPath, false
+		}
+	}
+
+	return reportPath, true
+}
@@ -0,0 +1,118 @@
+package repoconfig
+
+import (
+	"testing"
+
+	"goverage/internal/gate"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("ParsesConfig", func(t *testing.T) {
+		config, err := Parse([]byte(`
+gate:
+  min_coverage: 80
+  paths:
+    - pattern: app/core/**
+      min_coverage: 95
+ignore:
+  - "**/*.sql.go"
+projects:
+  api:
+    path_prefix: services/api
+`))
+
+		require.NoError(t, err)
+		assert.Equal(t, &Config{
+			Gate: gate.Policy{
+				MinCoverage: lo.ToPtr(80.0),
+				Paths:       []gate.PathRule{{Pattern: "app/core/**", MinCoverage: 95}},
+			},
+			Ignore:   []string{"**/*.sql.go"},
+			Projects: map[string]Project{"api": {PathPrefix: "services/api"}},
+		}, config)
+	})
+
+	t.Run("AcceptsEmptyConfig", func(t *testing.T) {
+		config, err := Parse(nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, &Config{}, config)
+	})
+
+	t.Run("ReportsLinesOfInvalidValues", func(t *testing.T) {
+		_, err := Parse([]byte(`gate:
+  min_coverage: 120
+ignore:
+  - "src/[a-"
+`))
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []Error{
+			{Line: 2, Message: "min_coverage must be >=0 and <=100"},
+			{Line: 4, Message: "ignore pattern must be a valid glob"},
+		}, validationErr.Errors)
+	})
+
+	t.Run("RejectsUnsupportedKeys", func(t *testing.T) {
+		_, err := Parse([]byte("ignore:\n  - mocks/\nflags:\n  - backend\n"))
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []Error{{Line: 3, Message: "flags aren't supported yet"}}, validationErr.Errors)
+	})
+
+	t.Run("ReportsLinesOfUnknownFields", func(t *testing.T) {
+		_, err := Parse([]byte("gate:\n  threshold: 80\n"))
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []Error{{Line: 2, Message: "field threshold not found in type gate.Policy"}}, validationErr.Errors)
+	})
+
+	t.Run("ReportsLinesOfSyntaxErrors", func(t *testing.T) {
+		_, err := Parse([]byte("ignore:\n  - a\n b: c\n"))
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []Error{{Line: 2, Message: "did not find expected key"}}, validationErr.Errors)
+	})
+}
+
+func TestRewritePath(t *testing.T) {
+	config := &Config{
+		Ignore:   []string{"**/mocks/", "services/api/db/*.sql.go"},
+		Projects: map[string]Project{"api": {PathPrefix: "services/api/"}, "cli": {PathPrefix: "./cli"}},
+	}
+
+	tests := []struct {
+		project string
+		path    string
+		want    string
+		kept    bool
+	}{
+		{"api", "main.go", "services/api/main.go", true},
+		{"api", "services/api/main.go", "services/api/main.go", true},
+		{"api", "./services/api/main.go", "services/api/main.go", true},
+		{"api", "./cmd//main.go", "services/api/cmd/main.go", true},
+		{"api", "/src/services/api/main.go", "/src/services/api/main.go", true},
+		{"api", "db/queries.sql.go", "services/api/db/queries.sql.go", false},
+		{"cli", "cli/main.go", "cli/main.go", true},
+		{"cli", "main.go", "cli/main.go", true},
+		{"web", "src/mocks/api.ts", "src/mocks/api.ts", false},
+		{"web", "src/index.ts", "src/index.ts", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.project+"_"+tt.path, func(t *testing.T) {
+			path, kept := config.RewritePath(tt.project, tt.path)
+
+			assert.Equal(t, tt.want, path)
+			assert.Equal(t, tt.kept, kept)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxCacheEntries bounds a cache, which is emptied when full.
+const maxCacheEntries = 10000
+
+type cacheEntry[V any] struct {
+	value V
+	// notFound caches the absence of a row.
+	notFound  bool
+	expiresAt time.Time
+}
+
+// cache holds the rows read by every badge view. Writes through the store
+// delete their entries, and entries expire after ttl as other instances may
+// write too. A zero ttl disables it.
+type cache[K comparable, V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[K]cacheEntry[V]
+	// deletions counts the calls to delete, so a value loaded while its entry
+	// is deleted isn't cached.
+	deletions uint64
+}
+
+func newCache[K comparable, V any](ttl time.Duration) *cache[K, V] {
+	return &cache[K, V]{ttl: ttl, now: time.Now, entries: map[K]cacheEntry[V]{}}
+}
+
+// get returns the value of key, read through load when missing or expired.
+func (c *cache[K, V]) get(key K, load func() (V, error)) (V, error) {
+	if c.ttl <= 0 {
+		return load()
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	deletions := c.deletions
+	c.mu.Unlock()
+
+	if ok && c.now().Before(entry.expiresAt) {
+		if entry.notFound {
+			var zero V
+			return zero, pgx.ErrNoRows
+		}
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return value, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.deletions != deletions {
+		return value, err
+	}
+
+	if len(c.entries) >= maxCacheEntries {
+		clear(c.entries)
+	}
+	c.entries[key] = cacheEntry[V]{value: value, notFound: err != nil, expiresAt: c.now().Add(c.ttl)}
+
+	return value, err
+}
+
+func (c *cache[K, V]) delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	c.deletions++
+}
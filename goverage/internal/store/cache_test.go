@@ -0,0 +1,80 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	setup := func(ttl time.Duration) (*cache[string, int], *time.Time, *int) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		c := newCache[string, int](ttl)
+		c.now = func() time.Time { return now }
+		loads := 0
+
+		return c, &now, &loads
+	}
+
+	load := func(loads *int, value int, err error) func() (int, error) {
+		return func() (int, error) {
+			*loads++
+			return value, err
+		}
+	}
+
+	t.Run("CachesUntilExpiry", func(t *testing.T) {
+		c, now, loads := setup(time.Minute)
+
+		first, _ := c.get("main", load(loads, 1, nil))
+		second, _ := c.get("main", load(loads, 2, nil))
+		*now = now.Add(time.Minute)
+		third, _ := c.get("main", load(loads, 3, nil))
+
+		assert.Equal(t, []int{1, 1, 3}, []int{first, second, third})
+		assert.Equal(t, 2, *loads)
+	})
+
+	t.Run("ReloadsDeletedEntries", func(t *testing.T) {
+		c, _, loads := setup(time.Minute)
+
+		_, _ = c.get("main", load(loads, 1, nil))
+		c.delete("main")
+		value, _ := c.get("main", load(loads, 2, nil))
+
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("CachesMissingRows", func(t *testing.T) {
+		c, _, loads := setup(time.Minute)
+
+		_, _ = c.get("main", load(loads, 0, pgx.ErrNoRows))
+		_, err := c.get("main", load(loads, 1, nil))
+
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+		assert.Equal(t, 1, *loads)
+	})
+
+	t.Run("SkipsFailures", func(t *testing.T) {
+		c, _, loads := setup(time.Minute)
+
+		_, err := c.get("main", load(loads, 0, errors.New("connection refused")))
+		value, _ := c.get("main", load(loads, 1, nil))
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, value)
+		assert.Equal(t, 2, *loads)
+	})
+
+	t.Run("IsDisabledWithoutTTL", func(t *testing.T) {
+		c, _, loads := setup(0)
+
+		_, _ = c.get("main", load(loads, 1, nil))
+		_, _ = c.get("main", load(loads, 1, nil))
+
+		assert.Equal(t, 2, *loads)
+	})
+}
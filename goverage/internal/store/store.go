@@ -0,0 +1,227 @@
+// Package store persists coverage reports. It extends the sqlc generated
+// queries, which live in a directory wiped on generation, with the writes
+// spanning several tables.
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"goverage/data"
+	"goverage/internal/coverage"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Store struct {
+	*data.Queries
+	pool          *pgxpool.Pool
+	recent        *cache[data.GetRecentCoverageParams, data.Coverage]
+	badgeSettings *cache[data.GetBadgeSettingsParams, data.BadgeSetting]
+}
+
+// New returns a store caching the rows read by badges for cacheTTL, 0 to
+// disable it.
+func New(pool *pgxpool.Pool, cacheTTL time.Duration) *Store {
+	return &Store{
+		Queries:       data.New(pool),
+		pool:          pool,
+		recent:        newCache[data.GetRecentCoverageParams, data.Coverage](cacheTTL),
+		badgeSettings: newCache[data.GetBadgeSettingsParams, data.BadgeSetting](cacheTTL),
+	}
+}
+
+// GetRecentCoverage returns the latest coverage row of a branch, cached until
+// UpsertCoverage writes one for the branch.
+func (s *Store) GetRecentCoverage(ctx context.Context, params data.GetRecentCoverageParams) (data.Coverage, error) {
+	return s.recent.get(params, func() (data.Coverage, error) {
+		return s.Queries.GetRecentCoverage(ctx, params)
+	})
+}
+
+// GetBadgeSettings returns the badge settings of a project, cached until they
+// are upserted or deleted.
+func (s *Store) GetBadgeSettings(ctx context.Context, params data.GetBadgeSettingsParams) (data.BadgeSetting, error) {
+	return s.badgeSettings.get(params, func() (data.BadgeSetting, error) {
+		return s.Queries.GetBadgeSettings(ctx, params)
+	})
+}
+
+func (s *Store) UpsertBadgeSettings(
+	ctx context.Context, params data.UpsertBadgeSettingsParams,
+) (data.BadgeSetting, error) {
+	defer s.badgeSettings.delete(data.GetBadgeSettingsParams{RepoName: params.RepoName, ProjectName: params.ProjectName})
+
+	return s.Queries.UpsertBadgeSettings(ctx, params)
+}
+
+func (s *Store) DeleteBadgeSettings(ctx context.Context, params data.DeleteBadgeSettingsParams) (int64, error) {
+	defer s.badgeSettings.delete(data.GetBadgeSettingsParams(params))
+
+	return s.Queries.DeleteBadgeSettings(ctx, params)
+}
+
+// UpsertCoverage stores the coverage row of params and replaces its files and
+// line ranges with the ones of report, in a single transaction. A row stored
+// before commits were kept in full is given the full commit of params first.
+func (s *Store) UpsertCoverage(
+	ctx context.Context, params data.UpsertCoverageParams, report *coverage.Report,
+) (data.Coverage, error) {
+	return s.upsertCoverage(ctx, params, report, nil)
+}
+
+// UpsertCoverageWithConfig is UpsertCoverage for an uploaded report, which
+// also replaces the config uploaded with the row in the same transaction. A
+// nil config drops the config of a previous upload.
+func (s *Store) UpsertCoverageWithConfig(
+	ctx context.Context, params data.UpsertCoverageParams, report *coverage.Report, config *string,
+) (data.Coverage, error) {
+	return s.upsertCoverage(ctx, params, report, func(queries *data.Queries, coverageID int32) error {
+		if config == nil {
+			if err := queries.DeleteCoverageConfig(ctx, coverageID); err != nil {
+				return fmt.Errorf("failed to delete coverage config: %w", err)
+			}
+
+			return nil
+		}
+
+		if err := queries.UpsertCoverageConfig(ctx, data.UpsertCoverageConfigParams{
+			CoverageID: coverageID,
+			Content:    *config,
+		}); err != nil {
+			return fmt.Errorf("failed to upsert coverage config: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// upsertCoverage implements UpsertCoverage, calling storeConfig, when given,
+// with the stored row before committing.
+func (s *Store) upsertCoverage(
+	ctx context.Context,
+	params data.UpsertCoverageParams,
+	report *coverage.Report,
+	storeConfig func(queries *data.Queries, coverageID int32) error,
+) (data.Coverage, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return data.Coverage{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // Rolling back a committed transaction is a no-op.
+
+	queries := s.WithTx(tx)
+
+	if err := queries.PromoteTruncatedCommit(ctx, data.PromoteTruncatedCommitParams{
+		RepoName:    params.RepoName,
+		ProjectName: params.ProjectName,
+		BranchName:  params.BranchName,
+		Kind:        params.Kind,
+		Commit:      params.Commit,
+	}); err != nil {
+		return data.Coverage{}, fmt.Errorf("failed to promote truncated commit: %w", err)
+	}
+
+	row, err := queries.UpsertCoverage(ctx, params)
+	if err != nil {
+		return data.Coverage{}, fmt.Errorf("failed to upsert coverage: %w", err)
+	}
+
+	if err := queries.DeleteCoverageFiles(ctx, row.ID); err != nil {
+		return data.Coverage{}, fmt.Errorf("failed to delete coverage files: %w", err)
+	}
+
+	files, err := queries.InsertCoverageFiles(ctx, coverageFilesParams(row.ID, report))
+	if err != nil {
+		return data.Coverage{}, fmt.Errorf("failed to insert coverage files: %w", err)
+	}
+
+	fileIDs := make(map[string]int32, len(files))
+	for _, file := range files {
+		fileIDs[file.Path] = file.ID
+	}
+
+	if err := queries.InsertCoverageLineRanges(ctx, lineRangesParams(fileIDs, report)); err != nil {
+		return data.Coverage{}, fmt.Errorf("failed to insert coverage line ranges: %w", err)
+	}
+
+	if storeConfig != nil {
+		if err := storeConfig(queries, row.ID); err != nil {
+			return data.Coverage{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return data.Coverage{}, fmt.Errorf("failed to commit coverage: %w", err)
+	}
+
+	s.recent.delete(data.GetRecentCoverageParams{
+		RepoName:    params.RepoName,
+		ProjectName: params.ProjectName,
+		BranchName:  params.BranchName,
+		Kind:        params.Kind,
+	})
+
+	return row, nil
+}
+
+func coverageFilesParams(coverageID int32, report *coverage.Report) data.InsertCoverageFilesParams {
+	params := data.InsertCoverageFilesParams{
+		CoverageID:        coverageID,
+		Paths:             make([]string, 0, len(report.Files)),
+		Coverages:         make([]float64, 0, len(report.Files)),
+		LinesCovered:      make([]int32, 0, len(report.Files)),
+		LinesTotal:        make([]int32, 0, len(report.Files)),
+		BranchesCovered:   make([]int32, 0, len(report.Files)),
+		BranchesTotal:     make([]int32, 0, len(report.Files)),
+		StatementsCovered: make([]int32, 0, len(report.Files)),
+		StatementsTotal:   make([]int32, 0, len(report.Files)),
+	}
+
+	for _, path := range sortedPaths(report) {
+		file := report.Files[path]
+		params.Paths = append(params.Paths, path)
+		params.Coverages = append(params.Coverages, file.Coverage())
+		params.LinesCovered = append(params.LinesCovered, int32(file.Totals.Lines.Covered))
+		params.LinesTotal = append(params.LinesTotal, int32(file.Totals.Lines.Total))
+		params.BranchesCovered = append(params.BranchesCovered, int32(file.Totals.Branches.Covered))
+		params.BranchesTotal = append(params.BranchesTotal, int32(file.Totals.Branches.Total))
+		params.StatementsCovered = append(params.StatementsCovered, int32(file.Totals.Statements.Covered))
+		params.StatementsTotal = append(params.StatementsTotal, int32(file.Totals.Statements.Total))
+	}
+
+	return params
+}
+
+func lineRangesParams(fileIDs map[string]int32, report *coverage.Report) data.InsertCoverageLineRangesParams {
+	var params data.InsertCoverageLineRangesParams
+	for _, path := range sortedPaths(report) {
+		fileID, ok := fileIDs[path]
+		if !ok {
+			continue
+		}
+
+		for _, lineRange := range coverage.LineRanges(report.Files[path].Lines) {
+			params.CoverageFileIds = append(params.CoverageFileIds, fileID)
+			params.StartLines = append(params.StartLines, int32(lineRange.StartLine))
+			params.EndLines = append(params.EndLines, int32(lineRange.EndLine))
+			params.Hits = append(params.Hits, lineRange.Hits)
+			params.BranchesCovered = append(params.BranchesCovered, int32(lineRange.Branches.Covered))
+			params.BranchesTotal = append(params.BranchesTotal, int32(lineRange.Branches.Total))
+		}
+	}
+
+	return params
+}
+
+func sortedPaths(report *coverage.Report) []string {
+	paths := make([]string, 0, len(report.Files))
+	for path := range report.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
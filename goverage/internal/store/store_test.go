@@ -0,0 +1,62 @@
+package store
+
+import (
+	"testing"
+
+	"goverage/data"
+	"goverage/internal/coverage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *coverage.Report {
+	return &coverage.Report{
+		Files: map[string]*coverage.File{
+			"b.go": {
+				Totals: coverage.Totals{
+					Statements: coverage.Counter{Covered: 1, Total: 4},
+					Lines:      coverage.Counter{Covered: 2, Total: 3},
+					Branches:   coverage.Counter{Covered: 1, Total: 2},
+				},
+				Lines: []coverage.Line{
+					{Number: 1, Hits: 3},
+					{Number: 2, Hits: 3, Branches: &coverage.Counter{Covered: 1, Total: 2}},
+					{Number: 3, Hits: 0},
+				},
+			},
+			"a.py": {
+				Totals: coverage.Totals{Lines: coverage.Counter{Covered: 1, Total: 2}},
+				Lines:  []coverage.Line{{Number: 4, Hits: 1}, {Number: 6, Hits: 0}},
+			},
+		},
+	}
+}
+
+func TestCoverageFilesParams(t *testing.T) {
+	params := coverageFilesParams(42, testReport())
+
+	assert.Equal(t, data.InsertCoverageFilesParams{
+		CoverageID:        42,
+		Paths:             []string{"a.py", "b.go"},
+		Coverages:         []float64{50, 25},
+		LinesCovered:      []int32{1, 2},
+		LinesTotal:        []int32{2, 3},
+		BranchesCovered:   []int32{0, 1},
+		BranchesTotal:     []int32{0, 2},
+		StatementsCovered: []int32{0, 1},
+		StatementsTotal:   []int32{0, 4},
+	}, params)
+}
+
+func TestLineRangesParams(t *testing.T) {
+	params := lineRangesParams(map[string]int32{"a.py": 1, "b.go": 2}, testReport())
+
+	assert.Equal(t, data.InsertCoverageLineRangesParams{
+		CoverageFileIds: []int32{1, 1, 2, 2, 2},
+		StartLines:      []int32{4, 6, 1, 2, 3},
+		EndLines:        []int32{4, 6, 1, 2, 3},
+		Hits:            []int64{1, 0, 3, 3, 0},
+		BranchesCovered: []int32{0, 0, 0, 1, 0},
+		BranchesTotal:   []int32{0, 0, 0, 2, 0},
+	}, params)
+}
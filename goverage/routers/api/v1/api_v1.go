@@ -1,17 +1,33 @@
 package apiv1
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"goverage/data"
+	"goverage/internal/badge"
 	"goverage/internal/config"
+	"goverage/internal/coverage"
+	"goverage/internal/diff"
+	"goverage/internal/gate"
+	"goverage/internal/glob"
 	"goverage/internal/httperrors"
+	"goverage/internal/repoconfig"
+	"hash/fnv"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cohesivestack/valgo"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -23,8 +39,33 @@ type repository interface {
 	ListBranches(ctx context.Context, params data.ListBranchesParams) ([]string, error)
 	GetRecentCoverage(ctx context.Context, params data.GetRecentCoverageParams) (data.Coverage, error)
 	GetCoverageData(ctx context.Context, params data.GetCoverageDataParams) ([]byte, error)
+	ListCoverageLineRanges(ctx context.Context, params data.ListCoverageLineRangesParams) ([]data.ListCoverageLineRangesRow, error)
+	ListCommitsByPrefix(ctx context.Context, params data.ListCommitsByPrefixParams) ([]string, error)
+	ListProjectCommitsByPrefix(ctx context.Context, params data.ListProjectCommitsByPrefixParams) ([]string, error)
+	GetProjectCommitCoverage(ctx context.Context, params data.GetProjectCommitCoverageParams) (data.Coverage, error)
+	GetCommitCoverage(ctx context.Context, params data.GetCommitCoverageParams) (data.Coverage, error)
+	ListCoverageFiles(ctx context.Context, coverageID int32) ([]data.CoverageFile, error)
 	ListCoverageSummary(ctx context.Context, params data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error)
-	UpsertCoverage(ctx context.Context, params data.UpsertCoverageParams) (data.Coverage, error)
+	ListLatestProjectCoverage(ctx context.Context, params data.ListLatestProjectCoverageParams) ([]data.ListLatestProjectCoverageRow, error)
+	ListFileCoverageHistory(ctx context.Context, params data.ListFileCoverageHistoryParams) ([]data.ListFileCoverageHistoryRow, error)
+	InsertFileRenames(ctx context.Context, params data.InsertFileRenamesParams) error
+	ListFileRenames(ctx context.Context, params data.ListFileRenamesParams) ([]data.ListFileRenamesRow, error)
+	UpsertCoverage(ctx context.Context, params data.UpsertCoverageParams, report *coverage.Report) (data.Coverage, error)
+	UpsertCoverageWithConfig(ctx context.Context, params data.UpsertCoverageParams, report *coverage.Report, config *string) (data.Coverage, error)
+	UpsertCoverageShard(ctx context.Context, params data.UpsertCoverageShardParams) error
+	GetCoverageConfig(ctx context.Context, coverageID int32) (string, error)
+	ListStaleProjectCoverage(ctx context.Context, params data.ListStaleProjectCoverageParams) ([]data.Coverage, error)
+	CountStaleProjectCoverage(ctx context.Context, params data.CountStaleProjectCoverageParams) (int64, error)
+	ListExclusionRules(ctx context.Context, params data.ListExclusionRulesParams) ([]data.ExclusionRule, error)
+	InsertExclusionRule(ctx context.Context, params data.InsertExclusionRuleParams) (data.ExclusionRule, error)
+	DeleteExclusionRule(ctx context.Context, params data.DeleteExclusionRuleParams) (int64, error)
+	ListCoverageShards(ctx context.Context, params data.ListCoverageShardsParams) ([]data.CoverageShard, error)
+	GetGatePolicy(ctx context.Context, params data.GetGatePolicyParams) (data.GatePolicy, error)
+	UpsertGatePolicy(ctx context.Context, params data.UpsertGatePolicyParams) (data.GatePolicy, error)
+	DeleteGatePolicy(ctx context.Context, params data.DeleteGatePolicyParams) (int64, error)
+	GetBadgeSettings(ctx context.Context, params data.GetBadgeSettingsParams) (data.BadgeSetting, error)
+	UpsertBadgeSettings(ctx context.Context, params data.UpsertBadgeSettingsParams) (data.BadgeSetting, error)
+	DeleteBadgeSettings(ctx context.Context, params data.DeleteBadgeSettingsParams) (int64, error)
 	ListRepositories(ctx context.Context) ([]string, error)
 	ListProjects(ctx context.Context, repoName string) ([]string, error)
 }
@@ -34,68 +75,2067 @@ type Router struct {
 	repo repository
 }
 
-type PythonCoverageJSONFile struct {
-	Meta struct {
-		Format    int    `json:"format"`
-		Version   string `json:"version"`
-		Timestamp string `json:"timestamp"`
-	} `json:"meta"`
-	Totals struct {
-		CoveredLines   int     `json:"covered_lines"`
-		NumStatements  int     `json:"num_statements"`
-		PercentCovered float64 `json:"percent_covered"`
-		MissingLines   int     `json:"missing_lines"`
-	} `json:"totals"`
+type CoverageSchema struct {
+	RepoName        string    `json:"repo_name"`
+	ProjectName     string    `json:"project_name"`
+	BranchName      string    `json:"branch_name"`
+	Commit          string    `json:"commit"`
+	Coverage        float64   `json:"coverage"`
+	CoverageDate    time.Time `json:"coverage_date"`
+	Format          string    `json:"format"`
+	Kind            string    `json:"kind"`
+	BranchesCovered int32     `json:"branches_covered"`
+	BranchesTotal   int32     `json:"branches_total"`
+	// BranchCoverage is nil for reports without branch data.
+	BranchCoverage *float64 `json:"branch_coverage"`
+	// OriginalCoverage is the coverage of the report as uploaded, before excluded files were left out.
+	OriginalCoverage float64 `json:"original_coverage"`
+}
+
+func branchCoverage(covered, total int32) *float64 {
+	return optionalPercent(coverage.Counter{Covered: int(covered), Total: int(total)})
+}
+
+// optionalPercent returns the percent of counter, or nil when it tracks nothing.
+func optionalPercent(counter coverage.Counter) *float64 {
+	if counter.Total == 0 {
+		return nil
+	}
+
+	return lo.ToPtr(counter.Percent())
+}
+
+// lineRangesByPath groups line range rows by file path.
+func lineRangesByPath(rows []data.ListCoverageLineRangesRow) map[string][]coverage.LineRange {
+	ranges := map[string][]coverage.LineRange{}
+	for _, row := range rows {
+		ranges[row.Path] = append(ranges[row.Path], coverage.LineRange{
+			StartLine: int(row.StartLine),
+			EndLine:   int(row.EndLine),
+			Hits:      row.Hits,
+			Branches:  coverage.Counter{Covered: int(row.BranchesCovered), Total: int(row.BranchesTotal)},
+		})
+	}
+
+	return ranges
+}
+
+func coverageModelToSchema(coverage data.Coverage) CoverageSchema {
+	return CoverageSchema{
+		RepoName:         coverage.RepoName,
+		ProjectName:      coverage.ProjectName,
+		BranchName:       coverage.BranchName,
+		Commit:           coverage.Commit,
+		Coverage:         coverage.Coverage,
+		CoverageDate:     coverage.CoverageDate.Time,
+		Format:           coverage.Format,
+		Kind:             coverage.Kind,
+		BranchesCovered:  coverage.BranchesCovered,
+		BranchesTotal:    coverage.BranchesTotal,
+		BranchCoverage:   branchCoverage(coverage.BranchesCovered, coverage.BranchesTotal),
+		OriginalCoverage: coverage.OriginalCoverage,
+	}
+}
+
+func coverageSummaryModelToSchema(coverage data.ListCoverageSummaryRow) CoverageSchema {
+	return CoverageSchema{
+		RepoName:         coverage.RepoName,
+		ProjectName:      coverage.ProjectName,
+		BranchName:       coverage.BranchName,
+		Commit:           coverage.Commit,
+		Coverage:         coverage.Coverage,
+		CoverageDate:     coverage.CoverageDate.Time,
+		Format:           coverage.Format,
+		Kind:             coverage.Kind,
+		BranchesCovered:  coverage.BranchesCovered,
+		BranchesTotal:    coverage.BranchesTotal,
+		BranchCoverage:   branchCoverage(coverage.BranchesCovered, coverage.BranchesTotal),
+		OriginalCoverage: coverage.OriginalCoverage,
+	}
+}
+
+func coverageFormats() []string {
+	return lo.Map(coverage.Formats(), func(format coverage.Format, _ int) string {
+		return string(format)
+	})
+}
+
+var coverageKinds = []string{coverage.KindUnit, coverage.KindIntegration}
+
+var (
+	// fullCommitPattern matches full SHA-1 and SHA-256 commit hashes.
+	fullCommitPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+	// commitPrefixPattern matches the commit prefixes accepted by lookups.
+	commitPrefixPattern = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+)
+
+func validateFullCommit(commit string) *valgo.Validation {
+	return valgo.Is(valgo.
+		String(commit, "commit").
+		MatchingTo(fullCommitPattern, "Commit must be a full 40 or 64 characters SHA"),
+	)
+}
+
+func validateCommitPrefix(commit string) error {
+	validate := valgo.Is(valgo.
+		String(commit, "commit").
+		MatchingTo(commitPrefixPattern, "Commit must be a SHA prefix of at least 7 characters"),
+	)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+type CommitConflictSchema struct {
+	Message    string   `json:"message"`
+	Candidates []string `json:"candidates"`
+}
+
+// resolveCommit returns the stored commit matching the prefix of params,
+// failing with 404 when none does and 409 when several do. A commit stored
+// both truncated and in full matches once, in full.
+func (r *Router) resolveCommit(ctx context.Context, params data.ListCommitsByPrefixParams) (string, error) {
+	commits, err := r.repo.ListCommitsByPrefix(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list commits")
+		return "", echo.NewHTTPError(http.StatusInternalServerError, "failed to list commits")
+	}
+
+	return pickCommit(commits)
+}
+
+// resolveProjectCommit is resolveCommit for commits of any branch of a project.
+func (r *Router) resolveProjectCommit(ctx context.Context, params data.ListProjectCommitsByPrefixParams) (string, error) {
+	commits, err := r.repo.ListProjectCommitsByPrefix(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list commits")
+		return "", echo.NewHTTPError(http.StatusInternalServerError, "failed to list commits")
+	}
+
+	return pickCommit(commits)
+}
+
+// pickCommit returns the single commit matching a prefix.
+func pickCommit(commits []string) (string, error) {
+	switch len(commits) {
+	case 0:
+		return "", echo.NewHTTPError(http.StatusNotFound, "commit not found")
+	case 1:
+		return commits[0], nil
+	default:
+		return "", echo.NewHTTPError(http.StatusConflict, CommitConflictSchema{
+			Message:    "commit prefix is ambiguous",
+			Candidates: commits,
+		})
+	}
+}
+
+func validateCoverageKind(kind string) error {
+	validate := valgo.Is(valgo.
+		String(kind, "kind").
+		InSlice(coverageKinds, "Kind must be one of: "+strings.Join(coverageKinds, ", ")),
+	)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+func NewAPIV1Router(e *echo.Echo, repo repository) *Router {
+	return &Router{e: e, repo: repo}
+}
+
+type PostCoverageRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	Commit      string `param:"commit"`
+	Format      string `form:"format"`
+	// Shard identifies the test shard of the upload, reports of sharded runs
+	// are merged once every shard is uploaded.
+	Shard string `form:"shard"`
+	// Shards is the number of shards expected for the commit, 0 when the
+	// shards are merged by an explicit finalize call.
+	Shards int32 `form:"shards"`
+}
+
+func (pr *PostCoverageRequest) Validate() error {
+	validate := validateFullCommit(pr.Commit).
+		Is(valgo.
+			String(pr.Format, "format").
+			Empty().Or().InSlice(coverageFormats(), "Format must be one of: "+strings.Join(coverageFormats(), ", ")),
+		).
+		Is(valgo.String(pr.Shard, "shard").
+			MaxLength(255, "Shard must be at most 255 characters long"),
+		).
+		Is(valgo.Int32(pr.Shards, "shards").
+			Between(0, 1000, "Shards must be >=0 and <=1000"),
+		).
+		Is(valgo.Bool(pr.Shards == 0 || pr.Shard != "", "shard").
+			True("Shard is required when shards is given"),
+		)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+func (r *Router) PostCoverage(c echo.Context) error {
+	var reqData PostCoverageRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+	reqData.Commit = strings.ToLower(reqData.Commit)
+
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	rawFileData, err := readFormFile(c, "coverage")
+	if err != nil {
+		return err
+	}
+
+	config, err := readConfig(c)
+	if err != nil {
+		return err
+	}
+
+	renames, err := readRenames(c)
+	if err != nil {
+		return err
+	}
+
+	report, err := coverage.Parse(rawFileData, coverage.Format(reqData.Format))
+	if err != nil {
+		if errors.Is(err, coverage.ErrUndetectedFormat) {
+			return echo.NewHTTPError(http.StatusBadRequest, "failed to detect coverage file format")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to parse coverage file")
+	}
+
+	params := data.UpsertCoverageParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Commit:      reqData.Commit,
+		Kind:        coverage.KindUnit,
+	}
+
+	if err := r.storeRenames(c, params, renames); err != nil {
+		return err
+	}
+
+	if reqData.Shard != "" {
+		return r.storeShard(c, params, data.UpsertCoverageShardParams{
+			ShardID: reqData.Shard,
+			Format:  string(report.Format),
+			Content: rawFileData,
+			ExpectedShards: pgtype.Int4{
+				Int32: reqData.Shards,
+				Valid: reqData.Shards > 0,
+			},
+		}, config)
+	}
+
+	return r.storeReport(c, params, report, config)
+}
+
+type ShardsSchema struct {
+	ShardsReceived int   `json:"shards_received"`
+	ShardsExpected int32 `json:"shards_expected"`
+}
+
+// storeShard stores the shard upload of the commit identified by params, and
+// merges the shards of the commit once the expected number is reached. Only
+// the config uploaded with the shard completing the commit is kept.
+func (r *Router) storeShard(
+	c echo.Context, params data.UpsertCoverageParams, shard data.UpsertCoverageShardParams, config *uploadedConfig,
+) error {
+	ctx := c.Request().Context()
+
+	shard.RepoName = params.RepoName
+	shard.ProjectName = params.ProjectName
+	shard.BranchName = params.BranchName
+	shard.Commit = params.Commit
+
+	if err := r.repo.UpsertCoverageShard(ctx, shard); err != nil {
+		log.Error().Err(err).Msg("Failed to upsert coverage shard")
+		return c.String(http.StatusInternalServerError, "failed to upsert coverage shard")
+	}
+
+	shards, err := r.repo.ListCoverageShards(ctx, data.ListCoverageShardsParams{
+		RepoName:    params.RepoName,
+		ProjectName: params.ProjectName,
+		BranchName:  params.BranchName,
+		Commit:      params.Commit,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list coverage shards")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list coverage shards")
+	}
+
+	var expected int32
+	for _, stored := range shards {
+		expected = max(expected, stored.ExpectedShards.Int32)
+	}
+
+	if expected == 0 || len(shards) < int(expected) {
+		return c.JSON(http.StatusAccepted, ShardsSchema{ShardsReceived: len(shards), ShardsExpected: expected})
+	}
+
+	return r.mergeShards(c, params, shards, config)
+}
+
+// mergeShards stores the merged report of shards as the report of the commit identified by params.
+func (r *Router) mergeShards(
+	c echo.Context, params data.UpsertCoverageParams, shards []data.CoverageShard, config *uploadedConfig,
+) error {
+	reports := make([]*coverage.Report, 0, len(shards))
+	for _, shard := range shards {
+		report, err := coverage.Parse(shard.Content, coverage.Format(shard.Format))
+		if err != nil {
+			log.Error().Err(err).Str("shard", shard.ShardID).Msg("Failed to parse coverage shard")
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to parse coverage shard")
+		}
+		reports = append(reports, report)
+	}
+
+	report, err := coverage.Merge(reports...)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to merge coverage shards")
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to merge coverage shards")
+	}
+
+	return r.storeReport(c, params, report, config)
+}
+
+type FinalizeCoverageRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	Commit      string `param:"commit"`
+}
+
+func (fr *FinalizeCoverageRequest) Validate() error {
+	validate := validateFullCommit(fr.Commit)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+// FinalizeCoverage merges the shards uploaded for a commit into its report.
+// Shards are kept, so a commit can be finalized again after a shard is retried.
+func (r *Router) FinalizeCoverage(c echo.Context) error {
+	var reqData FinalizeCoverageRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+	reqData.Commit = strings.ToLower(reqData.Commit)
+
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	shards, err := r.repo.ListCoverageShards(c.Request().Context(), data.ListCoverageShardsParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Commit:      reqData.Commit,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list coverage shards")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list coverage shards")
+	}
+
+	if len(shards) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "no coverage shard uploaded for commit")
+	}
+
+	config, err := readConfig(c)
+	if err != nil {
+		return err
+	}
+
+	return r.mergeShards(c, data.UpsertCoverageParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Commit:      reqData.Commit,
+		Kind:        coverage.KindUnit,
+	}, shards, config)
+}
+
+type PostIntegrationCoverageRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	Commit      string `param:"commit"`
+}
+
+func (pr *PostIntegrationCoverageRequest) Validate() error {
+	validate := validateFullCommit(pr.Commit)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+// PostIntegrationCoverage stores the coverage of an archived GOCOVERDIR, filled
+// by binaries built with `go build -cover`, as the integration report of a commit.
+func (r *Router) PostIntegrationCoverage(c echo.Context) error {
+	var reqData PostIntegrationCoverageRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+	reqData.Commit = strings.ToLower(reqData.Commit)
+
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	archive, err := readFormFile(c, "archive")
+	if err != nil {
+		return err
+	}
+
+	report, err := coverage.ParseGoCoverDir(archive)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to parse coverage archive")
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to parse coverage archive")
+	}
+
+	config, err := readConfig(c)
+	if err != nil {
+		return err
+	}
+
+	return r.storeReport(c, data.UpsertCoverageParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Commit:      reqData.Commit,
+		Kind:        coverage.KindIntegration,
+	}, report, config)
+}
+
+// bindWithQuery binds the request like c.Bind, and its query params too, which
+// echo only binds for GET, DELETE and HEAD requests.
+func bindWithQuery(c echo.Context, i interface{}) error {
+	if err := c.Bind(i); err != nil {
+		return err
+	}
+
+	return (&echo.DefaultBinder{}).BindQueryParams(c, i)
+}
+
+// hasFormFile reports whether the name multipart file is uploaded.
+func hasFormFile(c echo.Context, name string) bool {
+	_, err := c.FormFile(name)
+	return err == nil
+}
+
+// readFormFile reads the content of the name multipart file.
+func readFormFile(c echo.Context, name string) ([]byte, error) {
+	formFile, err := c.FormFile(name)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, name+" file is required")
+	}
+
+	src, err := formFile.Open()
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to open %s file", name)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to open "+name+" file")
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(src)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to read %s file", name)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to read "+name+" file")
+	}
+
+	return content, nil
+}
+
+type ConfigValidationSchema struct {
+	Valid  bool               `json:"valid"`
+	Errors []repoconfig.Error `json:"errors"`
+}
+
+// uploadedConfig is a .goverage.yml file uploaded along with a report.
+type uploadedConfig struct {
+	config  *repoconfig.Config
+	content []byte
+}
+
+// parseConfig parses content, failing with 400 and the located problems when it is invalid.
+func parseConfig(content []byte) (*repoconfig.Config, error) {
+	config, err := repoconfig.Parse(content)
+	if err != nil {
+		var validationErr *repoconfig.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "failed to parse config")
+		}
+
+		return nil, echo.NewHTTPError(http.StatusBadRequest, ConfigValidationSchema{
+			Valid:  false,
+			Errors: validationErr.Errors,
+		})
+	}
+
+	return config, nil
+}
+
+// readConfig parses the optional config multipart file, nil when none is uploaded.
+func readConfig(c echo.Context) (*uploadedConfig, error) {
+	if !hasFormFile(c, "config") {
+		return nil, nil
+	}
+
+	content, err := readFormFile(c, "config")
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := parseConfig(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &uploadedConfig{config: config, content: content}, nil
+}
+
+// readRenames parses the optional renames multipart file, the output of `git
+// diff --name-status -M` between the commit and its parent.
+func readRenames(c echo.Context) (map[string]string, error) {
+	if !hasFormFile(c, "renames") {
+		return nil, nil
+	}
+
+	content, err := readFormFile(c, "renames")
+	if err != nil {
+		return nil, err
+	}
+
+	renames, err := diff.ParseRenames(bytes.NewReader(content))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to parse renames")
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "failed to parse renames")
+	}
+
+	return renames, nil
+}
+
+// storeRenames stores the files renamed by the commit identified by params,
+// followed by the file coverage history of its branch.
+func (r *Router) storeRenames(c echo.Context, params data.UpsertCoverageParams, renames map[string]string) error {
+	if len(renames) == 0 {
+		return nil
+	}
+
+	oldPaths := lo.Keys(renames)
+	sort.Strings(oldPaths)
+
+	if err := r.repo.InsertFileRenames(c.Request().Context(), data.InsertFileRenamesParams{
+		RepoName:    params.RepoName,
+		ProjectName: params.ProjectName,
+		BranchName:  params.BranchName,
+		Commit:      params.Commit,
+		OldPaths:    oldPaths,
+		NewPaths:    lo.Map(oldPaths, func(oldPath string, _ int) string { return renames[oldPath] }),
+	}); err != nil {
+		log.Error().Err(err).Msg("Failed to insert file renames")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to insert file renames")
+	}
+
+	return nil
+}
+
+// storeReport completes params, which identify the stored report, with the
+// parsed report. The paths of the report are rewritten by config when one is
+// uploaded, the raw data keeping the report as uploaded.
+func (r *Router) storeReport(
+	c echo.Context, params data.UpsertCoverageParams, report *coverage.Report, config *uploadedConfig,
+) error {
+	ctx := c.Request().Context()
+
+	if report.Timestamp.IsZero() {
+		report.Timestamp = time.Now().UTC()
+	}
+
+	rawData, err := report.RawData()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode coverage report")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to encode coverage report")
+	}
+
+	patterns, err := r.exclusionPatterns(ctx, params.RepoName, params.ProjectName)
+	if err != nil {
+		return err
+	}
+
+	var repoConfig *repoconfig.Config
+	if config != nil {
+		repoConfig = config.config
+	}
+	report = excludeFiles(&params, report, repoConfig, patterns)
+
+	params.CoverageDate = pgtype.Timestamptz{
+		Time:  report.Timestamp,
+		Valid: true,
+	}
+	params.RawData = rawData
+	params.Format = string(report.Format)
+
+	// A report uploaded again without config drops the config of the previous upload.
+	var configContent *string
+	if config != nil {
+		configContent = lo.ToPtr(string(config.content))
+	}
+
+	if _, err := r.repo.UpsertCoverageWithConfig(ctx, params, report, configContent); err != nil {
+		log.Error().Err(err).Msg("Failed to upsert coverage")
+		return c.String(http.StatusInternalServerError, "failed to upsert coverage")
+	}
+
+	return c.NoContent(http.StatusCreated)
+}
+
+// excludeFiles rewrites the paths of report by config, when given, and leaves
+// out the files config ignores or matching one of patterns. params is completed
+// with the totals of report before and after.
+func excludeFiles(
+	params *data.UpsertCoverageParams, report *coverage.Report, config *repoconfig.Config, patterns []string,
+) *coverage.Report {
+	params.RulesVersion = rulesVersion(patterns)
+	params.OriginalCoverage = report.Coverage
+	params.OriginalLinesCovered = int32(report.Totals.Lines.Covered)
+	params.OriginalLinesTotal = int32(report.Totals.Lines.Total)
+
+	report = report.RewritePaths(func(path string) (string, bool) {
+		kept := true
+		if config != nil {
+			path, kept = config.RewritePath(params.ProjectName, path)
+		}
+
+		return path, kept && !lo.SomeBy(patterns, func(pattern string) bool { return glob.Match(pattern, path) })
+	})
+
+	params.Coverage = report.Coverage
+	params.LinesCovered = int32(report.Totals.Lines.Covered)
+	params.LinesTotal = int32(report.Totals.Lines.Total)
+	params.BranchesCovered = int32(report.Totals.Branches.Covered)
+	params.BranchesTotal = int32(report.Totals.Branches.Total)
+	params.FunctionsCovered = int32(report.Totals.Functions.Covered)
+	params.FunctionsTotal = int32(report.Totals.Functions.Total)
+
+	return report
+}
+
+// rulesVersion identifies the exclusion patterns a report is stored with, ""
+// for none.
+func rulesVersion(patterns []string) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+
+	sorted := slices.Clone(patterns)
+	slices.Sort(sorted)
+
+	hash := fnv.New64a()
+	for _, pattern := range sorted {
+		hash.Write([]byte(pattern))
+		hash.Write([]byte{0})
+	}
+
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
+
+// exclusionPatterns returns the patterns of the exclusion rules of a project.
+func (r *Router) exclusionPatterns(ctx context.Context, repoName, projectName string) ([]string, error) {
+	rules, err := r.repo.ListExclusionRules(ctx, data.ListExclusionRulesParams{
+		RepoName:    repoName,
+		ProjectName: projectName,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list exclusion rules")
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to list exclusion rules")
+	}
+
+	return lo.Map(rules, func(rule data.ExclusionRule, _ int) string { return rule.Pattern }), nil
+}
+
+type GetLatestBranchCoverageRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	Kind        string `query:"kind"`
+}
+
+func (r *Router) GetLatestBranchCoverage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData GetLatestBranchCoverageRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+
+	if reqData.Kind == "" {
+		reqData.Kind = coverage.KindUnit
+	}
+	if err := validateCoverageKind(reqData.Kind); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	recentCoverage, err := r.repo.GetRecentCoverage(ctx, data.GetRecentCoverageParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Kind:        reqData.Kind,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "failed to get recent coverage")
+	}
+
+	return c.JSON(http.StatusOK, coverageModelToSchema(recentCoverage))
+}
+
+type GetRepoCoverageRequest struct {
+	RepoName   string `param:"repoName"`
+	BranchName string `param:"branchName"`
+	Kind       string `query:"kind"`
+}
+
+type ProjectCoverageSchema struct {
+	ProjectName  string    `json:"project_name"`
+	Commit       string    `json:"commit"`
+	CoverageDate time.Time `json:"coverage_date"`
+	Coverage     float64   `json:"coverage"`
+	// Statements is the weight of the project in the repository coverage, its
+	// lines for formats that don't count statements.
+	Statements int `json:"statements"`
+}
+
+type RepoCoverageSchema struct {
+	RepoName   string                  `json:"repo_name"`
+	BranchName string                  `json:"branch_name"`
+	Kind       string                  `json:"kind"`
+	Coverage   float64                 `json:"coverage"`
+	Statements int                     `json:"statements"`
+	Projects   []ProjectCoverageSchema `json:"projects"`
+}
+
+// GetRepoCoverage aggregates the latest report of every project of a branch
+// into the coverage of the repository, weighted by the statements of each project.
+func (r *Router) GetRepoCoverage(c echo.Context) error {
+	var reqData GetRepoCoverageRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+
+	if reqData.Kind == "" {
+		reqData.Kind = coverage.KindUnit
+	}
+	if err := validateCoverageKind(reqData.Kind); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	rows, err := r.repo.ListLatestProjectCoverage(c.Request().Context(), data.ListLatestProjectCoverageParams{
+		RepoName:   reqData.RepoName,
+		BranchName: reqData.BranchName,
+		Kind:       reqData.Kind,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list latest project coverage")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to list latest project coverage")
+	}
+
+	if len(rows) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "no coverage for branch")
+	}
+
+	schema := RepoCoverageSchema{
+		RepoName:   reqData.RepoName,
+		BranchName: reqData.BranchName,
+		Kind:       reqData.Kind,
+		Projects:   make([]ProjectCoverageSchema, 0, len(rows)),
+	}
+	weighted := make([]coverage.Weighted, 0, len(rows))
+	for _, row := range rows {
+		statements := coverage.Weight(int(row.StatementsTotal), int(row.LinesTotal))
+		weighted = append(weighted, coverage.Weighted{Coverage: row.Coverage, Weight: statements})
+		schema.Statements += statements
+		schema.Projects = append(schema.Projects, ProjectCoverageSchema{
+			ProjectName:  row.ProjectName,
+			Commit:       row.Commit,
+			CoverageDate: row.CoverageDate.Time,
+			Coverage:     row.Coverage,
+			Statements:   statements,
+		})
+	}
+	schema.Coverage = coverage.WeightedMean(weighted)
+
+	return c.JSON(http.StatusOK, schema)
+}
+
+type GetCoverageDataRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	Commit      string `param:"commit"`
+	Kind        string `query:"kind"`
+}
+
+func (r *Router) GetCoverageData(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData GetCoverageDataRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+
+	if reqData.Kind == "" {
+		reqData.Kind = coverage.KindUnit
+	}
+	if err := validateCoverageKind(reqData.Kind); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	reqData.Commit = strings.ToLower(reqData.Commit)
+	if err := validateCommitPrefix(reqData.Commit); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	commit, err := r.resolveCommit(ctx, data.ListCommitsByPrefixParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Kind:        reqData.Kind,
+		Prefix:      reqData.Commit,
+	})
+	if err != nil {
+		return err
+	}
+
+	coverageData, err := r.repo.GetCoverageData(ctx, data.GetCoverageDataParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Commit:      commit,
+		Kind:        reqData.Kind,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "failed to get coverage data")
+	}
+
+	var jsonData map[string]interface{}
+	if err := json.Unmarshal(coverageData, &jsonData); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unmarshal coverage data")
+	}
+
+	return c.JSON(http.StatusOK, jsonData)
+}
+
+type GetCoverageTreeRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	Commit      string `param:"commit"`
+	Kind        string `query:"kind"`
+	// Path is the directory or file the tree is rooted at, the whole project when empty.
+	Path string `query:"path"`
+	// Depth is the number of levels returned below the root, 0 for every level.
+	Depth int `query:"depth"`
+}
+
+func (gr *GetCoverageTreeRequest) Validate() error {
+	validate := valgo.
+		Is(valgo.String(gr.Commit, "commit").
+			MatchingTo(commitPrefixPattern, "Commit must be a SHA prefix of at least 7 characters"),
+		).
+		Is(valgo.String(gr.Kind, "kind").
+			InSlice(coverageKinds, "Kind must be one of: "+strings.Join(coverageKinds, ", ")),
+		).
+		Is(valgo.Int(gr.Depth, "depth").
+			Between(0, 100, "Depth must be >=0 and <=100"),
+		)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+type TreeNodeSchema struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Type is directory or file.
+	Type              string  `json:"type"`
+	Coverage          float64 `json:"coverage"`
+	StatementsCovered int     `json:"statements_covered"`
+	StatementsTotal   int     `json:"statements_total"`
+	LinesCovered      int     `json:"lines_covered"`
+	LinesTotal        int     `json:"lines_total"`
+	// Children is left out for files and for directories below the requested depth.
+	Children []TreeNodeSchema `json:"children,omitempty"`
+}
+
+func treeNodeToSchema(node *coverage.TreeNode) TreeNodeSchema {
+	schema := TreeNodeSchema{
+		Name:              node.Name,
+		Path:              node.Path,
+		Type:              node.Type,
+		Coverage:          node.Coverage(),
+		StatementsCovered: node.Statements.Covered,
+		StatementsTotal:   node.Statements.Total,
+		LinesCovered:      node.Lines.Covered,
+		LinesTotal:        node.Lines.Total,
+	}
+
+	if node.Children != nil {
+		schema.Children = lo.Map(node.Children, func(child *coverage.TreeNode, _ int) TreeNodeSchema {
+			return treeNodeToSchema(child)
+		})
+	}
+
+	return schema
+}
+
+// GetCoverageTree returns the files of a commit rolled up into a tree of
+// directories, Go packages being directories, with their totals at every level.
+func (r *Router) GetCoverageTree(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData GetCoverageTreeRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+	reqData.Commit = strings.ToLower(reqData.Commit)
+	if reqData.Kind == "" {
+		reqData.Kind = coverage.KindUnit
+	}
+
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	commit, err := r.resolveCommit(ctx, data.ListCommitsByPrefixParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Kind:        reqData.Kind,
+		Prefix:      reqData.Commit,
+	})
+	if err != nil {
+		return err
+	}
+
+	commitCoverage, err := r.repo.GetCommitCoverage(ctx, data.GetCommitCoverageParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Commit:      commit,
+		Kind:        reqData.Kind,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "failed to get commit coverage")
+	}
+
+	files, err := r.repo.ListCoverageFiles(ctx, commitCoverage.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list coverage files")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to list coverage files")
+	}
+
+	tree, ok := coverage.Tree(lo.Map(files, func(file data.CoverageFile, _ int) coverage.TreeFile {
+		return coverage.TreeFile{
+			Path:       file.Path,
+			Statements: coverage.Counter{Covered: int(file.StatementsCovered), Total: int(file.StatementsTotal)},
+			Lines:      coverage.Counter{Covered: int(file.LinesCovered), Total: int(file.LinesTotal)},
+		}
+	}), reqData.Path, reqData.Depth)
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "no covered file at path")
+	}
+
+	return c.JSON(http.StatusOK, treeNodeToSchema(tree))
+}
+
+type GetUncoveredLinesRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	Commit      string `param:"commit"`
+	Kind        string `query:"kind"`
+	// Path is the file or directory the lines are listed for, every file when empty.
+	Path string `query:"path"`
+}
+
+func (gr *GetUncoveredLinesRequest) Validate() error {
+	validate := valgo.
+		Is(valgo.String(gr.Commit, "commit").
+			MatchingTo(commitPrefixPattern, "Commit must be a SHA prefix of at least 7 characters"),
+		).
+		Is(valgo.String(gr.Kind, "kind").
+			InSlice(coverageKinds, "Kind must be one of: "+strings.Join(coverageKinds, ", ")),
+		)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+type PartialLinesSchema struct {
+	StartLine       int   `json:"start_line"`
+	EndLine         int   `json:"end_line"`
+	Hits            int64 `json:"hits"`
+	BranchesCovered int   `json:"branches_covered"`
+	BranchesTotal   int   `json:"branches_total"`
+}
+
+type UncoveredFileSchema struct {
+	Path string `json:"path"`
+	// Uncovered holds [start, end] pairs of lines never hit.
+	Uncovered [][2]int             `json:"uncovered"`
+	Partial   []PartialLinesSchema `json:"partial"`
+}
+
+type UncoveredLinesSchema struct {
+	Commit string                `json:"commit"`
+	Files  []UncoveredFileSchema `json:"files"`
+}
+
+// GetUncoveredLines returns the uncovered and partially covered lines of the
+// files of a commit, compressed into ranges. Fully covered files are left out.
+func (r *Router) GetUncoveredLines(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData GetUncoveredLinesRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+	reqData.Commit = strings.ToLower(reqData.Commit)
+	if reqData.Kind == "" {
+		reqData.Kind = coverage.KindUnit
+	}
+
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	commit, err := r.resolveCommit(ctx, data.ListCommitsByPrefixParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Kind:        reqData.Kind,
+		Prefix:      reqData.Commit,
+	})
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.repo.ListCoverageLineRanges(ctx, data.ListCoverageLineRangesParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Commit:      commit,
+		Kind:        reqData.Kind,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list coverage line ranges")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to list coverage line ranges")
+	}
+
+	ranges := lineRangesByPath(rows)
+	paths := lo.Filter(lo.Keys(ranges), func(path string, _ int) bool { return coverage.InPath(path, reqData.Path) })
+	sort.Strings(paths)
+
+	files := make([]UncoveredFileSchema, 0, len(paths))
+	for _, path := range paths {
+		uncovered := coverage.Uncovered(ranges[path])
+		if uncovered.Empty() {
+			continue
+		}
+
+		files = append(files, UncoveredFileSchema{
+			Path:      path,
+			Uncovered: uncovered.Uncovered,
+			Partial: lo.Map(uncovered.Partial, func(lineRange coverage.LineRange, _ int) PartialLinesSchema {
+				return PartialLinesSchema{
+					StartLine:       lineRange.StartLine,
+					EndLine:         lineRange.EndLine,
+					Hits:            lineRange.Hits,
+					BranchesCovered: lineRange.Branches.Covered,
+					BranchesTotal:   lineRange.Branches.Total,
+				}
+			}),
+		})
+	}
+
+	return c.JSON(http.StatusOK, UncoveredLinesSchema{Commit: commit, Files: files})
+}
+
+type PostPatchCoverageRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	Commit      string `param:"commit"`
+	Base        string `query:"base"`
+	Kind        string `query:"kind"`
+}
+
+type PatchFileSchema struct {
+	Path           string  `json:"path"`
+	Coverage       float64 `json:"coverage"`
+	LinesCovered   int     `json:"lines_covered"`
+	LinesTotal     int     `json:"lines_total"`
+	UncoveredLines []int   `json:"uncovered_lines"`
+}
+
+type PatchCoverageSchema struct {
+	Commit string `json:"commit"`
+	// Coverage is nil when the diff changes no executable line.
+	Coverage     *float64          `json:"coverage"`
+	LinesCovered int               `json:"lines_covered"`
+	LinesTotal   int               `json:"lines_total"`
+	Files        []PatchFileSchema `json:"files"`
+	// Approximate is set when the changed lines were approximated from the
+	// report of a base commit rather than read from a diff.
+	Approximate bool `json:"approximate"`
+}
+
+func patchToSchema(commit string, patch coverage.Patch, approximate bool) PatchCoverageSchema {
+	return PatchCoverageSchema{
+		Commit:       commit,
+		Approximate:  approximate,
+		Coverage:     optionalPercent(patch.Lines),
+		LinesCovered: patch.Lines.Covered,
+		LinesTotal:   patch.Lines.Total,
+		Files: lo.Map(patch.Files, func(file coverage.PatchFile, _ int) PatchFileSchema {
+			return PatchFileSchema{
+				Path:           file.Path,
+				Coverage:       file.Lines.Percent(),
+				LinesCovered:   file.Lines.Covered,
+				LinesTotal:     file.Lines.Total,
+				UncoveredLines: file.UncoveredLines,
+			}
+		}),
+	}
+}
+
+// PostPatchCoverage computes the coverage of the lines added or modified by
+// the unified diff uploaded as the diff file, from the report of a commit.
+// Without a diff, the changed lines are approximated as those executable in
+// the commit but not in the base commit, which may have been uploaded on any
+// branch, and the result is flagged approximate. Gates only use diffs.
+func (r *Router) PostPatchCoverage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData PostPatchCoverageRequest
+	if err := bindWithQuery(c, &reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+
+	if reqData.Kind == "" {
+		reqData.Kind = coverage.KindUnit
+	}
+	if err := validateCoverageKind(reqData.Kind); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	reqData.Commit = strings.ToLower(reqData.Commit)
+	if err := validateCommitPrefix(reqData.Commit); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	reqData.Base = strings.ToLower(reqData.Base)
+	if reqData.Base != "" {
+		if err := validateCommitPrefix(reqData.Base); err != nil {
+			return c.JSON(http.StatusBadRequest, err)
+		}
+	}
+
+	// The report of the base commit is only used without a diff.
+	approximate := reqData.Base != "" && !hasFormFile(c, "diff")
+
+	var changed map[string][]int
+	if !approximate {
+		rawDiff, err := readFormFile(c, "diff")
+		if err != nil {
+			return err
+		}
+
+		changed, err = diff.Parse(bytes.NewReader(rawDiff))
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to parse diff")
+			return echo.NewHTTPError(http.StatusBadRequest, "failed to parse diff")
+		}
+	}
+
+	commit, err := r.resolveCommit(ctx, data.ListCommitsByPrefixParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Kind:        reqData.Kind,
+		Prefix:      reqData.Commit,
+	})
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.repo.ListCoverageLineRanges(ctx, data.ListCoverageLineRangesParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Commit:      commit,
+		Kind:        reqData.Kind,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list coverage line ranges")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list coverage line ranges")
+	}
+
+	ranges := lineRangesByPath(rows)
+
+	if approximate {
+		baseCommit, err := r.resolveProjectCommit(ctx, data.ListProjectCommitsByPrefixParams{
+			RepoName:    reqData.RepoName,
+			ProjectName: reqData.ProjectName,
+			Kind:        reqData.Kind,
+			Prefix:      reqData.Base,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, baseFiles, err := r.commitFiles(ctx, data.GetProjectCommitCoverageParams{
+			RepoName:    reqData.RepoName,
+			ProjectName: reqData.ProjectName,
+			Commit:      baseCommit,
+			Kind:        reqData.Kind,
+		})
+		if err != nil {
+			return err
+		}
+
+		changed = coverage.ApproximateChangedLines(lo.MapValues(baseFiles, func(file coverage.FileSummary, _ string) []coverage.LineRange {
+			return file.Ranges
+		}), ranges)
+	}
+
+	patch := coverage.PatchCoverage(changed, ranges)
+
+	return c.JSON(http.StatusOK, patchToSchema(commit, patch, approximate))
+}
+
+type CompareCommitsRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	Base        string `query:"base"`
+	Head        string `query:"head"`
+	Kind        string `query:"kind"`
+}
+
+func (cr *CompareCommitsRequest) Validate() error {
+	validate := valgo.
+		Is(valgo.String(cr.Base, "base").
+			MatchingTo(commitPrefixPattern, "Base must be a SHA prefix of at least 7 characters"),
+		).
+		Is(valgo.String(cr.Head, "head").
+			MatchingTo(commitPrefixPattern, "Head must be a SHA prefix of at least 7 characters"),
+		).
+		Is(valgo.String(cr.Kind, "kind").
+			InSlice(coverageKinds, "Kind must be one of: "+strings.Join(coverageKinds, ", ")),
+		)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+type ComparedCommitSchema struct {
+	Commit     string  `json:"commit"`
+	BranchName string  `json:"branch_name"`
+	Coverage   float64 `json:"coverage"`
 }
 
-type CoverageSchema struct {
-	RepoName     string    `json:"repo_name"`
-	ProjectName  string    `json:"project_name"`
-	BranchName   string    `json:"branch_name"`
-	Commit       string    `json:"commit"`
-	Coverage     float64   `json:"coverage"`
-	CoverageDate time.Time `json:"coverage_date"`
+type FileComparisonSchema struct {
+	Path string `json:"path"`
+	// CoverageBefore is nil for added files, CoverageAfter for removed ones.
+	CoverageBefore      *float64 `json:"coverage_before"`
+	CoverageAfter       *float64 `json:"coverage_after"`
+	NewlyCoveredLines   []int    `json:"newly_covered_lines"`
+	NewlyUncoveredLines []int    `json:"newly_uncovered_lines"`
+}
+
+type ComparisonSchema struct {
+	Base         ComparedCommitSchema   `json:"base"`
+	Head         ComparedCommitSchema   `json:"head"`
+	Delta        float64                `json:"delta"`
+	AddedFiles   []FileComparisonSchema `json:"added_files"`
+	RemovedFiles []FileComparisonSchema `json:"removed_files"`
+	ChangedFiles []FileComparisonSchema `json:"changed_files"`
+}
+
+func fileComparisonsToSchema(files []coverage.FileComparison) []FileComparisonSchema {
+	return lo.Map(files, func(file coverage.FileComparison, _ int) FileComparisonSchema {
+		return FileComparisonSchema{
+			Path:                file.Path,
+			CoverageBefore:      file.Before,
+			CoverageAfter:       file.After,
+			NewlyCoveredLines:   file.NewlyCovered,
+			NewlyUncoveredLines: file.NewlyUncovered,
+		}
+	})
+}
+
+// commitFiles returns the coverage of a commit of a project and the summary of its files.
+func (r *Router) commitFiles(
+	ctx context.Context, params data.GetProjectCommitCoverageParams,
+) (data.Coverage, map[string]coverage.FileSummary, error) {
+	commitCoverage, err := r.repo.GetProjectCommitCoverage(ctx, params)
+	if err != nil {
+		return data.Coverage{}, nil, echo.NewHTTPError(http.StatusNotFound, "failed to get commit coverage")
+	}
+
+	files, err := r.repo.ListCoverageFiles(ctx, commitCoverage.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list coverage files")
+		return data.Coverage{}, nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to list coverage files")
+	}
+
+	rows, err := r.repo.ListCoverageLineRanges(ctx, data.ListCoverageLineRangesParams{
+		RepoName:    commitCoverage.RepoName,
+		ProjectName: commitCoverage.ProjectName,
+		BranchName:  commitCoverage.BranchName,
+		Commit:      commitCoverage.Commit,
+		Kind:        commitCoverage.Kind,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list coverage line ranges")
+		return data.Coverage{}, nil, echo.NewHTTPError(
+			http.StatusInternalServerError, "failed to list coverage line ranges",
+		)
+	}
+
+	ranges := lineRangesByPath(rows)
+	summaries := make(map[string]coverage.FileSummary, len(files))
+	for _, file := range files {
+		summaries[file.Path] = coverage.FileSummary{Coverage: file.Coverage, Ranges: ranges[file.Path]}
+	}
+
+	return commitCoverage, summaries, nil
+}
+
+// CompareCommits compares the coverage of two commits of a project, which may
+// have been uploaded on different branches.
+func (r *Router) CompareCommits(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData CompareCommitsRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	reqData.Base = strings.ToLower(reqData.Base)
+	reqData.Head = strings.ToLower(reqData.Head)
+	if reqData.Kind == "" {
+		reqData.Kind = coverage.KindUnit
+	}
+
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	commits := make([]data.Coverage, 0, 2)
+	summaries := make([]map[string]coverage.FileSummary, 0, 2)
+	for _, prefix := range []string{reqData.Base, reqData.Head} {
+		commit, err := r.resolveProjectCommit(ctx, data.ListProjectCommitsByPrefixParams{
+			RepoName:    reqData.RepoName,
+			ProjectName: reqData.ProjectName,
+			Kind:        reqData.Kind,
+			Prefix:      prefix,
+		})
+		if err != nil {
+			return err
+		}
+
+		commitCoverage, files, err := r.commitFiles(ctx, data.GetProjectCommitCoverageParams{
+			RepoName:    reqData.RepoName,
+			ProjectName: reqData.ProjectName,
+			Commit:      commit,
+			Kind:        reqData.Kind,
+		})
+		if err != nil {
+			return err
+		}
+
+		commits = append(commits, commitCoverage)
+		summaries = append(summaries, files)
+	}
+
+	base, head := commits[0], commits[1]
+	comparison := coverage.Compare(summaries[0], summaries[1])
+
+	return c.JSON(http.StatusOK, ComparisonSchema{
+		Base:         ComparedCommitSchema{Commit: base.Commit, BranchName: base.BranchName, Coverage: base.Coverage},
+		Head:         ComparedCommitSchema{Commit: head.Commit, BranchName: head.BranchName, Coverage: head.Coverage},
+		Delta:        head.Coverage - base.Coverage,
+		AddedFiles:   fileComparisonsToSchema(comparison.Added),
+		RemovedFiles: fileComparisonsToSchema(comparison.Removed),
+		ChangedFiles: fileComparisonsToSchema(comparison.Changed),
+	})
+}
+
+type GatePolicyRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+}
+
+type PutGatePolicyRequest struct {
+	RepoName         string          `param:"repoName" json:"-"`
+	ProjectName      string          `param:"projectName" json:"-"`
+	MinCoverage      *float64        `json:"min_coverage"`
+	MaxDrop          *float64        `json:"max_drop"`
+	MinPatchCoverage *float64        `json:"min_patch_coverage"`
+	Paths            []gate.PathRule `json:"paths"`
+}
+
+func (pr *PutGatePolicyRequest) Validate() error {
+	validate := valgo.
+		Is(valgo.Float64P(pr.MinCoverage, "min_coverage").
+			Nil().Or().Between(0, 100, "Min coverage must be >=0 and <=100"),
+		).
+		Is(valgo.Float64P(pr.MaxDrop, "max_drop").
+			Nil().Or().Between(0, 100, "Max drop must be >=0 and <=100"),
+		).
+		Is(valgo.Float64P(pr.MinPatchCoverage, "min_patch_coverage").
+			Nil().Or().Between(0, 100, "Min patch coverage must be >=0 and <=100"),
+		).
+		Is(valgo.Int(len(pr.Paths), "paths").
+			LessOrEqualTo(100, "Paths must hold at most 100 rules"),
+		)
+
+	for i, rule := range pr.Paths {
+		field := fmt.Sprintf("paths[%d]", i)
+		validate.
+			Is(valgo.String(rule.Pattern, field+".pattern").
+				Passing(func(pattern string) bool { return glob.Validate(pattern) == nil }, "Pattern must be a valid glob"),
+			).
+			Is(valgo.Float64(rule.MinCoverage, field+".min_coverage").
+				Between(0, 100, "Min coverage must be >=0 and <=100"),
+			)
+	}
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+type GatePolicySchema struct {
+	RepoName         string          `json:"repo_name"`
+	ProjectName      string          `json:"project_name"`
+	MinCoverage      *float64        `json:"min_coverage"`
+	MaxDrop          *float64        `json:"max_drop"`
+	MinPatchCoverage *float64        `json:"min_patch_coverage"`
+	Paths            []gate.PathRule `json:"paths"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+func float8ToPtr(value pgtype.Float8) *float64 {
+	if !value.Valid {
+		return nil
+	}
+
+	return lo.ToPtr(value.Float64)
+}
+
+func ptrToFloat8(value *float64) pgtype.Float8 {
+	return pgtype.Float8{Float64: lo.FromPtr(value), Valid: value != nil}
+}
+
+func gatePolicyModelToPolicy(model data.GatePolicy) (gate.Policy, error) {
+	policy := gate.Policy{
+		MinCoverage:      float8ToPtr(model.MinCoverage),
+		MaxDrop:          float8ToPtr(model.MaxDrop),
+		MinPatchCoverage: float8ToPtr(model.MinPatchCoverage),
+		Paths:            []gate.PathRule{},
+	}
+
+	if err := json.Unmarshal(model.PathRules, &policy.Paths); err != nil {
+		return gate.Policy{}, fmt.Errorf("failed to decode path rules: %w", err)
+	}
+
+	return policy, nil
+}
+
+func writeGatePolicy(c echo.Context, status int, model data.GatePolicy) error {
+	policy, err := gatePolicyModelToPolicy(model)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to decode gate policy")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to decode gate policy")
+	}
+
+	return c.JSON(status, GatePolicySchema{
+		RepoName:         model.RepoName,
+		ProjectName:      model.ProjectName,
+		MinCoverage:      policy.MinCoverage,
+		MaxDrop:          policy.MaxDrop,
+		MinPatchCoverage: policy.MinPatchCoverage,
+		Paths:            policy.Paths,
+		UpdatedAt:        model.UpdatedAt.Time,
+	})
+}
+
+// getGatePolicy returns the stored gate policy of a project, failing with 404 without one.
+func (r *Router) getGatePolicy(ctx context.Context, params data.GetGatePolicyParams) (data.GatePolicy, error) {
+	policy, err := r.repo.GetGatePolicy(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return data.GatePolicy{}, echo.NewHTTPError(http.StatusNotFound, "no gate policy for project")
+		}
+		log.Error().Err(err).Msg("Failed to get gate policy")
+		return data.GatePolicy{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to get gate policy")
+	}
+
+	return policy, nil
+}
+
+func (r *Router) GetGatePolicy(c echo.Context) error {
+	var reqData GatePolicyRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	policy, err := r.getGatePolicy(c.Request().Context(), data.GetGatePolicyParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeGatePolicy(c, http.StatusOK, policy)
+}
+
+// PutGatePolicy replaces the gate policy of a project, thresholds left out
+// of the body aren't checked.
+func (r *Router) PutGatePolicy(c echo.Context) error {
+	var reqData PutGatePolicyRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	pathRules, err := json.Marshal(lo.Ternary(reqData.Paths == nil, []gate.PathRule{}, reqData.Paths))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode path rules")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to encode path rules")
+	}
+
+	policy, err := r.repo.UpsertGatePolicy(c.Request().Context(), data.UpsertGatePolicyParams{
+		RepoName:         reqData.RepoName,
+		ProjectName:      reqData.ProjectName,
+		MinCoverage:      ptrToFloat8(reqData.MinCoverage),
+		MaxDrop:          ptrToFloat8(reqData.MaxDrop),
+		MinPatchCoverage: ptrToFloat8(reqData.MinPatchCoverage),
+		PathRules:        pathRules,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to upsert gate policy")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to upsert gate policy")
+	}
+
+	return writeGatePolicy(c, http.StatusOK, policy)
+}
+
+func (r *Router) DeleteGatePolicy(c echo.Context) error {
+	var reqData GatePolicyRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	deleted, err := r.repo.DeleteGatePolicy(c.Request().Context(), data.DeleteGatePolicyParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete gate policy")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to delete gate policy")
+	}
+
+	if deleted == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "no gate policy for project")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+type BadgeSettingsRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+}
+
+type PutBadgeSettingsRequest struct {
+	RepoName    string `param:"repoName" json:"-"`
+	ProjectName string `param:"projectName" json:"-"`
+	ColorScale  string `json:"color_scale"`
+}
+
+func (pr *PutBadgeSettingsRequest) Validate() error {
+	validate := valgo.Is(valgo.String(pr.ColorScale, "color_scale").
+		Passing(func(scale string) bool {
+			_, err := badge.ParseScale(scale)
+			return err == nil
+		}, "Color scale must be colors and increasing bounds, such as red:60,yellow:80,green"),
+	)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+type BadgeSettingsSchema struct {
+	RepoName    string    `json:"repo_name"`
+	ProjectName string    `json:"project_name"`
+	ColorScale  string    `json:"color_scale"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func badgeSettingsModelToSchema(model data.BadgeSetting) BadgeSettingsSchema {
+	return BadgeSettingsSchema{
+		RepoName:    model.RepoName,
+		ProjectName: model.ProjectName,
+		ColorScale:  model.ColorScale,
+		UpdatedAt:   model.UpdatedAt.Time,
+	}
+}
+
+func (r *Router) GetBadgeSettings(c echo.Context) error {
+	var reqData BadgeSettingsRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	settings, err := r.repo.GetBadgeSettings(c.Request().Context(), data.GetBadgeSettingsParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "no badge settings for project")
+		}
+		log.Error().Err(err).Msg("Failed to get badge settings")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get badge settings")
+	}
+
+	return c.JSON(http.StatusOK, badgeSettingsModelToSchema(settings))
+}
+
+// PutBadgeSettings replaces the color scale the public badges of a project
+// use, unless a request overrides it.
+func (r *Router) PutBadgeSettings(c echo.Context) error {
+	var reqData PutBadgeSettingsRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	// The scale is stored normalized, it can't fail to parse once validated.
+	scale, _ := badge.ParseScale(reqData.ColorScale)
+	settings, err := r.repo.UpsertBadgeSettings(c.Request().Context(), data.UpsertBadgeSettingsParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		ColorScale:  scale.String(),
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to upsert badge settings")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to upsert badge settings")
+	}
+
+	return c.JSON(http.StatusOK, badgeSettingsModelToSchema(settings))
+}
+
+func (r *Router) DeleteBadgeSettings(c echo.Context) error {
+	var reqData BadgeSettingsRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	deleted, err := r.repo.DeleteBadgeSettings(c.Request().Context(), data.DeleteBadgeSettingsParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to delete badge settings")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to delete badge settings")
+	}
+
+	if deleted == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "no badge settings for project")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+type PostGateRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	Commit      string `param:"commit"`
+	// Base is the commit the max drop rule compares with, from any branch of the project.
+	Base string `query:"base"`
+	Kind string `query:"kind"`
+}
+
+func (pr *PostGateRequest) Validate() error {
+	validate := valgo.
+		Is(valgo.String(pr.Commit, "commit").
+			MatchingTo(commitPrefixPattern, "Commit must be a SHA prefix of at least 7 characters"),
+		).
+		Is(valgo.String(pr.Base, "base").
+			Empty().Or().MatchingTo(commitPrefixPattern, "Base must be a SHA prefix of at least 7 characters"),
+		).
+		Is(valgo.String(pr.Kind, "kind").
+			InSlice(coverageKinds, "Kind must be one of: "+strings.Join(coverageKinds, ", ")),
+		)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+type GateSchema struct {
+	Commit string `json:"commit"`
+	// BaseCommit is nil when no base is given.
+	BaseCommit *string       `json:"base_commit"`
+	Passed     bool          `json:"passed"`
+	Reasons    []gate.Reason `json:"reasons"`
+}
+
+// PostGate evaluates the gate policy of the project against the report of a
+// commit. The max drop rule needs the base query param, and the min patch
+// coverage rule the unified diff uploaded as the optional diff file.
+func (r *Router) PostGate(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData PostGateRequest
+	if err := bindWithQuery(c, &reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+	reqData.Commit = strings.ToLower(reqData.Commit)
+	reqData.Base = strings.ToLower(reqData.Base)
+	if reqData.Kind == "" {
+		reqData.Kind = coverage.KindUnit
+	}
+
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	var changed map[string][]int
+	if hasFormFile(c, "diff") {
+		rawDiff, err := readFormFile(c, "diff")
+		if err != nil {
+			return err
+		}
+
+		changed, err = diff.Parse(bytes.NewReader(rawDiff))
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to parse diff")
+			return echo.NewHTTPError(http.StatusBadRequest, "failed to parse diff")
+		}
+	}
+
+	commit, err := r.resolveCommit(ctx, data.ListCommitsByPrefixParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Kind:        reqData.Kind,
+		Prefix:      reqData.Commit,
+	})
+	if err != nil {
+		return err
+	}
+
+	commitCoverage, err := r.repo.GetCommitCoverage(ctx, data.GetCommitCoverageParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+		Commit:      commit,
+		Kind:        reqData.Kind,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "failed to get commit coverage")
+	}
+
+	policy, err := r.gatePolicy(ctx, commitCoverage)
+	if err != nil {
+		return err
+	}
+
+	input, err := r.gateInput(ctx, commitCoverage, changed)
+	if err != nil {
+		return err
+	}
+
+	var baseCommit *string
+	if reqData.Base != "" {
+		base, err := r.resolveProjectCommit(ctx, data.ListProjectCommitsByPrefixParams{
+			RepoName:    reqData.RepoName,
+			ProjectName: reqData.ProjectName,
+			Kind:        reqData.Kind,
+			Prefix:      reqData.Base,
+		})
+		if err != nil {
+			return err
+		}
+
+		baseCoverage, err := r.repo.GetProjectCommitCoverage(ctx, data.GetProjectCommitCoverageParams{
+			RepoName:    reqData.RepoName,
+			ProjectName: reqData.ProjectName,
+			Commit:      base,
+			Kind:        reqData.Kind,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, "failed to get base commit coverage")
+		}
+
+		baseCommit = &base
+		input.BaseCoverage = &baseCoverage.Coverage
+	}
+
+	result := gate.Evaluate(policy, input)
+
+	return c.JSON(http.StatusOK, GateSchema{
+		Commit:     commit,
+		BaseCommit: baseCommit,
+		Passed:     result.Passed,
+		Reasons:    result.Reasons,
+	})
 }
 
-func coverageModelToSchema(coverage data.Coverage) CoverageSchema {
-	return CoverageSchema{
-		RepoName:     coverage.RepoName,
-		ProjectName:  coverage.ProjectName,
-		BranchName:   coverage.BranchName,
-		Commit:       coverage.Commit,
-		Coverage:     coverage.Coverage,
-		CoverageDate: coverage.CoverageDate.Time,
+// gatePolicy returns the gate policy stored for the project of commitCoverage,
+// or else the one of the config uploaded with it, failing with 404 without either.
+func (r *Router) gatePolicy(ctx context.Context, commitCoverage data.Coverage) (gate.Policy, error) {
+	model, err := r.repo.GetGatePolicy(ctx, data.GetGatePolicyParams{
+		RepoName:    commitCoverage.RepoName,
+		ProjectName: commitCoverage.ProjectName,
+	})
+	if err == nil {
+		policy, err := gatePolicyModelToPolicy(model)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to decode gate policy")
+			return gate.Policy{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to decode gate policy")
+		}
+
+		return policy, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		log.Error().Err(err).Msg("Failed to get gate policy")
+		return gate.Policy{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to get gate policy")
 	}
-}
 
-func coverageSummaryModelToSchema(coverage data.ListCoverageSummaryRow) CoverageSchema {
-	return CoverageSchema{
-		RepoName:     coverage.RepoName,
-		ProjectName:  coverage.ProjectName,
-		BranchName:   coverage.BranchName,
-		Commit:       coverage.Commit,
-		Coverage:     coverage.Coverage,
-		CoverageDate: coverage.CoverageDate.Time,
+	content, err := r.repo.GetCoverageConfig(ctx, commitCoverage.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return gate.Policy{}, echo.NewHTTPError(http.StatusNotFound, "no gate policy for project")
+		}
+		log.Error().Err(err).Msg("Failed to get coverage config")
+		return gate.Policy{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to get coverage config")
+	}
+
+	config, err := repoconfig.Parse([]byte(content))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse stored coverage config")
+		return gate.Policy{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to parse coverage config")
 	}
+
+	return config.Gate, nil
 }
 
-func NewAPIV1Router(e *echo.Echo, repo repository) *Router {
-	return &Router{e: e, repo: repo}
+// gateInput gathers the coverage of the commit of commitCoverage, and the
+// patch coverage of changed when given.
+func (r *Router) gateInput(
+	ctx context.Context, commitCoverage data.Coverage, changed map[string][]int,
+) (gate.Input, error) {
+	files, err := r.repo.ListCoverageFiles(ctx, commitCoverage.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list coverage files")
+		return gate.Input{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to list coverage files")
+	}
+
+	input := gate.Input{
+		Coverage: commitCoverage.Coverage,
+		Files: lo.Map(files, func(file data.CoverageFile, _ int) gate.File {
+			return gate.File{
+				Path:       file.Path,
+				Statements: coverage.Counter{Covered: int(file.StatementsCovered), Total: int(file.StatementsTotal)},
+				Lines:      coverage.Counter{Covered: int(file.LinesCovered), Total: int(file.LinesTotal)},
+			}
+		}),
+	}
+
+	if changed == nil {
+		return input, nil
+	}
+
+	rows, err := r.repo.ListCoverageLineRanges(ctx, data.ListCoverageLineRangesParams{
+		RepoName:    commitCoverage.RepoName,
+		ProjectName: commitCoverage.ProjectName,
+		BranchName:  commitCoverage.BranchName,
+		Commit:      commitCoverage.Commit,
+		Kind:        commitCoverage.Kind,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list coverage line ranges")
+		return gate.Input{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to list coverage line ranges")
+	}
+
+	patch := coverage.PatchCoverage(changed, lineRangesByPath(rows))
+	input.Patch = &patch
+
+	return input, nil
 }
 
-type PostCoverageRequest struct {
+type ExclusionRulesRequest struct {
 	RepoName    string `param:"repoName"`
 	ProjectName string `param:"projectName"`
-	BranchName  string `param:"branchName"`
-	Commit      string `param:"commit"`
 }
 
-func (pr *PostCoverageRequest) Validate() error {
-	validate := valgo.
-		Is(valgo.
-			String(pr.Commit, "commit").
-			MinLength(8, "Commit must be at least 8 characters long"),
-		)
+type ExclusionRuleSchema struct {
+	ID        int32     `json:"id"`
+	Pattern   string    `json:"pattern"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func exclusionRuleModelToSchema(rule data.ExclusionRule) ExclusionRuleSchema {
+	return ExclusionRuleSchema{ID: rule.ID, Pattern: rule.Pattern, CreatedAt: rule.CreatedAt.Time}
+}
+
+func (r *Router) ListExclusionRules(c echo.Context) error {
+	var reqData ExclusionRulesRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	rules, err := r.repo.ListExclusionRules(c.Request().Context(), data.ListExclusionRulesParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list exclusion rules")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to list exclusion rules")
+	}
+
+	return c.JSON(http.StatusOK, lo.Map(rules, func(rule data.ExclusionRule, _ int) ExclusionRuleSchema {
+		return exclusionRuleModelToSchema(rule)
+	}))
+}
+
+type PostExclusionRuleRequest struct {
+	RepoName    string `param:"repoName" json:"-"`
+	ProjectName string `param:"projectName" json:"-"`
+	Pattern     string `json:"pattern"`
+	// Recompute recomputes the history of the project with the new rule.
+	Recompute bool `query:"recompute" json:"-"`
+}
+
+func (pr *PostExclusionRuleRequest) Validate() error {
+	validate := valgo.Is(valgo.String(pr.Pattern, "pattern").
+		MaxLength(1024, "Pattern must be at most 1024 characters long").
+		Passing(func(pattern string) bool { return glob.Validate(pattern) == nil }, "Pattern must be a valid glob"),
+	)
 
 	if !validate.Valid() {
 		return validate.Error()
@@ -104,150 +2144,247 @@ func (pr *PostCoverageRequest) Validate() error {
 	return nil
 }
 
-func (r *Router) PostCoverage(c echo.Context) error {
+// PostExclusionRule adds a pattern of files left out of the reports of a
+// project, from the next upload on or for the whole history with recompute.
+func (r *Router) PostExclusionRule(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	var reqData PostCoverageRequest
-	if err := c.Bind(&reqData); err != nil {
+	var reqData PostExclusionRuleRequest
+	if err := bindWithQuery(c, &reqData); err != nil {
 		return err
 	}
 
-	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
-	}
-	reqData.BranchName = decodedBranchName
-
 	if err := reqData.Validate(); err != nil {
 		return c.JSON(http.StatusBadRequest, err)
 	}
 
-	coverageFile, err := c.FormFile("coverage")
+	rule, err := r.repo.InsertExclusionRule(ctx, data.InsertExclusionRuleParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		Pattern:     reqData.Pattern,
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "coverage file is required")
+		log.Error().Err(err).Msg("Failed to insert exclusion rule")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to insert exclusion rule")
 	}
 
-	src, err := coverageFile.Open()
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to open coverage file")
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to open coverage file")
+	if reqData.Recompute {
+		if _, err := r.recomputeCoverage(ctx, reqData.RepoName, reqData.ProjectName); err != nil {
+			return err
+		}
 	}
-	defer src.Close()
 
-	decoder := json.NewDecoder(src)
-	var coverage PythonCoverageJSONFile
+	return c.JSON(http.StatusCreated, exclusionRuleModelToSchema(rule))
+}
+
+type DeleteExclusionRuleRequest struct {
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	RuleID      int32  `param:"ruleID"`
+	// Recompute recomputes the history of the project without the rule.
+	Recompute bool `query:"recompute"`
+}
+
+func (r *Router) DeleteExclusionRule(c echo.Context) error {
+	ctx := c.Request().Context()
 
-	if err := decoder.Decode(&coverage); err != nil {
-		// TODO: Implement a file format detection strategy to handle different coverage file formats
-		return echo.NewHTTPError(http.StatusBadRequest, "failed to decode coverage file")
+	var reqData DeleteExclusionRuleRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
 	}
 
-	parsedTime, err := time.Parse("2006-01-02T15:04:05", coverage.Meta.Timestamp)
+	deleted, err := r.repo.DeleteExclusionRule(ctx, data.DeleteExclusionRuleParams{
+		ID:          reqData.RuleID,
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "failed to parse coverage timestamp")
+		log.Error().Err(err).Msg("Failed to delete exclusion rule")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to delete exclusion rule")
 	}
 
-	_, err = src.Seek(0, 0)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to seek coverage file")
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to seek coverage file")
+	if deleted == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "exclusion rule not found")
 	}
 
-	rawFileData, err := io.ReadAll(src)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to read coverage file")
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to read coverage file")
+	if reqData.Recompute {
+		if _, err := r.recomputeCoverage(ctx, reqData.RepoName, reqData.ProjectName); err != nil {
+			return err
+		}
 	}
 
-	_, err = r.repo.UpsertCoverage(ctx, data.UpsertCoverageParams{
-		RepoName:    reqData.RepoName,
-		ProjectName: reqData.ProjectName,
-		BranchName:  reqData.BranchName,
-		Commit:      reqData.Commit[:8],
-		Coverage:    coverage.Totals.PercentCovered,
-		CoverageDate: pgtype.Timestamptz{
-			Time:  parsedTime,
-			Valid: true,
-		},
-		RawData: rawFileData,
-	})
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RecomputeFailureSchema is a report whose raw data can't be parsed anymore,
+// which recompute leaves as stored.
+type RecomputeFailureSchema struct {
+	CoverageID int32  `json:"coverage_id"`
+	BranchName string `json:"branch_name"`
+	Commit     string `json:"commit"`
+	Kind       string `json:"kind"`
+	Error      string `json:"error"`
+}
+
+type RecomputeSchema struct {
+	Recomputed int                      `json:"recomputed"`
+	Failed     []RecomputeFailureSchema `json:"failed"`
+}
+
+// RecomputeCoverage recomputes the history of a project with its current exclusion rules.
+func (r *Router) RecomputeCoverage(c echo.Context) error {
+	var reqData ExclusionRulesRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	result, err := r.recomputeCoverage(c.Request().Context(), reqData.RepoName, reqData.ProjectName)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to upsert coverage")
-		return c.String(http.StatusInternalServerError, "failed to upsert coverage")
+		return err
 	}
 
-	return c.NoContent(http.StatusCreated)
+	return c.JSON(http.StatusOK, result)
 }
 
-type GetLatestBranchCoverageRequest struct {
-	RepoName    string `param:"repoName"`
-	ProjectName string `param:"projectName"`
-	BranchName  string `param:"branchName"`
+type RecomputeStatusSchema struct {
+	// Stale counts the reports not stored with the current exclusion rules,
+	// left by a recompute that failed or wasn't requested.
+	Stale int64 `json:"stale"`
 }
 
-func (r *Router) GetLatestBranchCoverage(c echo.Context) error {
+// GetRecomputeStatus counts the reports of a project a recompute would update.
+func (r *Router) GetRecomputeStatus(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	var reqData GetLatestBranchCoverageRequest
+	var reqData ExclusionRulesRequest
 	if err := c.Bind(&reqData); err != nil {
 		return err
 	}
 
-	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	patterns, err := r.exclusionPatterns(ctx, reqData.RepoName, reqData.ProjectName)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+		return err
 	}
-	reqData.BranchName = decodedBranchName
 
-	coverage, err := r.repo.GetRecentCoverage(ctx, data.GetRecentCoverageParams{
-		RepoName:    reqData.RepoName,
-		ProjectName: reqData.ProjectName,
-		BranchName:  reqData.BranchName,
+	stale, err := r.repo.CountStaleProjectCoverage(ctx, data.CountStaleProjectCoverageParams{
+		RepoName:     reqData.RepoName,
+		ProjectName:  reqData.ProjectName,
+		RulesVersion: rulesVersion(patterns),
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, "failed to get recent coverage")
+		log.Error().Err(err).Msg("Failed to count stale project coverage")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to count stale project coverage")
 	}
 
-	return c.JSON(http.StatusOK, coverageModelToSchema(coverage))
+	return c.JSON(http.StatusOK, RecomputeStatusSchema{Stale: stale})
 }
 
-type GetCoverageDataRequest struct {
-	RepoName    string `param:"repoName"`
-	ProjectName string `param:"projectName"`
-	BranchName  string `param:"branchName"`
-	Commit      string `param:"commit"`
+// recomputeBatchSize is the number of reports loaded at once by recomputeCoverage.
+const recomputeBatchSize = 50
+
+// recomputeCoverage recomputes the totals, files and line ranges of every
+// report of a project from its raw data, with the current exclusion rules and
+// the config uploaded with the report. Reports already stored with the current
+// rules are skipped, so a recompute failing midway resumes where it stopped
+// when retried.
+func (r *Router) recomputeCoverage(ctx context.Context, repoName, projectName string) (RecomputeSchema, error) {
+	result := RecomputeSchema{Failed: []RecomputeFailureSchema{}}
+
+	patterns, err := r.exclusionPatterns(ctx, repoName, projectName)
+	if err != nil {
+		return result, err
+	}
+
+	var afterID int32
+	for {
+		rows, err := r.repo.ListStaleProjectCoverage(ctx, data.ListStaleProjectCoverageParams{
+			RepoName:     repoName,
+			ProjectName:  projectName,
+			Limit:        recomputeBatchSize,
+			AfterID:      afterID,
+			RulesVersion: rulesVersion(patterns),
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to list project coverage")
+			return result, echo.NewHTTPError(http.StatusInternalServerError, "failed to list project coverage")
+		}
+
+		for _, row := range rows {
+			afterID = row.ID
+
+			report, err := coverage.ParseRawData(row.RawData, coverage.Format(row.Format))
+			if err != nil {
+				log.Warn().Err(err).Int32("coverage_id", row.ID).Msg("Failed to parse stored coverage report")
+				result.Failed = append(result.Failed, RecomputeFailureSchema{
+					CoverageID: row.ID,
+					BranchName: row.BranchName,
+					Commit:     row.Commit,
+					Kind:       row.Kind,
+					Error:      err.Error(),
+				})
+				continue
+			}
+
+			if err := r.recomputeReport(ctx, row, report, patterns); err != nil {
+				return result, err
+			}
+			result.Recomputed++
+		}
+
+		if len(rows) < recomputeBatchSize {
+			return result, nil
+		}
+	}
 }
 
-func (r *Router) GetCoverageData(c echo.Context) error {
-	ctx := c.Request().Context()
+// recomputeReport stores the report parsed from the raw data of row anew.
+func (r *Router) recomputeReport(ctx context.Context, row data.Coverage, report *coverage.Report, patterns []string) error {
+	var config *repoconfig.Config
+	content, err := r.repo.GetCoverageConfig(ctx, row.ID)
+	switch {
+	case err == nil:
+		if config, err = repoconfig.Parse([]byte(content)); err != nil {
+			log.Error().Err(err).Int32("coverage_id", row.ID).Msg("Failed to parse stored coverage config")
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to parse coverage config")
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		log.Error().Err(err).Msg("Failed to get coverage config")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get coverage config")
+	}
 
-	var reqData GetCoverageDataRequest
-	if err := c.Bind(&reqData); err != nil {
-		return err
+	params := data.UpsertCoverageParams{
+		RepoName:     row.RepoName,
+		ProjectName:  row.ProjectName,
+		BranchName:   row.BranchName,
+		Commit:       row.Commit,
+		CoverageDate: row.CoverageDate,
+		RawData:      row.RawData,
+		Format:       row.Format,
+		Kind:         row.Kind,
 	}
+	report = excludeFiles(&params, report, config, patterns)
 
-	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	if _, err := r.repo.UpsertCoverage(ctx, params, report); err != nil {
+		log.Error().Err(err).Msg("Failed to upsert coverage")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to upsert coverage")
 	}
-	reqData.BranchName = decodedBranchName
 
-	coverage, err := r.repo.GetCoverageData(ctx, data.GetCoverageDataParams{
-		RepoName:    reqData.RepoName,
-		ProjectName: reqData.ProjectName,
-		BranchName:  reqData.BranchName,
-		Commit:      reqData.Commit,
-	})
+	return nil
+}
+
+// ValidateConfig lints the .goverage.yml file sent as the request body.
+func (r *Router) ValidateConfig(c echo.Context) error {
+	content, err := io.ReadAll(io.LimitReader(c.Request().Body, repoconfig.MaxSize+1))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, "failed to get coverage data")
+		log.Error().Err(err).Msg("Failed to read config")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to read config")
 	}
 
-	var jsonData map[string]interface{}
-	if err := json.Unmarshal(coverage, &jsonData); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unmarshal coverage data")
+	if _, err := parseConfig(content); err != nil {
+		return err
 	}
 
-	return c.JSON(http.StatusOK, jsonData)
+	return c.JSON(http.StatusOK, ConfigValidationSchema{Valid: true, Errors: []repoconfig.Error{}})
 }
 
 type ListCoverageHistoryRequest struct {
@@ -257,6 +2394,7 @@ type ListCoverageHistoryRequest struct {
 	Order       *string `query:"order"`
 	Limit       *int32  `query:"limit"`
 	Page        *int32  `query:"page"`
+	Kind        *string `query:"kind"`
 }
 
 func (lr *ListCoverageHistoryRequest) SetDefaults() {
@@ -271,6 +2409,10 @@ func (lr *ListCoverageHistoryRequest) SetDefaults() {
 	if lr.Limit == nil {
 		lr.Limit = lo.ToPtr(int32(50))
 	}
+
+	if lr.Kind == nil {
+		lr.Kind = lo.ToPtr(coverage.KindUnit)
+	}
 }
 
 func (lr *ListCoverageHistoryRequest) Validate() error {
@@ -283,6 +2425,9 @@ func (lr *ListCoverageHistoryRequest) Validate() error {
 		).
 		Is(valgo.Int32(*lr.Page, "page").
 			GreaterOrEqualTo(1, "Page must be >=1"),
+		).
+		Is(valgo.String(*lr.Kind, "kind").
+			InSlice(coverageKinds, "Kind must be one of: "+strings.Join(coverageKinds, ", ")),
 		)
 
 	if !validate.Valid() {
@@ -319,6 +2464,7 @@ func (r *Router) ListCoverageHistory(c echo.Context) error {
 		BranchName:     reqData.BranchName,
 		Limit:          *reqData.Limit,
 		Offset:         offset,
+		Kind:           *reqData.Kind,
 		OrderDirection: *reqData.Order,
 	})
 	if err != nil {
@@ -327,13 +2473,135 @@ func (r *Router) ListCoverageHistory(c echo.Context) error {
 	}
 
 	coveragesSchemas := make([]CoverageSchema, 0, len(coverages))
-	for _, coverage := range coverages {
-		coveragesSchemas = append(coveragesSchemas, coverageSummaryModelToSchema(coverage))
+	for _, summary := range coverages {
+		coveragesSchemas = append(coveragesSchemas, coverageSummaryModelToSchema(summary))
 	}
 
 	return c.JSON(http.StatusOK, coveragesSchemas)
 }
 
+type ListFileHistoryRequest struct {
+	ListCoverageHistoryRequest
+	// Path is the current path of the file, its previous paths are followed
+	// through the renames uploaded with the reports.
+	Path string `query:"path"`
+}
+
+func (lr *ListFileHistoryRequest) Validate() error {
+	if err := lr.ListCoverageHistoryRequest.Validate(); err != nil {
+		return err
+	}
+
+	validate := valgo.Is(valgo.String(lr.Path, "path").
+		Not().Blank("Path is required").
+		MaxLength(4096, "Path must be at most 4096 characters long"),
+	)
+
+	if !validate.Valid() {
+		return validate.Error()
+	}
+
+	return nil
+}
+
+type FileHistorySchema struct {
+	Commit       string    `json:"commit"`
+	CoverageDate time.Time `json:"coverage_date"`
+	// Path is the path of the file at the commit, a previous one when the file was renamed since.
+	Path            string  `json:"path"`
+	Coverage        float64 `json:"coverage"`
+	LinesCovered    int32   `json:"lines_covered"`
+	LinesTotal      int32   `json:"lines_total"`
+	BranchesCovered int32   `json:"branches_covered"`
+	BranchesTotal   int32   `json:"branches_total"`
+	// BranchCoverage is nil for files without branch data.
+	BranchCoverage *float64 `json:"branch_coverage"`
+}
+
+// maxFilePaths bounds the previous paths a file history follows.
+const maxFilePaths = 100
+
+// filePaths returns path followed by the paths it was renamed from, directly or
+// not, from the newest to the oldest.
+func filePaths(path string, renames []data.ListFileRenamesRow) []string {
+	paths := []string{path}
+	seen := map[string]bool{path: true}
+	for i := 0; i < len(paths) && len(paths) < maxFilePaths; i++ {
+		for _, rename := range renames {
+			if rename.NewPath == paths[i] && !seen[rename.OldPath] {
+				seen[rename.OldPath] = true
+				paths = append(paths, rename.OldPath)
+			}
+		}
+	}
+
+	return paths
+}
+
+// ListFileHistory returns the coverage of a file across the commits of a
+// branch, ordered and paginated as ListCoverageHistory. A commit holding the
+// file under several of its paths is listed once, with the newest path.
+func (r *Router) ListFileHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData ListFileHistoryRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	decodedBranchName, err := url.QueryUnescape(reqData.BranchName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unescape branch name")
+	}
+	reqData.BranchName = decodedBranchName
+
+	reqData.SetDefaults()
+	if err := reqData.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, err)
+	}
+
+	renames, err := r.repo.ListFileRenames(ctx, data.ListFileRenamesParams{
+		RepoName:    reqData.RepoName,
+		ProjectName: reqData.ProjectName,
+		BranchName:  reqData.BranchName,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list file renames")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to list file renames")
+	}
+
+	offset := (*reqData.Page - 1) * *reqData.Limit
+
+	rows, err := r.repo.ListFileCoverageHistory(ctx, data.ListFileCoverageHistoryParams{
+		RepoName:       reqData.RepoName,
+		ProjectName:    reqData.ProjectName,
+		BranchName:     reqData.BranchName,
+		Offset:         offset,
+		Limit:          *reqData.Limit,
+		Kind:           *reqData.Kind,
+		Paths:          filePaths(reqData.Path, renames),
+		OrderDirection: *reqData.Order,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get file coverage history")
+		return httperrors.WriteResponse(c, http.StatusInternalServerError, "failed to get file coverage history")
+	}
+
+	return c.JSON(http.StatusOK, lo.Map(rows, func(row data.ListFileCoverageHistoryRow, _ int) FileHistorySchema {
+		return FileHistorySchema{
+			Commit:          row.Commit,
+			CoverageDate:    row.CoverageDate.Time,
+			Path:            row.Path,
+			Coverage:        row.Coverage,
+			LinesCovered:    row.LinesCovered,
+			LinesTotal:      row.LinesTotal,
+			BranchesCovered: row.BranchesCovered,
+			BranchesTotal:   row.BranchesTotal,
+			BranchCoverage:  branchCoverage(row.BranchesCovered, row.BranchesTotal),
+		}
+	}))
+}
+
 func (r *Router) ListRepositories(c echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -410,25 +2678,92 @@ func (r *Router) Register() {
 		},
 	}))
 
+	apiGroup.POST(
+		"/config/validate", r.ValidateConfig,
+	)
 	apiGroup.GET(
 		"/repos", r.ListRepositories,
 	)
 	apiGroup.GET(
 		"/repos/:repoName/projects", r.ListProjects,
 	)
+	apiGroup.GET(
+		"/repos/:repoName/branches/:branchName/coverage", r.GetRepoCoverage,
+	)
 	apiGroup.GET(
 		"/repos/:repoName/projects/:projectName/branches", r.ListBranches,
 	)
+	apiGroup.GET(
+		"/repos/:repoName/projects/:projectName/compare", r.CompareCommits,
+	)
+	apiGroup.GET(
+		"/repos/:repoName/projects/:projectName/gate_policy", r.GetGatePolicy,
+	)
+	apiGroup.PUT(
+		"/repos/:repoName/projects/:projectName/gate_policy", r.PutGatePolicy,
+	)
+	apiGroup.DELETE(
+		"/repos/:repoName/projects/:projectName/gate_policy", r.DeleteGatePolicy,
+	)
+	apiGroup.GET(
+		"/repos/:repoName/projects/:projectName/badge_settings", r.GetBadgeSettings,
+	)
+	apiGroup.PUT(
+		"/repos/:repoName/projects/:projectName/badge_settings", r.PutBadgeSettings,
+	)
+	apiGroup.DELETE(
+		"/repos/:repoName/projects/:projectName/badge_settings", r.DeleteBadgeSettings,
+	)
+	apiGroup.GET(
+		"/repos/:repoName/projects/:projectName/exclusion_rules", r.ListExclusionRules,
+	)
+	apiGroup.POST(
+		"/repos/:repoName/projects/:projectName/exclusion_rules", r.PostExclusionRule,
+	)
+	apiGroup.GET(
+		"/repos/:repoName/projects/:projectName/exclusion_rules/recompute", r.GetRecomputeStatus,
+	)
+	apiGroup.POST(
+		"/repos/:repoName/projects/:projectName/exclusion_rules/recompute", r.RecomputeCoverage,
+	)
+	apiGroup.DELETE(
+		"/repos/:repoName/projects/:projectName/exclusion_rules/:ruleID", r.DeleteExclusionRule,
+	)
 	apiGroup.POST(
 		"/repos/:repoName/projects/:projectName/branches/:branchName/commits/:commit/coverage", r.PostCoverage,
 	)
+	apiGroup.POST(
+		"/repos/:repoName/projects/:projectName/branches/:branchName/commits/:commit/coverage/finalize",
+		r.FinalizeCoverage,
+	)
+	apiGroup.POST(
+		"/repos/:repoName/projects/:projectName/branches/:branchName/commits/:commit/patch_coverage",
+		r.PostPatchCoverage,
+	)
+	apiGroup.POST(
+		"/repos/:repoName/projects/:projectName/branches/:branchName/commits/:commit/gate", r.PostGate,
+	)
+	apiGroup.POST(
+		"/repos/:repoName/projects/:projectName/branches/:branchName/commits/:commit/integration_coverage",
+		r.PostIntegrationCoverage,
+	)
 	apiGroup.GET(
 		"/repos/:repoName/projects/:projectName/branches/:branchName/coverage", r.GetLatestBranchCoverage,
 	)
 	apiGroup.GET(
 		"/repos/:repoName/projects/:projectName/branches/:branchName/commits/:commit/coverage_data", r.GetCoverageData,
 	)
+	apiGroup.GET(
+		"/repos/:repoName/projects/:projectName/branches/:branchName/commits/:commit/tree", r.GetCoverageTree,
+	)
+	apiGroup.GET(
+		"/repos/:repoName/projects/:projectName/branches/:branchName/commits/:commit/uncovered_lines",
+		r.GetUncoveredLines,
+	)
 	apiGroup.GET(
 		"/repos/:repoName/projects/:projectName/branches/:branchName/coverage_history", r.ListCoverageHistory,
 	)
+	apiGroup.GET(
+		"/repos/:repoName/projects/:projectName/branches/:branchName/files/history", r.ListFileHistory,
+	)
 }
@@ -1,18 +1,30 @@
 package apiv1
 
 import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"goverage/data"
+	"goverage/internal/coverage"
+	"goverage/internal/repoconfig"
 	"goverage/routers/api/v1/mocks"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 //go:generate go run -mod=mod github.com/vektra/mockery/v2 --name repository --structname Repository
@@ -107,6 +119,59 @@ func TestListProjects(t *testing.T) {
 	})
 }
 
+func TestGetRepoCoverage(t *testing.T) {
+	setup := func(query string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/repo1/branches/main/coverage"+query, http.NoBody)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "branchName")
+		c.SetParamValues("repo1", "main")
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("WeighsProjectsByStatements", func(t *testing.T) {
+		router, mockDB, c, rec := setup("")
+		coverageDate := pgtype.Timestamptz{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+		mockDB.On("ListLatestProjectCoverage", mock.Anything, data.ListLatestProjectCoverageParams{
+			RepoName: "repo1", BranchName: "main", Kind: "unit",
+		}).Return([]data.ListLatestProjectCoverageRow{
+			{ProjectName: "api", Commit: "abc", Coverage: 90, CoverageDate: coverageDate, StatementsTotal: 300, LinesTotal: 350},
+			{ProjectName: "web", Commit: "def", Coverage: 50, CoverageDate: coverageDate, LinesTotal: 100},
+		}, nil)
+
+		err := router.GetRepoCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"repo_name": "repo1",
+			"branch_name": "main",
+			"kind": "unit",
+			"coverage": 80,
+			"statements": 400,
+			"projects": [
+				{"project_name": "api", "commit": "abc", "coverage_date": "2024-01-01T00:00:00Z", "coverage": 90, "statements": 300},
+				{"project_name": "web", "commit": "def", "coverage_date": "2024-01-01T00:00:00Z", "coverage": 50, "statements": 100}
+			]
+		}`, rec.Body.String())
+	})
+
+	t.Run("ReturnsNotFoundWithoutCoverage", func(t *testing.T) {
+		router, mockDB, c, _ := setup("?kind=integration")
+		mockDB.On("ListLatestProjectCoverage", mock.Anything, mock.Anything).Return(nil, nil)
+
+		err := router.GetRepoCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
+
 func TestListBranches(t *testing.T) {
 	expectedBranchesParams := data.ListBranchesParams{
 		RepoName:    "repo1",
@@ -156,3 +221,1471 @@ func TestListBranches(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
 }
+
+func newCoverageUploadRequest(t *testing.T, target string, files map[string]string, fields map[string]string) *http.Request {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, content := range files {
+		part, err := writer.CreateFormFile(name, name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, target, body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+
+	return req
+}
+
+func TestPostCoverage(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+
+	setup := func(files map[string]string, fields map[string]string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := newCoverageUploadRequest(
+			t, "/api/v1/repos/repo1/projects/project1/branches/main/commits/"+commit+"/coverage", files, fields,
+		)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", commit)
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("StoresPythonCoverage", func(t *testing.T) {
+		pythonReport := `{"meta": {"timestamp": "2024-03-27T20:01:53.123456"}, "totals": {"percent_covered": 87.5, "covered_lines": 7, "num_statements": 8}}`
+		router, mockDB, c, rec := setup(map[string]string{"coverage": pythonReport}, nil)
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{}, nil)
+		mockDB.On("UpsertCoverageWithConfig", mock.Anything, mock.MatchedBy(func(params data.UpsertCoverageParams) bool {
+			return params.Commit == commit &&
+				params.Coverage == 87.5 &&
+				params.CoverageDate.Time.Equal(time.Date(2024, 3, 27, 20, 1, 53, 123456000, time.UTC)) &&
+				string(params.RawData) == pythonReport &&
+				params.Format == "python" &&
+				params.Kind == "unit" &&
+				params.LinesCovered == 7 &&
+				params.LinesTotal == 8 &&
+				params.BranchesTotal == 0
+		}), mock.Anything, (*string)(nil)).Return(data.Coverage{}, nil)
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("StoresGoCoverProfile", func(t *testing.T) {
+		profile := "mode: set\nexample.com/app/main.go:3.13,5.2 3 1\nexample.com/app/main.go:7.13,9.2 1 0\n"
+		router, mockDB, c, rec := setup(map[string]string{"coverage": profile}, nil)
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{}, nil)
+		mockDB.On("UpsertCoverageWithConfig", mock.Anything, mock.MatchedBy(func(params data.UpsertCoverageParams) bool {
+			var stored map[string]interface{}
+			return params.Coverage == 75.0 &&
+				params.Format == "go" &&
+				json.Unmarshal(params.RawData, &stored) == nil &&
+				stored["files"] != nil
+		}), mock.MatchedBy(func(report *coverage.Report) bool {
+			return len(report.Files["example.com/app/main.go"].Lines) == 6
+		}), (*string)(nil)).Return(data.Coverage{}, nil)
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("RejectsUndetectedFormat", func(t *testing.T) {
+		router, _, c, _ := setup(map[string]string{"coverage": "not a coverage file"}, nil)
+
+		err := router.PostCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("UsesForcedFormat", func(t *testing.T) {
+		profile := "mode: set\na.go:1.1,2.2 1 1\n"
+		router, _, c, _ := setup(map[string]string{"coverage": profile}, map[string]string{"format": "python"})
+
+		err := router.PostCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("RejectsUnknownForcedFormat", func(t *testing.T) {
+		router, _, c, rec := setup(map[string]string{"coverage": "{}"}, map[string]string{"format": "clover"})
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Format must be one of")
+	})
+
+	t.Run("RequiresCoverageFile", func(t *testing.T) {
+		router, _, c, _ := setup(nil, nil)
+
+		err := router.PostCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("StoresShardUntilEveryShardIsUploaded", func(t *testing.T) {
+		profile := "mode: set\nexample.com/app/main.go:3.13,5.2 3 1\n"
+		router, mockDB, c, rec := setup(map[string]string{"coverage": profile}, map[string]string{"shard": "1", "shards": "2"})
+		mockDB.On("UpsertCoverageShard", mock.Anything, data.UpsertCoverageShardParams{
+			RepoName:       "repo1",
+			ProjectName:    "project1",
+			BranchName:     "main",
+			Commit:         commit,
+			ShardID:        "1",
+			Format:         "go",
+			Content:        []byte(profile),
+			ExpectedShards: pgtype.Int4{Int32: 2, Valid: true},
+		}).Return(nil)
+		mockDB.On("ListCoverageShards", mock.Anything, mock.Anything).Return([]data.CoverageShard{
+			{ShardID: "1", Format: "go", Content: []byte(profile), ExpectedShards: pgtype.Int4{Int32: 2, Valid: true}},
+		}, nil)
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.JSONEq(t, `{"shards_received": 1, "shards_expected": 2}`, rec.Body.String())
+	})
+
+	t.Run("MergesShardsOnceEveryShardIsUploaded", func(t *testing.T) {
+		profileA := "mode: set\nexample.com/app/main.go:3.13,5.2 3 1\nexample.com/app/main.go:7.13,9.2 1 0\n"
+		profileB := "mode: set\nexample.com/app/main.go:3.13,5.2 3 0\nexample.com/app/main.go:7.13,9.2 1 1\n"
+		router, mockDB, c, rec := setup(map[string]string{"coverage": profileB}, map[string]string{"shard": "2", "shards": "2"})
+		mockDB.On("UpsertCoverageShard", mock.Anything, mock.Anything).Return(nil)
+		mockDB.On("ListCoverageShards", mock.Anything, mock.Anything).Return([]data.CoverageShard{
+			{ShardID: "1", Format: "go", Content: []byte(profileA), ExpectedShards: pgtype.Int4{Int32: 2, Valid: true}},
+			{ShardID: "2", Format: "go", Content: []byte(profileB), ExpectedShards: pgtype.Int4{Int32: 2, Valid: true}},
+		}, nil)
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{}, nil)
+		mockDB.On("UpsertCoverageWithConfig", mock.Anything, mock.MatchedBy(func(params data.UpsertCoverageParams) bool {
+			return params.Commit == commit && params.Coverage == 100.0 && params.Format == "go"
+		}), mock.Anything, (*string)(nil)).Return(data.Coverage{}, nil)
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("RequiresShardWithShards", func(t *testing.T) {
+		router, _, c, rec := setup(map[string]string{"coverage": "{}"}, map[string]string{"shards": "2"})
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Shard is required")
+	})
+
+	t.Run("RejectsTruncatedCommit", func(t *testing.T) {
+		router, _, c, rec := setup(map[string]string{"coverage": "{}"}, nil)
+		c.SetParamValues("repo1", "project1", "main", commit[:8])
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "full 40 or 64 characters SHA")
+	})
+
+	t.Run("LowercasesCommit", func(t *testing.T) {
+		profile := "mode: set\nexample.com/app/main.go:3.13,5.2 3 1\n"
+		router, mockDB, c, rec := setup(map[string]string{"coverage": profile}, nil)
+		c.SetParamValues("repo1", "project1", "main", strings.ToUpper(commit))
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{}, nil)
+		mockDB.On("UpsertCoverageWithConfig", mock.Anything, mock.MatchedBy(func(params data.UpsertCoverageParams) bool {
+			return params.Commit == commit
+		}), mock.Anything, (*string)(nil)).Return(data.Coverage{}, nil)
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("AppliesUploadedConfig", func(t *testing.T) {
+		profile := "mode: set\nmain.go:3.13,5.2 3 1\ndb/queries.sql.go:7.13,9.2 1 0\n"
+		config := "ignore:\n  - \"**/*.sql.go\"\nprojects:\n  project1:\n    path_prefix: services/api\n"
+		router, mockDB, c, rec := setup(map[string]string{"coverage": profile, "config": config}, nil)
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{}, nil)
+		mockDB.On("UpsertCoverageWithConfig", mock.Anything, mock.MatchedBy(func(params data.UpsertCoverageParams) bool {
+			var stored coverage.Report
+			return params.Coverage == 100.0 &&
+				json.Unmarshal(params.RawData, &stored) == nil &&
+				len(stored.Files) == 2
+		}), mock.MatchedBy(func(report *coverage.Report) bool {
+			return len(report.Files) == 1 && report.Files["services/api/main.go"] != nil
+		}), &config).Return(data.Coverage{ID: 1}, nil)
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("StoresRenames", func(t *testing.T) {
+		profile := "mode: set\ncore/db.go:3.13,5.2 3 1\n"
+		renames := "M\tmain.go\nR095\tdb.go\tcore/db.go\n"
+		router, mockDB, c, rec := setup(map[string]string{"coverage": profile, "renames": renames}, nil)
+		mockDB.On("InsertFileRenames", mock.Anything, data.InsertFileRenamesParams{
+			RepoName:    "repo1",
+			ProjectName: "project1",
+			BranchName:  "main",
+			Commit:      commit,
+			OldPaths:    []string{"db.go"},
+			NewPaths:    []string{"core/db.go"},
+		}).Return(nil)
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{}, nil)
+		mockDB.On("UpsertCoverageWithConfig", mock.Anything, mock.Anything, mock.Anything, (*string)(nil)).Return(data.Coverage{}, nil)
+
+		err := router.PostCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("RejectsInvalidConfig", func(t *testing.T) {
+		profile := "mode: set\nmain.go:3.13,5.2 3 1\n"
+		router, _, c, _ := setup(map[string]string{"coverage": profile, "config": "gate:\n  min_coverage: 120\n"}, nil)
+
+		err := router.PostCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		assert.Equal(t, ConfigValidationSchema{
+			Errors: []repoconfig.Error{{Line: 2, Message: "min_coverage must be >=0 and <=100"}},
+		}, httpErr.Message)
+	})
+}
+
+func TestValidateConfig(t *testing.T) {
+	setup := func(body string) (*Router, echo.Context, *httptest.ResponseRecorder) {
+		router := NewAPIV1Router(echo.New(), mocks.NewRepository(t))
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/config/validate", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		return router, router.e.NewContext(req, rec), rec
+	}
+
+	t.Run("AcceptsValidConfig", func(t *testing.T) {
+		router, c, rec := setup("ignore:\n  - mocks/\n")
+
+		err := router.ValidateConfig(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"valid": true, "errors": []}`, rec.Body.String())
+	})
+
+	t.Run("ReportsErrorLines", func(t *testing.T) {
+		router, c, _ := setup("ignore:\n  - mocks/\n\nunknown: 1\n")
+
+		err := router.ValidateConfig(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		assert.Equal(t, ConfigValidationSchema{
+			Errors: []repoconfig.Error{{Line: 4, Message: "field unknown not found in type repoconfig.Config"}},
+		}, httpErr.Message)
+	})
+}
+
+// goCoverDirArchive tars the GOCOVERDIR fixture of the coverage package.
+func goCoverDirArchive(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join("..", "..", "..", "internal", "coverage", "testdata", "gocoverdir")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: entry.Name(), Mode: 0o644, Size: int64(len(content))}))
+		_, err = tarWriter.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+
+	return buf.String()
+}
+
+func TestPostIntegrationCoverage(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+
+	setup := func(files map[string]string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := newCoverageUploadRequest(
+			t, "/api/v1/repos/repo1/projects/project1/branches/main/commits/"+commit+"/integration_coverage", files, nil,
+		)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", commit)
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("StoresIntegrationCoverage", func(t *testing.T) {
+		router, mockDB, c, rec := setup(map[string]string{"archive": goCoverDirArchive(t)})
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{}, nil)
+		mockDB.On("UpsertCoverageWithConfig", mock.Anything, mock.MatchedBy(func(params data.UpsertCoverageParams) bool {
+			var stored map[string]interface{}
+			return params.Commit == commit &&
+				params.Coverage == 75.0 &&
+				params.Format == "gocoverdir" &&
+				params.Kind == "integration" &&
+				json.Unmarshal(params.RawData, &stored) == nil &&
+				stored["packages"] != nil
+		}), mock.Anything, (*string)(nil)).Return(data.Coverage{}, nil)
+
+		err := router.PostIntegrationCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("RejectsInvalidArchive", func(t *testing.T) {
+		router, _, c, _ := setup(map[string]string{"archive": "mode: set\na.go:1.1,2.2 1 1\n"})
+
+		err := router.PostIntegrationCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("RequiresArchiveFile", func(t *testing.T) {
+		router, _, c, _ := setup(map[string]string{"coverage": goCoverDirArchive(t)})
+
+		err := router.PostIntegrationCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+}
+
+func TestGetLatestBranchCoverage(t *testing.T) {
+	setup := func(target string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName")
+		c.SetParamValues("repo1", "project1", "main")
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("DefaultsToUnitCoverage", func(t *testing.T) {
+		router, mockDB, c, rec := setup("/api/v1/repos/repo1/projects/project1/branches/main/coverage")
+		mockDB.On("GetRecentCoverage", mock.Anything, data.GetRecentCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main", Kind: "unit",
+		}).Return(data.Coverage{Coverage: 80, Kind: "unit"}, nil)
+
+		err := router.GetLatestBranchCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"kind":"unit"`)
+	})
+
+	t.Run("ReturnsBranchCoverage", func(t *testing.T) {
+		router, mockDB, c, rec := setup("/api/v1/repos/repo1/projects/project1/branches/main/coverage")
+		mockDB.On("GetRecentCoverage", mock.Anything, mock.Anything).
+			Return(data.Coverage{Coverage: 80, BranchesCovered: 3, BranchesTotal: 4}, nil)
+
+		err := router.GetLatestBranchCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"branches_covered":3,"branches_total":4,"branch_coverage":75`)
+	})
+
+	t.Run("OmitsBranchCoverageWithoutBranches", func(t *testing.T) {
+		router, mockDB, c, rec := setup("/api/v1/repos/repo1/projects/project1/branches/main/coverage")
+		mockDB.On("GetRecentCoverage", mock.Anything, mock.Anything).Return(data.Coverage{Coverage: 80}, nil)
+
+		err := router.GetLatestBranchCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"branch_coverage":null`)
+	})
+
+	t.Run("ReturnsIntegrationCoverage", func(t *testing.T) {
+		router, mockDB, c, rec := setup("/api/v1/repos/repo1/projects/project1/branches/main/coverage?kind=integration")
+		mockDB.On("GetRecentCoverage", mock.Anything, data.GetRecentCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main", Kind: "integration",
+		}).Return(data.Coverage{Coverage: 60, Kind: "integration"}, nil)
+
+		err := router.GetLatestBranchCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"kind":"integration"`)
+	})
+
+	t.Run("RejectsUnknownKind", func(t *testing.T) {
+		router, _, c, rec := setup("/api/v1/repos/repo1/projects/project1/branches/main/coverage?kind=e2e")
+
+		err := router.GetLatestBranchCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestGetCoverageData(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+
+	setup := func(prefix string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(
+			http.MethodGet, "/api/v1/repos/repo1/projects/project1/branches/main/commits/"+prefix+"/coverage_data", http.NoBody,
+		)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", prefix)
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("ResolvesCommitPrefix", func(t *testing.T) {
+		router, mockDB, c, rec := setup("0123456")
+		mockDB.On("ListCommitsByPrefix", mock.Anything, data.ListCommitsByPrefixParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main", Kind: "unit", Prefix: "0123456",
+		}).Return([]string{commit}, nil)
+		mockDB.On("GetCoverageData", mock.Anything, data.GetCoverageDataParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main", Commit: commit, Kind: "unit",
+		}).Return([]byte(`{"coverage": 80}`), nil)
+
+		err := router.GetCoverageData(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"coverage": 80}`, rec.Body.String())
+	})
+
+	t.Run("ReturnsConflictForAmbiguousPrefix", func(t *testing.T) {
+		router, mockDB, c, _ := setup("0123456")
+		candidates := []string{commit, "0123456fffffffffffffffffffffffffffffffff"}
+		mockDB.On("ListCommitsByPrefix", mock.Anything, mock.Anything).Return(candidates, nil)
+
+		err := router.GetCoverageData(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusConflict, httpErr.Code)
+		assert.Equal(t, CommitConflictSchema{Message: "commit prefix is ambiguous", Candidates: candidates}, httpErr.Message)
+	})
+
+	t.Run("ReturnsNotFoundForUnknownCommit", func(t *testing.T) {
+		router, mockDB, c, _ := setup(commit)
+		mockDB.On("ListCommitsByPrefix", mock.Anything, mock.Anything).Return(nil, nil)
+
+		err := router.GetCoverageData(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+
+	t.Run("RejectsShortPrefix", func(t *testing.T) {
+		router, _, c, rec := setup("012345")
+
+		err := router.GetCoverageData(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestGetCoverageTree(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+
+	setup := func(query string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(
+			http.MethodGet, "/api/v1/repos/repo1/projects/project1/branches/main/commits/"+commit+"/tree"+query, http.NoBody,
+		)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", commit)
+
+		mockDB.On("ListCommitsByPrefix", mock.Anything, mock.Anything).Return([]string{commit}, nil).Maybe()
+		mockDB.On("GetCommitCoverage", mock.Anything, data.GetCommitCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main", Commit: commit, Kind: "unit",
+		}).Return(data.Coverage{ID: 1}, nil).Maybe()
+		mockDB.On("ListCoverageFiles", mock.Anything, int32(1)).Return([]data.CoverageFile{
+			{Path: "app/core/db.go", StatementsCovered: 3, StatementsTotal: 4, LinesCovered: 5, LinesTotal: 6},
+			{Path: "app/main.go", StatementsCovered: 0, StatementsTotal: 4, LinesCovered: 0, LinesTotal: 4},
+			{Path: "cmd/main.go", StatementsCovered: 2, StatementsTotal: 2, LinesCovered: 2, LinesTotal: 2},
+		}, nil).Maybe()
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("ReturnsTreeAtPathAndDepth", func(t *testing.T) {
+		router, _, c, rec := setup("?path=app&depth=1")
+
+		err := router.GetCoverageTree(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"name": "app", "path": "app", "type": "directory", "coverage": 37.5,
+			"statements_covered": 3, "statements_total": 8, "lines_covered": 5, "lines_total": 10,
+			"children": [
+				{"name": "core", "path": "app/core", "type": "directory", "coverage": 75,
+					"statements_covered": 3, "statements_total": 4, "lines_covered": 5, "lines_total": 6},
+				{"name": "main.go", "path": "app/main.go", "type": "file", "coverage": 0,
+					"statements_covered": 0, "statements_total": 4, "lines_covered": 0, "lines_total": 4}
+			]
+		}`, rec.Body.String())
+	})
+
+	t.Run("ReturnsNotFoundForUnknownPath", func(t *testing.T) {
+		router, _, c, _ := setup("?path=lib")
+
+		err := router.GetCoverageTree(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+
+	t.Run("RejectsNegativeDepth", func(t *testing.T) {
+		router, _, c, rec := setup("?depth=-1")
+
+		err := router.GetCoverageTree(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestGetUncoveredLines(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+
+	setup := func(query string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(
+			http.MethodGet,
+			"/api/v1/repos/repo1/projects/project1/branches/main/commits/0123456/uncovered_lines"+query,
+			http.NoBody,
+		)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", "0123456")
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("ReturnsRangesUnderPath", func(t *testing.T) {
+		router, mockDB, c, rec := setup("?path=app")
+		mockDB.On("ListCommitsByPrefix", mock.Anything, mock.Anything).Return([]string{commit}, nil)
+		mockDB.On("ListCoverageLineRanges", mock.Anything, data.ListCoverageLineRangesParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main", Commit: commit, Kind: "unit",
+		}).Return([]data.ListCoverageLineRangesRow{
+			{Path: "app/covered.py", StartLine: 1, EndLine: 4, Hits: 1},
+			{Path: "app/main.py", StartLine: 1, EndLine: 9, Hits: 1},
+			{Path: "app/main.py", StartLine: 10, EndLine: 14, Hits: 0},
+			{Path: "app/main.py", StartLine: 20, EndLine: 20, Hits: 3, BranchesCovered: 1, BranchesTotal: 2},
+			{Path: "app/main.py", StartLine: 22, EndLine: 22, Hits: 0},
+			{Path: "application.py", StartLine: 1, EndLine: 1, Hits: 0},
+		}, nil)
+
+		err := router.GetUncoveredLines(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"commit": "`+commit+`",
+			"files": [{
+				"path": "app/main.py",
+				"uncovered": [[10, 14], [22, 22]],
+				"partial": [{"start_line": 20, "end_line": 20, "hits": 3, "branches_covered": 1, "branches_total": 2}]
+			}]
+		}`, rec.Body.String())
+	})
+
+	t.Run("RejectsUnknownKind", func(t *testing.T) {
+		router, _, c, rec := setup("?kind=e2e")
+
+		err := router.GetUncoveredLines(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestListFileHistory(t *testing.T) {
+	setup := func(query string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(
+			http.MethodGet, "/api/v1/repos/repo1/projects/project1/branches/main/files/history"+query, http.NoBody,
+		)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName")
+		c.SetParamValues("repo1", "project1", "main")
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("FollowsRenames", func(t *testing.T) {
+		router, mockDB, c, rec := setup("?path=core/db.go&order=asc&limit=10&page=2")
+		mockDB.On("ListFileRenames", mock.Anything, data.ListFileRenamesParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main",
+		}).Return([]data.ListFileRenamesRow{
+			{OldPath: "db.go", NewPath: "core/db.go"},
+			{OldPath: "database.go", NewPath: "db.go"},
+			{OldPath: "main.go", NewPath: "cmd/main.go"},
+		}, nil)
+		mockDB.On("ListFileCoverageHistory", mock.Anything, data.ListFileCoverageHistoryParams{
+			RepoName:       "repo1",
+			ProjectName:    "project1",
+			BranchName:     "main",
+			Offset:         10,
+			Limit:          10,
+			Kind:           "unit",
+			Paths:          []string{"core/db.go", "db.go", "database.go"},
+			OrderDirection: "asc",
+		}).Return([]data.ListFileCoverageHistoryRow{{
+			Commit:          "abc",
+			CoverageDate:    pgtype.Timestamptz{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			Path:            "db.go",
+			Coverage:        50,
+			LinesCovered:    1,
+			LinesTotal:      2,
+			BranchesCovered: 1,
+			BranchesTotal:   4,
+		}}, nil)
+
+		err := router.ListFileHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[{
+			"commit": "abc",
+			"coverage_date": "2024-01-01T00:00:00Z",
+			"path": "db.go",
+			"coverage": 50,
+			"lines_covered": 1,
+			"lines_total": 2,
+			"branches_covered": 1,
+			"branches_total": 4,
+			"branch_coverage": 25
+		}]`, rec.Body.String())
+	})
+
+	t.Run("RequiresPath", func(t *testing.T) {
+		router, _, c, rec := setup("")
+
+		err := router.ListFileHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Path is required")
+	})
+}
+
+func TestFinalizeCoverage(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+
+	setup := func() (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/api/v1/repos/repo1/projects/project1/branches/main/commits/"+commit+"/coverage/finalize",
+			http.NoBody,
+		)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", commit)
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("MergesUploadedShards", func(t *testing.T) {
+		router, mockDB, c, rec := setup()
+		mockDB.On("ListCoverageShards", mock.Anything, data.ListCoverageShardsParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main", Commit: commit,
+		}).Return([]data.CoverageShard{
+			{ShardID: "1", Format: "go", Content: []byte("mode: set\nexample.com/app/main.go:3.13,5.2 3 1\n")},
+			{ShardID: "2", Format: "go", Content: []byte("mode: set\nexample.com/app/main.go:7.13,9.2 1 0\n")},
+		}, nil)
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{}, nil)
+		mockDB.On("UpsertCoverageWithConfig", mock.Anything, mock.MatchedBy(func(params data.UpsertCoverageParams) bool {
+			return params.Coverage == 75.0 && params.Kind == "unit"
+		}), mock.Anything, (*string)(nil)).Return(data.Coverage{}, nil)
+
+		err := router.FinalizeCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("RejectsShardsOfDifferentFormats", func(t *testing.T) {
+		router, mockDB, c, _ := setup()
+		mockDB.On("ListCoverageShards", mock.Anything, mock.Anything).Return([]data.CoverageShard{
+			{ShardID: "1", Format: "go", Content: []byte("mode: set\nexample.com/app/main.go:3.13,5.2 3 1\n")},
+			{ShardID: "2", Format: "lcov", Content: []byte("SF:a.c\nDA:1,1\nend_of_record\n")},
+		}, nil)
+
+		err := router.FinalizeCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("ReturnsNotFoundWithoutShards", func(t *testing.T) {
+		router, mockDB, c, _ := setup()
+		mockDB.On("ListCoverageShards", mock.Anything, mock.Anything).Return(nil, nil)
+
+		err := router.FinalizeCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
+
+func TestPostPatchCoverage(t *testing.T) {
+	const (
+		commit  = "0123456789abcdef0123456789abcdef01234567"
+		gitDiff = "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -3,2 +3,4 @@\n a\n+b\n+c\n d\n"
+	)
+
+	setup := func(files map[string]string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := newCoverageUploadRequest(
+			t, "/api/v1/repos/repo1/projects/project1/branches/main/commits/0123456/patch_coverage", files, nil,
+		)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", "0123456")
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("ReturnsCoverageOfChangedLines", func(t *testing.T) {
+		router, mockDB, c, rec := setup(map[string]string{"diff": gitDiff})
+		mockDB.On("ListCommitsByPrefix", mock.Anything, mock.Anything).Return([]string{commit}, nil)
+		mockDB.On("ListCoverageLineRanges", mock.Anything, data.ListCoverageLineRangesParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main", Commit: commit, Kind: "unit",
+		}).Return([]data.ListCoverageLineRangesRow{
+			{Path: "example.com/app/main.go", StartLine: 3, EndLine: 4, Hits: 1},
+			{Path: "example.com/app/main.go", StartLine: 5, EndLine: 5, Hits: 0},
+		}, nil)
+
+		err := router.PostPatchCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"commit": "`+commit+`",
+			"coverage": 50,
+			"lines_covered": 1,
+			"lines_total": 2,
+			"files": [{"path": "main.go", "coverage": 50, "lines_covered": 1, "lines_total": 2, "uncovered_lines": [5]}],
+			"approximate": false
+		}`, rec.Body.String())
+	})
+
+	t.Run("ReadsKindFromQuery", func(t *testing.T) {
+		router, mockDB, _, _ := setup(nil)
+		req := newCoverageUploadRequest(
+			t, "/api/v1/repos/repo1/projects/project1/branches/main/commits/0123456/patch_coverage?kind=integration",
+			map[string]string{"diff": gitDiff}, nil,
+		)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", "0123456")
+		mockDB.On("ListCommitsByPrefix", mock.Anything, mock.MatchedBy(func(params data.ListCommitsByPrefixParams) bool {
+			return params.Kind == coverage.KindIntegration
+		})).Return([]string{commit}, nil)
+		mockDB.On("ListCoverageLineRanges", mock.Anything, mock.MatchedBy(func(params data.ListCoverageLineRangesParams) bool {
+			return params.Kind == coverage.KindIntegration
+		})).Return([]data.ListCoverageLineRangesRow{}, nil)
+
+		err := router.PostPatchCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("ApproximatesFromBaseCommitWithoutDiff", func(t *testing.T) {
+		const baseCommit = "89abcdef0123456789abcdef0123456789abcdef"
+		router, mockDB, _, _ := setup(nil)
+		req := newCoverageUploadRequest(
+			t, "/api/v1/repos/repo1/projects/project1/branches/main/commits/0123456/patch_coverage?base=89ABCDEF", nil, nil,
+		)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", "0123456")
+		mockDB.On("ListCommitsByPrefix", mock.Anything, mock.Anything).Return([]string{commit}, nil)
+		mockDB.On("ListProjectCommitsByPrefix", mock.Anything, data.ListProjectCommitsByPrefixParams{
+			RepoName: "repo1", ProjectName: "project1", Kind: "unit", Prefix: "89abcdef",
+		}).Return([]string{baseCommit}, nil)
+		mockDB.On("GetProjectCommitCoverage", mock.Anything, data.GetProjectCommitCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", Commit: baseCommit, Kind: "unit",
+		}).Return(data.Coverage{
+			ID: 1, RepoName: "repo1", ProjectName: "project1", BranchName: "develop", Commit: baseCommit, Kind: "unit",
+		}, nil)
+		mockDB.On("ListCoverageFiles", mock.Anything, int32(1)).Return([]data.CoverageFile{{Path: "main.go"}}, nil)
+		mockDB.On("ListCoverageLineRanges", mock.Anything, mock.MatchedBy(func(params data.ListCoverageLineRangesParams) bool {
+			return params.Commit == baseCommit && params.BranchName == "develop"
+		})).Return([]data.ListCoverageLineRangesRow{
+			{Path: "main.go", StartLine: 3, EndLine: 4, Hits: 1},
+		}, nil)
+		mockDB.On("ListCoverageLineRanges", mock.Anything, mock.MatchedBy(func(params data.ListCoverageLineRangesParams) bool {
+			return params.Commit == commit && params.BranchName == "main"
+		})).Return([]data.ListCoverageLineRangesRow{
+			{Path: "main.go", StartLine: 3, EndLine: 5, Hits: 1},
+			{Path: "main.go", StartLine: 6, EndLine: 6, Hits: 0},
+		}, nil)
+
+		err := router.PostPatchCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"commit": "`+commit+`",
+			"coverage": 50,
+			"lines_covered": 1,
+			"lines_total": 2,
+			"files": [{"path": "main.go", "coverage": 50, "lines_covered": 1, "lines_total": 2, "uncovered_lines": [6]}],
+			"approximate": true
+		}`, rec.Body.String())
+	})
+
+	t.Run("PrefersDiffOverBaseCommit", func(t *testing.T) {
+		router, mockDB, _, _ := setup(nil)
+		req := newCoverageUploadRequest(
+			t, "/api/v1/repos/repo1/projects/project1/branches/main/commits/0123456/patch_coverage?base=89abcdef",
+			map[string]string{"diff": gitDiff}, nil,
+		)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", "0123456")
+		mockDB.On("ListCommitsByPrefix", mock.Anything, mock.Anything).Return([]string{commit}, nil)
+		mockDB.On("ListCoverageLineRanges", mock.Anything, mock.Anything).Return([]data.ListCoverageLineRangesRow{}, nil)
+
+		err := router.PostPatchCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var patch PatchCoverageSchema
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &patch))
+		assert.False(t, patch.Approximate)
+	})
+
+	t.Run("RejectsInvalidDiff", func(t *testing.T) {
+		router, _, c, _ := setup(map[string]string{"diff": "@@ -1 +1 @@\n"})
+
+		err := router.PostPatchCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("RequiresDiffFile", func(t *testing.T) {
+		router, _, c, _ := setup(nil)
+
+		err := router.PostPatchCoverage(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+}
+
+func TestCompareCommits(t *testing.T) {
+	const (
+		baseCommit = "0123456789abcdef0123456789abcdef01234567"
+		headCommit = "89abcdef0123456789abcdef0123456789abcdef"
+	)
+
+	setup := func(query string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos/repo1/projects/project1/compare?"+query, http.NoBody)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName")
+		c.SetParamValues("repo1", "project1")
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("ReturnsFileDeltas", func(t *testing.T) {
+		router, mockDB, c, rec := setup("base=0123456&head=89abcdef")
+		mockDB.On("ListProjectCommitsByPrefix", mock.Anything, data.ListProjectCommitsByPrefixParams{
+			RepoName: "repo1", ProjectName: "project1", Kind: "unit", Prefix: "0123456",
+		}).Return([]string{baseCommit}, nil)
+		mockDB.On("ListProjectCommitsByPrefix", mock.Anything, data.ListProjectCommitsByPrefixParams{
+			RepoName: "repo1", ProjectName: "project1", Kind: "unit", Prefix: "89abcdef",
+		}).Return([]string{headCommit}, nil)
+		mockDB.On("GetProjectCommitCoverage", mock.Anything, data.GetProjectCommitCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", Commit: baseCommit, Kind: "unit",
+		}).Return(data.Coverage{
+			ID: 1, RepoName: "repo1", ProjectName: "project1", BranchName: "main", Commit: baseCommit, Kind: "unit", Coverage: 50,
+		}, nil)
+		mockDB.On("GetProjectCommitCoverage", mock.Anything, data.GetProjectCommitCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", Commit: headCommit, Kind: "unit",
+		}).Return(data.Coverage{
+			ID: 2, RepoName: "repo1", ProjectName: "project1", BranchName: "feature", Commit: headCommit, Kind: "unit", Coverage: 75,
+		}, nil)
+		mockDB.On("ListCoverageFiles", mock.Anything, int32(1)).Return([]data.CoverageFile{
+			{Path: "main.go", Coverage: 50}, {Path: "old.go", Coverage: 0},
+		}, nil)
+		mockDB.On("ListCoverageFiles", mock.Anything, int32(2)).Return([]data.CoverageFile{
+			{Path: "main.go", Coverage: 100}, {Path: "new.go", Coverage: 50},
+		}, nil)
+		mockDB.On("ListCoverageLineRanges", mock.Anything, mock.MatchedBy(func(params data.ListCoverageLineRangesParams) bool {
+			return params.Commit == baseCommit && params.BranchName == "main"
+		})).Return([]data.ListCoverageLineRangesRow{
+			{Path: "main.go", StartLine: 1, EndLine: 1, Hits: 1},
+			{Path: "main.go", StartLine: 2, EndLine: 2, Hits: 0},
+		}, nil)
+		mockDB.On("ListCoverageLineRanges", mock.Anything, mock.MatchedBy(func(params data.ListCoverageLineRangesParams) bool {
+			return params.Commit == headCommit && params.BranchName == "feature"
+		})).Return([]data.ListCoverageLineRangesRow{
+			{Path: "main.go", StartLine: 1, EndLine: 2, Hits: 1},
+		}, nil)
+
+		err := router.CompareCommits(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"base": {"commit": "`+baseCommit+`", "branch_name": "main", "coverage": 50},
+			"head": {"commit": "`+headCommit+`", "branch_name": "feature", "coverage": 75},
+			"delta": 25,
+			"added_files": [{
+				"path": "new.go", "coverage_before": null, "coverage_after": 50,
+				"newly_covered_lines": [], "newly_uncovered_lines": []
+			}],
+			"removed_files": [{
+				"path": "old.go", "coverage_before": 0, "coverage_after": null,
+				"newly_covered_lines": [], "newly_uncovered_lines": []
+			}],
+			"changed_files": [{
+				"path": "main.go", "coverage_before": 50, "coverage_after": 100,
+				"newly_covered_lines": [2], "newly_uncovered_lines": []
+			}]
+		}`, rec.Body.String())
+	})
+
+	t.Run("RequiresBaseAndHead", func(t *testing.T) {
+		router, _, c, rec := setup("base=0123456")
+
+		err := router.CompareCommits(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("ReturnsConflictForAmbiguousCommit", func(t *testing.T) {
+		router, mockDB, c, _ := setup("base=0123456&head=89abcdef")
+		mockDB.On("ListProjectCommitsByPrefix", mock.Anything, mock.Anything).
+			Return([]string{baseCommit, "0123456fffffffffffffffffffffffffffffffff"}, nil)
+
+		err := router.CompareCommits(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusConflict, httpErr.Code)
+	})
+}
+
+func TestGatePolicy(t *testing.T) {
+	setup := func(method, body string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(method, "/api/v1/repos/repo1/projects/project1/gate_policy", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName")
+		c.SetParamValues("repo1", "project1")
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("StoresPolicy", func(t *testing.T) {
+		router, mockDB, c, rec := setup(http.MethodPut, `{"min_coverage": 80, "paths": [{"pattern": "app/**", "min_coverage": 90}]}`)
+		mockDB.On("UpsertGatePolicy", mock.Anything, data.UpsertGatePolicyParams{
+			RepoName:    "repo1",
+			ProjectName: "project1",
+			MinCoverage: pgtype.Float8{Float64: 80, Valid: true},
+			PathRules:   []byte(`[{"pattern":"app/**","min_coverage":90}]`),
+		}).Return(data.GatePolicy{
+			RepoName:    "repo1",
+			ProjectName: "project1",
+			MinCoverage: pgtype.Float8{Float64: 80, Valid: true},
+			PathRules:   []byte(`[{"pattern":"app/**","min_coverage":90}]`),
+			UpdatedAt:   pgtype.Timestamptz{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		}, nil)
+
+		err := router.PutGatePolicy(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"repo_name": "repo1",
+			"project_name": "project1",
+			"min_coverage": 80,
+			"max_drop": null,
+			"min_patch_coverage": null,
+			"paths": [{"pattern": "app/**", "min_coverage": 90}],
+			"updated_at": "2024-01-01T00:00:00Z"
+		}`, rec.Body.String())
+	})
+
+	t.Run("RejectsInvalidPolicy", func(t *testing.T) {
+		router, _, c, rec := setup(http.MethodPut, `{"max_drop": -1, "paths": [{"pattern": "app/[a-", "min_coverage": 90}]}`)
+
+		err := router.PutGatePolicy(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "max_drop")
+		assert.Contains(t, rec.Body.String(), "paths[0].pattern")
+	})
+
+	t.Run("ReturnsNotFoundWithoutPolicy", func(t *testing.T) {
+		router, mockDB, c, _ := setup(http.MethodGet, "")
+		mockDB.On("GetGatePolicy", mock.Anything, mock.Anything).Return(data.GatePolicy{}, pgx.ErrNoRows)
+
+		err := router.GetGatePolicy(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+
+	t.Run("DeletesPolicy", func(t *testing.T) {
+		router, mockDB, c, rec := setup(http.MethodDelete, "")
+		mockDB.On("DeleteGatePolicy", mock.Anything, data.DeleteGatePolicyParams{
+			RepoName: "repo1", ProjectName: "project1",
+		}).Return(int64(1), nil)
+
+		err := router.DeleteGatePolicy(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
+
+func TestBadgeSettings(t *testing.T) {
+	setup := func(method, body string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(method, "/api/v1/repos/repo1/projects/project1/badge_settings", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName")
+		c.SetParamValues("repo1", "project1")
+
+		return router, mockDB, c, rec
+	}
+
+	t.Run("StoresNormalizedScale", func(t *testing.T) {
+		router, mockDB, c, rec := setup(http.MethodPut, `{"color_scale": "red:60.0, yellow:80,green"}`)
+		mockDB.On("UpsertBadgeSettings", mock.Anything, data.UpsertBadgeSettingsParams{
+			RepoName:    "repo1",
+			ProjectName: "project1",
+			ColorScale:  "red:60,yellow:80,green",
+		}).Return(data.BadgeSetting{
+			RepoName:    "repo1",
+			ProjectName: "project1",
+			ColorScale:  "red:60,yellow:80,green",
+			UpdatedAt:   pgtype.Timestamptz{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		}, nil)
+
+		err := router.PutBadgeSettings(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"repo_name": "repo1",
+			"project_name": "project1",
+			"color_scale": "red:60,yellow:80,green",
+			"updated_at": "2024-01-01T00:00:00Z"
+		}`, rec.Body.String())
+	})
+
+	t.Run("RejectsInvalidScale", func(t *testing.T) {
+		router, _, c, rec := setup(http.MethodPut, `{"color_scale": "red:80,yellow:60,green"}`)
+
+		err := router.PutBadgeSettings(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "color_scale")
+	})
+
+	t.Run("ReturnsNotFoundWithoutSettings", func(t *testing.T) {
+		router, mockDB, c, _ := setup(http.MethodDelete, "")
+		mockDB.On("DeleteBadgeSettings", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+		err := router.DeleteBadgeSettings(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
+
+func TestExclusionRules(t *testing.T) {
+	setup := func(method, target, body string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := httptest.NewRequest(method, "/api/v1/repos/repo1/projects/project1/exclusion_rules"+target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName")
+		c.SetParamValues("repo1", "project1")
+
+		return router, mockDB, c, rec
+	}
+
+	createdAt := pgtype.Timestamptz{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	rule := data.ExclusionRule{ID: 1, RepoName: "repo1", ProjectName: "project1", Pattern: "**/*.sql.go", CreatedAt: createdAt}
+
+	t.Run("ListsRules", func(t *testing.T) {
+		router, mockDB, c, rec := setup(http.MethodGet, "", "")
+		mockDB.On("ListExclusionRules", mock.Anything, data.ListExclusionRulesParams{
+			RepoName: "repo1", ProjectName: "project1",
+		}).Return([]data.ExclusionRule{rule}, nil)
+
+		err := router.ListExclusionRules(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[{"id": 1, "pattern": "**/*.sql.go", "created_at": "2024-01-01T00:00:00Z"}]`, rec.Body.String())
+	})
+
+	t.Run("CreatesRule", func(t *testing.T) {
+		router, mockDB, c, rec := setup(http.MethodPost, "", `{"pattern": "**/*.sql.go"}`)
+		mockDB.On("InsertExclusionRule", mock.Anything, data.InsertExclusionRuleParams{
+			RepoName: "repo1", ProjectName: "project1", Pattern: "**/*.sql.go",
+		}).Return(rule, nil)
+
+		err := router.PostExclusionRule(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"id": 1, "pattern": "**/*.sql.go", "created_at": "2024-01-01T00:00:00Z"}`, rec.Body.String())
+	})
+
+	t.Run("RejectsInvalidPattern", func(t *testing.T) {
+		router, _, c, rec := setup(http.MethodPost, "", `{"pattern": "app/[a-"}`)
+
+		err := router.PostExclusionRule(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "valid glob")
+	})
+
+	t.Run("RecomputesHistoryWithNewRule", func(t *testing.T) {
+		profile := "mode: set\nmain.go:3.13,5.2 3 1\ndb/queries.sql.go:7.13,9.2 1 0\n"
+		report, err := coverage.Parse([]byte(profile), coverage.FormatGo)
+		require.NoError(t, err)
+		rawData, err := json.Marshal(report)
+		require.NoError(t, err)
+
+		router, mockDB, c, rec := setup(http.MethodPost, "?recompute=true", `{"pattern": "**/*.sql.go"}`)
+		mockDB.On("InsertExclusionRule", mock.Anything, mock.Anything).Return(rule, nil)
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{rule}, nil)
+		mockDB.On("ListStaleProjectCoverage", mock.Anything, data.ListStaleProjectCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", Limit: recomputeBatchSize,
+			RulesVersion: rulesVersion([]string{"**/*.sql.go"}),
+		}).Return([]data.Coverage{
+			{ID: 7, RepoName: "repo1", ProjectName: "project1", BranchName: "main", Commit: "abc", Kind: "unit",
+				Format: "go", RawData: rawData, CoverageDate: createdAt},
+			{ID: 8, Format: "go", RawData: []byte("not json")},
+		}, nil)
+		mockDB.On("GetCoverageConfig", mock.Anything, int32(7)).Return("", pgx.ErrNoRows)
+		mockDB.On("UpsertCoverage", mock.Anything, mock.MatchedBy(func(params data.UpsertCoverageParams) bool {
+			return params.Commit == "abc" &&
+				params.RulesVersion == rulesVersion([]string{"**/*.sql.go"}) &&
+				params.Coverage == 100.0 &&
+				params.OriginalCoverage == 75.0 &&
+				params.OriginalLinesTotal == 6 &&
+				string(params.RawData) == string(rawData)
+		}), mock.MatchedBy(func(report *coverage.Report) bool {
+			return len(report.Files) == 1 && report.Files["main.go"] != nil
+		})).Return(data.Coverage{ID: 7}, nil)
+
+		err = router.PostExclusionRule(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("ReportsUnparsableReports", func(t *testing.T) {
+		router, mockDB, c, rec := setup(http.MethodPost, "/recompute", "")
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{}, nil)
+		mockDB.On("ListStaleProjectCoverage", mock.Anything, mock.Anything).Return([]data.Coverage{
+			{ID: 8, BranchName: "main", Commit: "abc", Kind: "unit", Format: "go", RawData: []byte("not json")},
+		}, nil)
+
+		err := router.RecomputeCoverage(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"recomputed": 0,
+			"failed": [{
+				"coverage_id": 8,
+				"branch_name": "main",
+				"commit": "abc",
+				"kind": "unit",
+				"error": "failed to decode report: invalid character 'o' in literal null (expecting 'u')"
+			}]
+		}`, rec.Body.String())
+	})
+
+	t.Run("CountsStaleReports", func(t *testing.T) {
+		router, mockDB, c, rec := setup(http.MethodGet, "/recompute", "")
+		c.Request().ContentLength = 0 // Required for echo to parse the request body correctly
+		mockDB.On("ListExclusionRules", mock.Anything, mock.Anything).Return([]data.ExclusionRule{rule}, nil)
+		mockDB.On("CountStaleProjectCoverage", mock.Anything, data.CountStaleProjectCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", RulesVersion: rulesVersion([]string{"**/*.sql.go"}),
+		}).Return(int64(3), nil)
+
+		err := router.GetRecomputeStatus(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"stale": 3}`, rec.Body.String())
+	})
+
+	t.Run("ReturnsNotFoundForUnknownRule", func(t *testing.T) {
+		router, mockDB, c, _ := setup(http.MethodDelete, "/2", "")
+		c.SetParamNames("repoName", "projectName", "ruleID")
+		c.SetParamValues("repo1", "project1", "2")
+		mockDB.On("DeleteExclusionRule", mock.Anything, data.DeleteExclusionRuleParams{
+			ID: 2, RepoName: "repo1", ProjectName: "project1",
+		}).Return(int64(0), nil)
+
+		err := router.DeleteExclusionRule(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
+
+func TestRulesVersion(t *testing.T) {
+	assert.Equal(t, "", rulesVersion(nil))
+	assert.Equal(t, rulesVersion([]string{"a/**", "b/"}), rulesVersion([]string{"b/", "a/**"}))
+	assert.NotEqual(t, rulesVersion([]string{"a/**"}), rulesVersion([]string{"a/**", "b/"}))
+	assert.NotEqual(t, rulesVersion([]string{"ab"}), rulesVersion([]string{"a", "b"}))
+}
+
+func TestPostGate(t *testing.T) {
+	const (
+		commit     = "0123456789abcdef0123456789abcdef01234567"
+		baseCommit = "89abcdef0123456789abcdef0123456789abcdef"
+		gitDiff    = "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -3,2 +3,4 @@\n a\n+b\n+c\n d\n"
+	)
+
+	setup := func(query string, files map[string]string) (*Router, *mocks.Repository, echo.Context, *httptest.ResponseRecorder) {
+		mockDB := mocks.NewRepository(t)
+		router := NewAPIV1Router(echo.New(), mockDB)
+		req := newCoverageUploadRequest(
+			t, "/api/v1/repos/repo1/projects/project1/branches/main/commits/0123456/gate?"+query, files, nil,
+		)
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName", "commit")
+		c.SetParamValues("repo1", "project1", "main", "0123456")
+
+		return router, mockDB, c, rec
+	}
+
+	mockCommit := func(mockDB *mocks.Repository) {
+		mockDB.On("ListCommitsByPrefix", mock.Anything, mock.Anything).Return([]string{commit}, nil)
+		mockDB.On("GetCommitCoverage", mock.Anything, data.GetCommitCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "main", Commit: commit, Kind: "unit",
+		}).Return(data.Coverage{
+			ID: 1, RepoName: "repo1", ProjectName: "project1", BranchName: "main", Commit: commit, Kind: "unit", Coverage: 75,
+		}, nil)
+	}
+
+	mockFiles := func(mockDB *mocks.Repository) {
+		mockDB.On("ListCoverageFiles", mock.Anything, int32(1)).Return([]data.CoverageFile{
+			{Path: "main.go", LinesCovered: 3, LinesTotal: 4},
+		}, nil)
+	}
+
+	t.Run("EvaluatesPolicy", func(t *testing.T) {
+		router, mockDB, c, rec := setup("base=89abcdef", map[string]string{"diff": gitDiff})
+		mockDB.On("GetGatePolicy", mock.Anything, data.GetGatePolicyParams{
+			RepoName: "repo1", ProjectName: "project1",
+		}).Return(data.GatePolicy{
+			MinCoverage:      pgtype.Float8{Float64: 70, Valid: true},
+			MaxDrop:          pgtype.Float8{Float64: 1, Valid: true},
+			MinPatchCoverage: pgtype.Float8{Float64: 50, Valid: true},
+			PathRules:        []byte(`[{"pattern":"*.go","min_coverage":70}]`),
+		}, nil)
+		mockCommit(mockDB)
+		mockFiles(mockDB)
+		mockDB.On("ListCoverageLineRanges", mock.Anything, mock.Anything).Return([]data.ListCoverageLineRangesRow{
+			{Path: "main.go", StartLine: 3, EndLine: 4, Hits: 1},
+			{Path: "main.go", StartLine: 5, EndLine: 5, Hits: 0},
+		}, nil)
+		mockDB.On("ListProjectCommitsByPrefix", mock.Anything, mock.Anything).Return([]string{baseCommit}, nil)
+		mockDB.On("GetProjectCommitCoverage", mock.Anything, data.GetProjectCommitCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", Commit: baseCommit, Kind: "unit",
+		}).Return(data.Coverage{ID: 2, Commit: baseCommit, Coverage: 80}, nil)
+
+		err := router.PostGate(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"commit": "`+commit+`",
+			"base_commit": "`+baseCommit+`",
+			"passed": false,
+			"reasons": [
+				{
+					"rule": "min_coverage", "status": "passed", "expected": 70, "actual": 75,
+					"message": "coverage is 75.00%, at least 70.00% required"
+				},
+				{
+					"rule": "max_drop", "status": "failed", "expected": 1, "actual": 5,
+					"message": "coverage dropped by 5.00% against the base, at most 1.00% allowed"
+				},
+				{
+					"rule": "min_patch_coverage", "status": "passed", "expected": 50, "actual": 50,
+					"message": "patch coverage is 50.00%, at least 50.00% required"
+				},
+				{
+					"rule": "path_min_coverage", "path": "*.go", "status": "passed", "expected": 70, "actual": 75,
+					"message": "coverage of *.go is 75.00%, at least 70.00% required"
+				}
+			]
+		}`, rec.Body.String())
+	})
+
+	t.Run("SkipsRulesWithoutBaseAndDiff", func(t *testing.T) {
+		router, mockDB, c, rec := setup("", nil)
+		mockDB.On("GetGatePolicy", mock.Anything, mock.Anything).Return(data.GatePolicy{
+			MaxDrop:          pgtype.Float8{Float64: 1, Valid: true},
+			MinPatchCoverage: pgtype.Float8{Float64: 50, Valid: true},
+			PathRules:        []byte(`[]`),
+		}, nil)
+		mockCommit(mockDB)
+		mockFiles(mockDB)
+
+		err := router.PostGate(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var gateResult GateSchema
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &gateResult))
+		assert.True(t, gateResult.Passed)
+		assert.Nil(t, gateResult.BaseCommit)
+		assert.Len(t, gateResult.Reasons, 2)
+	})
+
+	t.Run("UsesPolicyOfUploadedConfig", func(t *testing.T) {
+		router, mockDB, c, rec := setup("", nil)
+		mockCommit(mockDB)
+		mockFiles(mockDB)
+		mockDB.On("GetGatePolicy", mock.Anything, mock.Anything).Return(data.GatePolicy{}, pgx.ErrNoRows)
+		mockDB.On("GetCoverageConfig", mock.Anything, int32(1)).Return("gate:\n  min_coverage: 80\n", nil)
+
+		err := router.PostGate(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var gateResult GateSchema
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &gateResult))
+		assert.False(t, gateResult.Passed)
+		assert.Equal(t, "min_coverage", gateResult.Reasons[0].Rule)
+	})
+
+	t.Run("ReturnsNotFoundWithoutPolicy", func(t *testing.T) {
+		router, mockDB, c, _ := setup("", nil)
+		mockCommit(mockDB)
+		mockDB.On("GetGatePolicy", mock.Anything, mock.Anything).Return(data.GatePolicy{}, pgx.ErrNoRows)
+		mockDB.On("GetCoverageConfig", mock.Anything, int32(1)).Return("", pgx.ErrNoRows)
+
+		err := router.PostGate(c)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+
+	t.Run("RejectsInvalidBase", func(t *testing.T) {
+		router, _, c, rec := setup("base=main", nil)
+
+		err := router.PostGate(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 	data "goverage/data"
+	coverage "goverage/internal/coverage"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -22,28 +23,1174 @@ func (_m *Repository) EXPECT() *Repository_Expecter {
 	return &Repository_Expecter{mock: &_m.Mock}
 }
 
+// CountStaleProjectCoverage provides a mock function with given fields: ctx, params
+func (_m *Repository) CountStaleProjectCoverage(ctx context.Context, params data.CountStaleProjectCoverageParams) (int64, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountStaleProjectCoverage")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.CountStaleProjectCoverageParams) (int64, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.CountStaleProjectCoverageParams) int64); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.CountStaleProjectCoverageParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_CountStaleProjectCoverage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountStaleProjectCoverage'
+type Repository_CountStaleProjectCoverage_Call struct {
+	*mock.Call
+}
+
+// CountStaleProjectCoverage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.CountStaleProjectCoverageParams
+func (_e *Repository_Expecter) CountStaleProjectCoverage(ctx interface{}, params interface{}) *Repository_CountStaleProjectCoverage_Call {
+	return &Repository_CountStaleProjectCoverage_Call{Call: _e.mock.On("CountStaleProjectCoverage", ctx, params)}
+}
+
+func (_c *Repository_CountStaleProjectCoverage_Call) Run(run func(ctx context.Context, params data.CountStaleProjectCoverageParams)) *Repository_CountStaleProjectCoverage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.CountStaleProjectCoverageParams))
+	})
+	return _c
+}
+
+func (_c *Repository_CountStaleProjectCoverage_Call) Return(_a0 int64, _a1 error) *Repository_CountStaleProjectCoverage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_CountStaleProjectCoverage_Call) RunAndReturn(run func(context.Context, data.CountStaleProjectCoverageParams) (int64, error)) *Repository_CountStaleProjectCoverage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteBadgeSettings provides a mock function with given fields: ctx, params
+func (_m *Repository) DeleteBadgeSettings(ctx context.Context, params data.DeleteBadgeSettingsParams) (int64, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBadgeSettings")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.DeleteBadgeSettingsParams) (int64, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.DeleteBadgeSettingsParams) int64); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.DeleteBadgeSettingsParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_DeleteBadgeSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteBadgeSettings'
+type Repository_DeleteBadgeSettings_Call struct {
+	*mock.Call
+}
+
+// DeleteBadgeSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.DeleteBadgeSettingsParams
+func (_e *Repository_Expecter) DeleteBadgeSettings(ctx interface{}, params interface{}) *Repository_DeleteBadgeSettings_Call {
+	return &Repository_DeleteBadgeSettings_Call{Call: _e.mock.On("DeleteBadgeSettings", ctx, params)}
+}
+
+func (_c *Repository_DeleteBadgeSettings_Call) Run(run func(ctx context.Context, params data.DeleteBadgeSettingsParams)) *Repository_DeleteBadgeSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.DeleteBadgeSettingsParams))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteBadgeSettings_Call) Return(_a0 int64, _a1 error) *Repository_DeleteBadgeSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_DeleteBadgeSettings_Call) RunAndReturn(run func(context.Context, data.DeleteBadgeSettingsParams) (int64, error)) *Repository_DeleteBadgeSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExclusionRule provides a mock function with given fields: ctx, params
+func (_m *Repository) DeleteExclusionRule(ctx context.Context, params data.DeleteExclusionRuleParams) (int64, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExclusionRule")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.DeleteExclusionRuleParams) (int64, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.DeleteExclusionRuleParams) int64); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.DeleteExclusionRuleParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_DeleteExclusionRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExclusionRule'
+type Repository_DeleteExclusionRule_Call struct {
+	*mock.Call
+}
+
+// DeleteExclusionRule is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.DeleteExclusionRuleParams
+func (_e *Repository_Expecter) DeleteExclusionRule(ctx interface{}, params interface{}) *Repository_DeleteExclusionRule_Call {
+	return &Repository_DeleteExclusionRule_Call{Call: _e.mock.On("DeleteExclusionRule", ctx, params)}
+}
+
+func (_c *Repository_DeleteExclusionRule_Call) Run(run func(ctx context.Context, params data.DeleteExclusionRuleParams)) *Repository_DeleteExclusionRule_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.DeleteExclusionRuleParams))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteExclusionRule_Call) Return(_a0 int64, _a1 error) *Repository_DeleteExclusionRule_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_DeleteExclusionRule_Call) RunAndReturn(run func(context.Context, data.DeleteExclusionRuleParams) (int64, error)) *Repository_DeleteExclusionRule_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteGatePolicy provides a mock function with given fields: ctx, params
+func (_m *Repository) DeleteGatePolicy(ctx context.Context, params data.DeleteGatePolicyParams) (int64, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteGatePolicy")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.DeleteGatePolicyParams) (int64, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.DeleteGatePolicyParams) int64); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.DeleteGatePolicyParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_DeleteGatePolicy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteGatePolicy'
+type Repository_DeleteGatePolicy_Call struct {
+	*mock.Call
+}
+
+// DeleteGatePolicy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.DeleteGatePolicyParams
+func (_e *Repository_Expecter) DeleteGatePolicy(ctx interface{}, params interface{}) *Repository_DeleteGatePolicy_Call {
+	return &Repository_DeleteGatePolicy_Call{Call: _e.mock.On("DeleteGatePolicy", ctx, params)}
+}
+
+func (_c *Repository_DeleteGatePolicy_Call) Run(run func(ctx context.Context, params data.DeleteGatePolicyParams)) *Repository_DeleteGatePolicy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.DeleteGatePolicyParams))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteGatePolicy_Call) Return(_a0 int64, _a1 error) *Repository_DeleteGatePolicy_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_DeleteGatePolicy_Call) RunAndReturn(run func(context.Context, data.DeleteGatePolicyParams) (int64, error)) *Repository_DeleteGatePolicy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBadgeSettings provides a mock function with given fields: ctx, params
+func (_m *Repository) GetBadgeSettings(ctx context.Context, params data.GetBadgeSettingsParams) (data.BadgeSetting, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBadgeSettings")
+	}
+
+	var r0 data.BadgeSetting
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetBadgeSettingsParams) (data.BadgeSetting, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetBadgeSettingsParams) data.BadgeSetting); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(data.BadgeSetting)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.GetBadgeSettingsParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetBadgeSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBadgeSettings'
+type Repository_GetBadgeSettings_Call struct {
+	*mock.Call
+}
+
+// GetBadgeSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.GetBadgeSettingsParams
+func (_e *Repository_Expecter) GetBadgeSettings(ctx interface{}, params interface{}) *Repository_GetBadgeSettings_Call {
+	return &Repository_GetBadgeSettings_Call{Call: _e.mock.On("GetBadgeSettings", ctx, params)}
+}
+
+func (_c *Repository_GetBadgeSettings_Call) Run(run func(ctx context.Context, params data.GetBadgeSettingsParams)) *Repository_GetBadgeSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.GetBadgeSettingsParams))
+	})
+	return _c
+}
+
+func (_c *Repository_GetBadgeSettings_Call) Return(_a0 data.BadgeSetting, _a1 error) *Repository_GetBadgeSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetBadgeSettings_Call) RunAndReturn(run func(context.Context, data.GetBadgeSettingsParams) (data.BadgeSetting, error)) *Repository_GetBadgeSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCommitCoverage provides a mock function with given fields: ctx, params
+func (_m *Repository) GetCommitCoverage(ctx context.Context, params data.GetCommitCoverageParams) (data.Coverage, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCommitCoverage")
+	}
+
+	var r0 data.Coverage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetCommitCoverageParams) (data.Coverage, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetCommitCoverageParams) data.Coverage); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(data.Coverage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.GetCommitCoverageParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetCommitCoverage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCommitCoverage'
+type Repository_GetCommitCoverage_Call struct {
+	*mock.Call
+}
+
+// GetCommitCoverage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.GetCommitCoverageParams
+func (_e *Repository_Expecter) GetCommitCoverage(ctx interface{}, params interface{}) *Repository_GetCommitCoverage_Call {
+	return &Repository_GetCommitCoverage_Call{Call: _e.mock.On("GetCommitCoverage", ctx, params)}
+}
+
+func (_c *Repository_GetCommitCoverage_Call) Run(run func(ctx context.Context, params data.GetCommitCoverageParams)) *Repository_GetCommitCoverage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.GetCommitCoverageParams))
+	})
+	return _c
+}
+
+func (_c *Repository_GetCommitCoverage_Call) Return(_a0 data.Coverage, _a1 error) *Repository_GetCommitCoverage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetCommitCoverage_Call) RunAndReturn(run func(context.Context, data.GetCommitCoverageParams) (data.Coverage, error)) *Repository_GetCommitCoverage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCoverageConfig provides a mock function with given fields: ctx, coverageID
+func (_m *Repository) GetCoverageConfig(ctx context.Context, coverageID int32) (string, error) {
+	ret := _m.Called(ctx, coverageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCoverageConfig")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int32) (string, error)); ok {
+		return rf(ctx, coverageID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int32) string); ok {
+		r0 = rf(ctx, coverageID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, coverageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetCoverageConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCoverageConfig'
+type Repository_GetCoverageConfig_Call struct {
+	*mock.Call
+}
+
+// GetCoverageConfig is a helper method to define mock.On call
+//   - ctx context.Context
+//   - coverageID int32
+func (_e *Repository_Expecter) GetCoverageConfig(ctx interface{}, coverageID interface{}) *Repository_GetCoverageConfig_Call {
+	return &Repository_GetCoverageConfig_Call{Call: _e.mock.On("GetCoverageConfig", ctx, coverageID)}
+}
+
+func (_c *Repository_GetCoverageConfig_Call) Run(run func(ctx context.Context, coverageID int32)) *Repository_GetCoverageConfig_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int32))
+	})
+	return _c
+}
+
+func (_c *Repository_GetCoverageConfig_Call) Return(_a0 string, _a1 error) *Repository_GetCoverageConfig_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetCoverageConfig_Call) RunAndReturn(run func(context.Context, int32) (string, error)) *Repository_GetCoverageConfig_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetCoverageData provides a mock function with given fields: ctx, params
 func (_m *Repository) GetCoverageData(ctx context.Context, params data.GetCoverageDataParams) ([]byte, error) {
 	ret := _m.Called(ctx, params)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetCoverageData")
+		panic("no return value specified for GetCoverageData")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetCoverageDataParams) ([]byte, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetCoverageDataParams) []byte); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.GetCoverageDataParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetCoverageData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCoverageData'
+type Repository_GetCoverageData_Call struct {
+	*mock.Call
+}
+
+// GetCoverageData is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.GetCoverageDataParams
+func (_e *Repository_Expecter) GetCoverageData(ctx interface{}, params interface{}) *Repository_GetCoverageData_Call {
+	return &Repository_GetCoverageData_Call{Call: _e.mock.On("GetCoverageData", ctx, params)}
+}
+
+func (_c *Repository_GetCoverageData_Call) Run(run func(ctx context.Context, params data.GetCoverageDataParams)) *Repository_GetCoverageData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.GetCoverageDataParams))
+	})
+	return _c
+}
+
+func (_c *Repository_GetCoverageData_Call) Return(_a0 []byte, _a1 error) *Repository_GetCoverageData_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetCoverageData_Call) RunAndReturn(run func(context.Context, data.GetCoverageDataParams) ([]byte, error)) *Repository_GetCoverageData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetGatePolicy provides a mock function with given fields: ctx, params
+func (_m *Repository) GetGatePolicy(ctx context.Context, params data.GetGatePolicyParams) (data.GatePolicy, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGatePolicy")
+	}
+
+	var r0 data.GatePolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetGatePolicyParams) (data.GatePolicy, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetGatePolicyParams) data.GatePolicy); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(data.GatePolicy)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.GetGatePolicyParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetGatePolicy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetGatePolicy'
+type Repository_GetGatePolicy_Call struct {
+	*mock.Call
+}
+
+// GetGatePolicy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.GetGatePolicyParams
+func (_e *Repository_Expecter) GetGatePolicy(ctx interface{}, params interface{}) *Repository_GetGatePolicy_Call {
+	return &Repository_GetGatePolicy_Call{Call: _e.mock.On("GetGatePolicy", ctx, params)}
+}
+
+func (_c *Repository_GetGatePolicy_Call) Run(run func(ctx context.Context, params data.GetGatePolicyParams)) *Repository_GetGatePolicy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.GetGatePolicyParams))
+	})
+	return _c
+}
+
+func (_c *Repository_GetGatePolicy_Call) Return(_a0 data.GatePolicy, _a1 error) *Repository_GetGatePolicy_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetGatePolicy_Call) RunAndReturn(run func(context.Context, data.GetGatePolicyParams) (data.GatePolicy, error)) *Repository_GetGatePolicy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProjectCommitCoverage provides a mock function with given fields: ctx, params
+func (_m *Repository) GetProjectCommitCoverage(ctx context.Context, params data.GetProjectCommitCoverageParams) (data.Coverage, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProjectCommitCoverage")
+	}
+
+	var r0 data.Coverage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetProjectCommitCoverageParams) (data.Coverage, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetProjectCommitCoverageParams) data.Coverage); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(data.Coverage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.GetProjectCommitCoverageParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetProjectCommitCoverage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProjectCommitCoverage'
+type Repository_GetProjectCommitCoverage_Call struct {
+	*mock.Call
+}
+
+// GetProjectCommitCoverage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.GetProjectCommitCoverageParams
+func (_e *Repository_Expecter) GetProjectCommitCoverage(ctx interface{}, params interface{}) *Repository_GetProjectCommitCoverage_Call {
+	return &Repository_GetProjectCommitCoverage_Call{Call: _e.mock.On("GetProjectCommitCoverage", ctx, params)}
+}
+
+func (_c *Repository_GetProjectCommitCoverage_Call) Run(run func(ctx context.Context, params data.GetProjectCommitCoverageParams)) *Repository_GetProjectCommitCoverage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.GetProjectCommitCoverageParams))
+	})
+	return _c
+}
+
+func (_c *Repository_GetProjectCommitCoverage_Call) Return(_a0 data.Coverage, _a1 error) *Repository_GetProjectCommitCoverage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetProjectCommitCoverage_Call) RunAndReturn(run func(context.Context, data.GetProjectCommitCoverageParams) (data.Coverage, error)) *Repository_GetProjectCommitCoverage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRecentCoverage provides a mock function with given fields: ctx, params
+func (_m *Repository) GetRecentCoverage(ctx context.Context, params data.GetRecentCoverageParams) (data.Coverage, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRecentCoverage")
+	}
+
+	var r0 data.Coverage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetRecentCoverageParams) (data.Coverage, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetRecentCoverageParams) data.Coverage); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(data.Coverage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.GetRecentCoverageParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetRecentCoverage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRecentCoverage'
+type Repository_GetRecentCoverage_Call struct {
+	*mock.Call
+}
+
+// GetRecentCoverage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.GetRecentCoverageParams
+func (_e *Repository_Expecter) GetRecentCoverage(ctx interface{}, params interface{}) *Repository_GetRecentCoverage_Call {
+	return &Repository_GetRecentCoverage_Call{Call: _e.mock.On("GetRecentCoverage", ctx, params)}
+}
+
+func (_c *Repository_GetRecentCoverage_Call) Run(run func(ctx context.Context, params data.GetRecentCoverageParams)) *Repository_GetRecentCoverage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.GetRecentCoverageParams))
+	})
+	return _c
+}
+
+func (_c *Repository_GetRecentCoverage_Call) Return(_a0 data.Coverage, _a1 error) *Repository_GetRecentCoverage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetRecentCoverage_Call) RunAndReturn(run func(context.Context, data.GetRecentCoverageParams) (data.Coverage, error)) *Repository_GetRecentCoverage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertExclusionRule provides a mock function with given fields: ctx, params
+func (_m *Repository) InsertExclusionRule(ctx context.Context, params data.InsertExclusionRuleParams) (data.ExclusionRule, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertExclusionRule")
+	}
+
+	var r0 data.ExclusionRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.InsertExclusionRuleParams) (data.ExclusionRule, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.InsertExclusionRuleParams) data.ExclusionRule); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(data.ExclusionRule)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.InsertExclusionRuleParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_InsertExclusionRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertExclusionRule'
+type Repository_InsertExclusionRule_Call struct {
+	*mock.Call
+}
+
+// InsertExclusionRule is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.InsertExclusionRuleParams
+func (_e *Repository_Expecter) InsertExclusionRule(ctx interface{}, params interface{}) *Repository_InsertExclusionRule_Call {
+	return &Repository_InsertExclusionRule_Call{Call: _e.mock.On("InsertExclusionRule", ctx, params)}
+}
+
+func (_c *Repository_InsertExclusionRule_Call) Run(run func(ctx context.Context, params data.InsertExclusionRuleParams)) *Repository_InsertExclusionRule_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.InsertExclusionRuleParams))
+	})
+	return _c
+}
+
+func (_c *Repository_InsertExclusionRule_Call) Return(_a0 data.ExclusionRule, _a1 error) *Repository_InsertExclusionRule_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_InsertExclusionRule_Call) RunAndReturn(run func(context.Context, data.InsertExclusionRuleParams) (data.ExclusionRule, error)) *Repository_InsertExclusionRule_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InsertFileRenames provides a mock function with given fields: ctx, params
+func (_m *Repository) InsertFileRenames(ctx context.Context, params data.InsertFileRenamesParams) error {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertFileRenames")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.InsertFileRenamesParams) error); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_InsertFileRenames_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InsertFileRenames'
+type Repository_InsertFileRenames_Call struct {
+	*mock.Call
+}
+
+// InsertFileRenames is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.InsertFileRenamesParams
+func (_e *Repository_Expecter) InsertFileRenames(ctx interface{}, params interface{}) *Repository_InsertFileRenames_Call {
+	return &Repository_InsertFileRenames_Call{Call: _e.mock.On("InsertFileRenames", ctx, params)}
+}
+
+func (_c *Repository_InsertFileRenames_Call) Run(run func(ctx context.Context, params data.InsertFileRenamesParams)) *Repository_InsertFileRenames_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.InsertFileRenamesParams))
+	})
+	return _c
+}
+
+func (_c *Repository_InsertFileRenames_Call) Return(_a0 error) *Repository_InsertFileRenames_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_InsertFileRenames_Call) RunAndReturn(run func(context.Context, data.InsertFileRenamesParams) error) *Repository_InsertFileRenames_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBranches provides a mock function with given fields: ctx, params
+func (_m *Repository) ListBranches(ctx context.Context, params data.ListBranchesParams) ([]string, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBranches")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListBranchesParams) ([]string, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListBranchesParams) []string); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListBranchesParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListBranches_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBranches'
+type Repository_ListBranches_Call struct {
+	*mock.Call
+}
+
+// ListBranches is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.ListBranchesParams
+func (_e *Repository_Expecter) ListBranches(ctx interface{}, params interface{}) *Repository_ListBranches_Call {
+	return &Repository_ListBranches_Call{Call: _e.mock.On("ListBranches", ctx, params)}
+}
+
+func (_c *Repository_ListBranches_Call) Run(run func(ctx context.Context, params data.ListBranchesParams)) *Repository_ListBranches_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.ListBranchesParams))
+	})
+	return _c
+}
+
+func (_c *Repository_ListBranches_Call) Return(_a0 []string, _a1 error) *Repository_ListBranches_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListBranches_Call) RunAndReturn(run func(context.Context, data.ListBranchesParams) ([]string, error)) *Repository_ListBranches_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCommitsByPrefix provides a mock function with given fields: ctx, params
+func (_m *Repository) ListCommitsByPrefix(ctx context.Context, params data.ListCommitsByPrefixParams) ([]string, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCommitsByPrefix")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCommitsByPrefixParams) ([]string, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCommitsByPrefixParams) []string); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListCommitsByPrefixParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListCommitsByPrefix_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCommitsByPrefix'
+type Repository_ListCommitsByPrefix_Call struct {
+	*mock.Call
+}
+
+// ListCommitsByPrefix is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.ListCommitsByPrefixParams
+func (_e *Repository_Expecter) ListCommitsByPrefix(ctx interface{}, params interface{}) *Repository_ListCommitsByPrefix_Call {
+	return &Repository_ListCommitsByPrefix_Call{Call: _e.mock.On("ListCommitsByPrefix", ctx, params)}
+}
+
+func (_c *Repository_ListCommitsByPrefix_Call) Run(run func(ctx context.Context, params data.ListCommitsByPrefixParams)) *Repository_ListCommitsByPrefix_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.ListCommitsByPrefixParams))
+	})
+	return _c
+}
+
+func (_c *Repository_ListCommitsByPrefix_Call) Return(_a0 []string, _a1 error) *Repository_ListCommitsByPrefix_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListCommitsByPrefix_Call) RunAndReturn(run func(context.Context, data.ListCommitsByPrefixParams) ([]string, error)) *Repository_ListCommitsByPrefix_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCoverageFiles provides a mock function with given fields: ctx, coverageID
+func (_m *Repository) ListCoverageFiles(ctx context.Context, coverageID int32) ([]data.CoverageFile, error) {
+	ret := _m.Called(ctx, coverageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCoverageFiles")
+	}
+
+	var r0 []data.CoverageFile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int32) ([]data.CoverageFile, error)); ok {
+		return rf(ctx, coverageID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int32) []data.CoverageFile); ok {
+		r0 = rf(ctx, coverageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.CoverageFile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, coverageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListCoverageFiles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCoverageFiles'
+type Repository_ListCoverageFiles_Call struct {
+	*mock.Call
+}
+
+// ListCoverageFiles is a helper method to define mock.On call
+//   - ctx context.Context
+//   - coverageID int32
+func (_e *Repository_Expecter) ListCoverageFiles(ctx interface{}, coverageID interface{}) *Repository_ListCoverageFiles_Call {
+	return &Repository_ListCoverageFiles_Call{Call: _e.mock.On("ListCoverageFiles", ctx, coverageID)}
+}
+
+func (_c *Repository_ListCoverageFiles_Call) Run(run func(ctx context.Context, coverageID int32)) *Repository_ListCoverageFiles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int32))
+	})
+	return _c
+}
+
+func (_c *Repository_ListCoverageFiles_Call) Return(_a0 []data.CoverageFile, _a1 error) *Repository_ListCoverageFiles_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListCoverageFiles_Call) RunAndReturn(run func(context.Context, int32) ([]data.CoverageFile, error)) *Repository_ListCoverageFiles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCoverageLineRanges provides a mock function with given fields: ctx, params
+func (_m *Repository) ListCoverageLineRanges(ctx context.Context, params data.ListCoverageLineRangesParams) ([]data.ListCoverageLineRangesRow, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCoverageLineRanges")
 	}
 
-	var r0 []byte
+	var r0 []data.ListCoverageLineRangesRow
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, data.GetCoverageDataParams) ([]byte, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageLineRangesParams) ([]data.ListCoverageLineRangesRow, error)); ok {
 		return rf(ctx, params)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, data.GetCoverageDataParams) []byte); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageLineRangesParams) []data.ListCoverageLineRangesRow); ok {
 		r0 = rf(ctx, params)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).([]data.ListCoverageLineRangesRow)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, data.GetCoverageDataParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListCoverageLineRangesParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListCoverageLineRanges_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCoverageLineRanges'
+type Repository_ListCoverageLineRanges_Call struct {
+	*mock.Call
+}
+
+// ListCoverageLineRanges is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.ListCoverageLineRangesParams
+func (_e *Repository_Expecter) ListCoverageLineRanges(ctx interface{}, params interface{}) *Repository_ListCoverageLineRanges_Call {
+	return &Repository_ListCoverageLineRanges_Call{Call: _e.mock.On("ListCoverageLineRanges", ctx, params)}
+}
+
+func (_c *Repository_ListCoverageLineRanges_Call) Run(run func(ctx context.Context, params data.ListCoverageLineRangesParams)) *Repository_ListCoverageLineRanges_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.ListCoverageLineRangesParams))
+	})
+	return _c
+}
+
+func (_c *Repository_ListCoverageLineRanges_Call) Return(_a0 []data.ListCoverageLineRangesRow, _a1 error) *Repository_ListCoverageLineRanges_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListCoverageLineRanges_Call) RunAndReturn(run func(context.Context, data.ListCoverageLineRangesParams) ([]data.ListCoverageLineRangesRow, error)) *Repository_ListCoverageLineRanges_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCoverageShards provides a mock function with given fields: ctx, params
+func (_m *Repository) ListCoverageShards(ctx context.Context, params data.ListCoverageShardsParams) ([]data.CoverageShard, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCoverageShards")
+	}
+
+	var r0 []data.CoverageShard
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageShardsParams) ([]data.CoverageShard, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageShardsParams) []data.CoverageShard); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.CoverageShard)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListCoverageShardsParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListCoverageShards_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCoverageShards'
+type Repository_ListCoverageShards_Call struct {
+	*mock.Call
+}
+
+// ListCoverageShards is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.ListCoverageShardsParams
+func (_e *Repository_Expecter) ListCoverageShards(ctx interface{}, params interface{}) *Repository_ListCoverageShards_Call {
+	return &Repository_ListCoverageShards_Call{Call: _e.mock.On("ListCoverageShards", ctx, params)}
+}
+
+func (_c *Repository_ListCoverageShards_Call) Run(run func(ctx context.Context, params data.ListCoverageShardsParams)) *Repository_ListCoverageShards_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.ListCoverageShardsParams))
+	})
+	return _c
+}
+
+func (_c *Repository_ListCoverageShards_Call) Return(_a0 []data.CoverageShard, _a1 error) *Repository_ListCoverageShards_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListCoverageShards_Call) RunAndReturn(run func(context.Context, data.ListCoverageShardsParams) ([]data.CoverageShard, error)) *Repository_ListCoverageShards_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCoverageSummary provides a mock function with given fields: ctx, params
+func (_m *Repository) ListCoverageSummary(ctx context.Context, params data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCoverageSummary")
+	}
+
+	var r0 []data.ListCoverageSummaryRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageSummaryParams) []data.ListCoverageSummaryRow); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.ListCoverageSummaryRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListCoverageSummaryParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListCoverageSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCoverageSummary'
+type Repository_ListCoverageSummary_Call struct {
+	*mock.Call
+}
+
+// ListCoverageSummary is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.ListCoverageSummaryParams
+func (_e *Repository_Expecter) ListCoverageSummary(ctx interface{}, params interface{}) *Repository_ListCoverageSummary_Call {
+	return &Repository_ListCoverageSummary_Call{Call: _e.mock.On("ListCoverageSummary", ctx, params)}
+}
+
+func (_c *Repository_ListCoverageSummary_Call) Run(run func(ctx context.Context, params data.ListCoverageSummaryParams)) *Repository_ListCoverageSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.ListCoverageSummaryParams))
+	})
+	return _c
+}
+
+func (_c *Repository_ListCoverageSummary_Call) Return(_a0 []data.ListCoverageSummaryRow, _a1 error) *Repository_ListCoverageSummary_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListCoverageSummary_Call) RunAndReturn(run func(context.Context, data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error)) *Repository_ListCoverageSummary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListExclusionRules provides a mock function with given fields: ctx, params
+func (_m *Repository) ListExclusionRules(ctx context.Context, params data.ListExclusionRulesParams) ([]data.ExclusionRule, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListExclusionRules")
+	}
+
+	var r0 []data.ExclusionRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListExclusionRulesParams) ([]data.ExclusionRule, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListExclusionRulesParams) []data.ExclusionRule); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.ExclusionRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListExclusionRulesParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListExclusionRules_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListExclusionRules'
+type Repository_ListExclusionRules_Call struct {
+	*mock.Call
+}
+
+// ListExclusionRules is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.ListExclusionRulesParams
+func (_e *Repository_Expecter) ListExclusionRules(ctx interface{}, params interface{}) *Repository_ListExclusionRules_Call {
+	return &Repository_ListExclusionRules_Call{Call: _e.mock.On("ListExclusionRules", ctx, params)}
+}
+
+func (_c *Repository_ListExclusionRules_Call) Run(run func(ctx context.Context, params data.ListExclusionRulesParams)) *Repository_ListExclusionRules_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.ListExclusionRulesParams))
+	})
+	return _c
+}
+
+func (_c *Repository_ListExclusionRules_Call) Return(_a0 []data.ExclusionRule, _a1 error) *Repository_ListExclusionRules_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListExclusionRules_Call) RunAndReturn(run func(context.Context, data.ListExclusionRulesParams) ([]data.ExclusionRule, error)) *Repository_ListExclusionRules_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListFileCoverageHistory provides a mock function with given fields: ctx, params
+func (_m *Repository) ListFileCoverageHistory(ctx context.Context, params data.ListFileCoverageHistoryParams) ([]data.ListFileCoverageHistoryRow, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFileCoverageHistory")
+	}
+
+	var r0 []data.ListFileCoverageHistoryRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListFileCoverageHistoryParams) ([]data.ListFileCoverageHistoryRow, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListFileCoverageHistoryParams) []data.ListFileCoverageHistoryRow); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.ListFileCoverageHistoryRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListFileCoverageHistoryParams) error); ok {
 		r1 = rf(ctx, params)
 	} else {
 		r1 = ret.Error(1)
@@ -52,55 +1199,57 @@ func (_m *Repository) GetCoverageData(ctx context.Context, params data.GetCovera
 	return r0, r1
 }
 
-// Repository_GetCoverageData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCoverageData'
-type Repository_GetCoverageData_Call struct {
+// Repository_ListFileCoverageHistory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFileCoverageHistory'
+type Repository_ListFileCoverageHistory_Call struct {
 	*mock.Call
 }
 
-// GetCoverageData is a helper method to define mock.On call
+// ListFileCoverageHistory is a helper method to define mock.On call
 //   - ctx context.Context
-//   - params data.GetCoverageDataParams
-func (_e *Repository_Expecter) GetCoverageData(ctx interface{}, params interface{}) *Repository_GetCoverageData_Call {
-	return &Repository_GetCoverageData_Call{Call: _e.mock.On("GetCoverageData", ctx, params)}
+//   - params data.ListFileCoverageHistoryParams
+func (_e *Repository_Expecter) ListFileCoverageHistory(ctx interface{}, params interface{}) *Repository_ListFileCoverageHistory_Call {
+	return &Repository_ListFileCoverageHistory_Call{Call: _e.mock.On("ListFileCoverageHistory", ctx, params)}
 }
 
-func (_c *Repository_GetCoverageData_Call) Run(run func(ctx context.Context, params data.GetCoverageDataParams)) *Repository_GetCoverageData_Call {
+func (_c *Repository_ListFileCoverageHistory_Call) Run(run func(ctx context.Context, params data.ListFileCoverageHistoryParams)) *Repository_ListFileCoverageHistory_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(data.GetCoverageDataParams))
+		run(args[0].(context.Context), args[1].(data.ListFileCoverageHistoryParams))
 	})
 	return _c
 }
 
-func (_c *Repository_GetCoverageData_Call) Return(_a0 []byte, _a1 error) *Repository_GetCoverageData_Call {
+func (_c *Repository_ListFileCoverageHistory_Call) Return(_a0 []data.ListFileCoverageHistoryRow, _a1 error) *Repository_ListFileCoverageHistory_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_GetCoverageData_Call) RunAndReturn(run func(context.Context, data.GetCoverageDataParams) ([]byte, error)) *Repository_GetCoverageData_Call {
+func (_c *Repository_ListFileCoverageHistory_Call) RunAndReturn(run func(context.Context, data.ListFileCoverageHistoryParams) ([]data.ListFileCoverageHistoryRow, error)) *Repository_ListFileCoverageHistory_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetRecentCoverage provides a mock function with given fields: ctx, params
-func (_m *Repository) GetRecentCoverage(ctx context.Context, params data.GetRecentCoverageParams) (data.Coverage, error) {
+// ListFileRenames provides a mock function with given fields: ctx, params
+func (_m *Repository) ListFileRenames(ctx context.Context, params data.ListFileRenamesParams) ([]data.ListFileRenamesRow, error) {
 	ret := _m.Called(ctx, params)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetRecentCoverage")
+		panic("no return value specified for ListFileRenames")
 	}
 
-	var r0 data.Coverage
+	var r0 []data.ListFileRenamesRow
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, data.GetRecentCoverageParams) (data.Coverage, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListFileRenamesParams) ([]data.ListFileRenamesRow, error)); ok {
 		return rf(ctx, params)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, data.GetRecentCoverageParams) data.Coverage); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListFileRenamesParams) []data.ListFileRenamesRow); ok {
 		r0 = rf(ctx, params)
 	} else {
-		r0 = ret.Get(0).(data.Coverage)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.ListFileRenamesRow)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, data.GetRecentCoverageParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListFileRenamesParams) error); ok {
 		r1 = rf(ctx, params)
 	} else {
 		r1 = ret.Error(1)
@@ -109,57 +1258,57 @@ func (_m *Repository) GetRecentCoverage(ctx context.Context, params data.GetRece
 	return r0, r1
 }
 
-// Repository_GetRecentCoverage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRecentCoverage'
-type Repository_GetRecentCoverage_Call struct {
+// Repository_ListFileRenames_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFileRenames'
+type Repository_ListFileRenames_Call struct {
 	*mock.Call
 }
 
-// GetRecentCoverage is a helper method to define mock.On call
+// ListFileRenames is a helper method to define mock.On call
 //   - ctx context.Context
-//   - params data.GetRecentCoverageParams
-func (_e *Repository_Expecter) GetRecentCoverage(ctx interface{}, params interface{}) *Repository_GetRecentCoverage_Call {
-	return &Repository_GetRecentCoverage_Call{Call: _e.mock.On("GetRecentCoverage", ctx, params)}
+//   - params data.ListFileRenamesParams
+func (_e *Repository_Expecter) ListFileRenames(ctx interface{}, params interface{}) *Repository_ListFileRenames_Call {
+	return &Repository_ListFileRenames_Call{Call: _e.mock.On("ListFileRenames", ctx, params)}
 }
 
-func (_c *Repository_GetRecentCoverage_Call) Run(run func(ctx context.Context, params data.GetRecentCoverageParams)) *Repository_GetRecentCoverage_Call {
+func (_c *Repository_ListFileRenames_Call) Run(run func(ctx context.Context, params data.ListFileRenamesParams)) *Repository_ListFileRenames_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(data.GetRecentCoverageParams))
+		run(args[0].(context.Context), args[1].(data.ListFileRenamesParams))
 	})
 	return _c
 }
 
-func (_c *Repository_GetRecentCoverage_Call) Return(_a0 data.Coverage, _a1 error) *Repository_GetRecentCoverage_Call {
+func (_c *Repository_ListFileRenames_Call) Return(_a0 []data.ListFileRenamesRow, _a1 error) *Repository_ListFileRenames_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_GetRecentCoverage_Call) RunAndReturn(run func(context.Context, data.GetRecentCoverageParams) (data.Coverage, error)) *Repository_GetRecentCoverage_Call {
+func (_c *Repository_ListFileRenames_Call) RunAndReturn(run func(context.Context, data.ListFileRenamesParams) ([]data.ListFileRenamesRow, error)) *Repository_ListFileRenames_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListBranches provides a mock function with given fields: ctx, params
-func (_m *Repository) ListBranches(ctx context.Context, params data.ListBranchesParams) ([]string, error) {
+// ListLatestProjectCoverage provides a mock function with given fields: ctx, params
+func (_m *Repository) ListLatestProjectCoverage(ctx context.Context, params data.ListLatestProjectCoverageParams) ([]data.ListLatestProjectCoverageRow, error) {
 	ret := _m.Called(ctx, params)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListBranches")
+		panic("no return value specified for ListLatestProjectCoverage")
 	}
 
-	var r0 []string
+	var r0 []data.ListLatestProjectCoverageRow
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, data.ListBranchesParams) ([]string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListLatestProjectCoverageParams) ([]data.ListLatestProjectCoverageRow, error)); ok {
 		return rf(ctx, params)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, data.ListBranchesParams) []string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListLatestProjectCoverageParams) []data.ListLatestProjectCoverageRow); ok {
 		r0 = rf(ctx, params)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]string)
+			r0 = ret.Get(0).([]data.ListLatestProjectCoverageRow)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, data.ListBranchesParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListLatestProjectCoverageParams) error); ok {
 		r1 = rf(ctx, params)
 	} else {
 		r1 = ret.Error(1)
@@ -168,57 +1317,57 @@ func (_m *Repository) ListBranches(ctx context.Context, params data.ListBranches
 	return r0, r1
 }
 
-// Repository_ListBranches_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBranches'
-type Repository_ListBranches_Call struct {
+// Repository_ListLatestProjectCoverage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListLatestProjectCoverage'
+type Repository_ListLatestProjectCoverage_Call struct {
 	*mock.Call
 }
 
-// ListBranches is a helper method to define mock.On call
+// ListLatestProjectCoverage is a helper method to define mock.On call
 //   - ctx context.Context
-//   - params data.ListBranchesParams
-func (_e *Repository_Expecter) ListBranches(ctx interface{}, params interface{}) *Repository_ListBranches_Call {
-	return &Repository_ListBranches_Call{Call: _e.mock.On("ListBranches", ctx, params)}
+//   - params data.ListLatestProjectCoverageParams
+func (_e *Repository_Expecter) ListLatestProjectCoverage(ctx interface{}, params interface{}) *Repository_ListLatestProjectCoverage_Call {
+	return &Repository_ListLatestProjectCoverage_Call{Call: _e.mock.On("ListLatestProjectCoverage", ctx, params)}
 }
 
-func (_c *Repository_ListBranches_Call) Run(run func(ctx context.Context, params data.ListBranchesParams)) *Repository_ListBranches_Call {
+func (_c *Repository_ListLatestProjectCoverage_Call) Run(run func(ctx context.Context, params data.ListLatestProjectCoverageParams)) *Repository_ListLatestProjectCoverage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(data.ListBranchesParams))
+		run(args[0].(context.Context), args[1].(data.ListLatestProjectCoverageParams))
 	})
 	return _c
 }
 
-func (_c *Repository_ListBranches_Call) Return(_a0 []string, _a1 error) *Repository_ListBranches_Call {
+func (_c *Repository_ListLatestProjectCoverage_Call) Return(_a0 []data.ListLatestProjectCoverageRow, _a1 error) *Repository_ListLatestProjectCoverage_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_ListBranches_Call) RunAndReturn(run func(context.Context, data.ListBranchesParams) ([]string, error)) *Repository_ListBranches_Call {
+func (_c *Repository_ListLatestProjectCoverage_Call) RunAndReturn(run func(context.Context, data.ListLatestProjectCoverageParams) ([]data.ListLatestProjectCoverageRow, error)) *Repository_ListLatestProjectCoverage_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListCoverageSummary provides a mock function with given fields: ctx, params
-func (_m *Repository) ListCoverageSummary(ctx context.Context, params data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error) {
+// ListProjectCommitsByPrefix provides a mock function with given fields: ctx, params
+func (_m *Repository) ListProjectCommitsByPrefix(ctx context.Context, params data.ListProjectCommitsByPrefixParams) ([]string, error) {
 	ret := _m.Called(ctx, params)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListCoverageSummary")
+		panic("no return value specified for ListProjectCommitsByPrefix")
 	}
 
-	var r0 []data.ListCoverageSummaryRow
+	var r0 []string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListProjectCommitsByPrefixParams) ([]string, error)); ok {
 		return rf(ctx, params)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageSummaryParams) []data.ListCoverageSummaryRow); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListProjectCommitsByPrefixParams) []string); ok {
 		r0 = rf(ctx, params)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]data.ListCoverageSummaryRow)
+			r0 = ret.Get(0).([]string)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, data.ListCoverageSummaryParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListProjectCommitsByPrefixParams) error); ok {
 		r1 = rf(ctx, params)
 	} else {
 		r1 = ret.Error(1)
@@ -227,31 +1376,31 @@ func (_m *Repository) ListCoverageSummary(ctx context.Context, params data.ListC
 	return r0, r1
 }
 
-// Repository_ListCoverageSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCoverageSummary'
-type Repository_ListCoverageSummary_Call struct {
+// Repository_ListProjectCommitsByPrefix_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListProjectCommitsByPrefix'
+type Repository_ListProjectCommitsByPrefix_Call struct {
 	*mock.Call
 }
 
-// ListCoverageSummary is a helper method to define mock.On call
+// ListProjectCommitsByPrefix is a helper method to define mock.On call
 //   - ctx context.Context
-//   - params data.ListCoverageSummaryParams
-func (_e *Repository_Expecter) ListCoverageSummary(ctx interface{}, params interface{}) *Repository_ListCoverageSummary_Call {
-	return &Repository_ListCoverageSummary_Call{Call: _e.mock.On("ListCoverageSummary", ctx, params)}
+//   - params data.ListProjectCommitsByPrefixParams
+func (_e *Repository_Expecter) ListProjectCommitsByPrefix(ctx interface{}, params interface{}) *Repository_ListProjectCommitsByPrefix_Call {
+	return &Repository_ListProjectCommitsByPrefix_Call{Call: _e.mock.On("ListProjectCommitsByPrefix", ctx, params)}
 }
 
-func (_c *Repository_ListCoverageSummary_Call) Run(run func(ctx context.Context, params data.ListCoverageSummaryParams)) *Repository_ListCoverageSummary_Call {
+func (_c *Repository_ListProjectCommitsByPrefix_Call) Run(run func(ctx context.Context, params data.ListProjectCommitsByPrefixParams)) *Repository_ListProjectCommitsByPrefix_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(data.ListCoverageSummaryParams))
+		run(args[0].(context.Context), args[1].(data.ListProjectCommitsByPrefixParams))
 	})
 	return _c
 }
 
-func (_c *Repository_ListCoverageSummary_Call) Return(_a0 []data.ListCoverageSummaryRow, _a1 error) *Repository_ListCoverageSummary_Call {
+func (_c *Repository_ListProjectCommitsByPrefix_Call) Return(_a0 []string, _a1 error) *Repository_ListProjectCommitsByPrefix_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_ListCoverageSummary_Call) RunAndReturn(run func(context.Context, data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error)) *Repository_ListCoverageSummary_Call {
+func (_c *Repository_ListProjectCommitsByPrefix_Call) RunAndReturn(run func(context.Context, data.ListProjectCommitsByPrefixParams) ([]string, error)) *Repository_ListProjectCommitsByPrefix_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -373,26 +1522,85 @@ func (_c *Repository_ListRepositories_Call) RunAndReturn(run func(context.Contex
 	return _c
 }
 
-// UpsertCoverage provides a mock function with given fields: ctx, params
-func (_m *Repository) UpsertCoverage(ctx context.Context, params data.UpsertCoverageParams) (data.Coverage, error) {
+// ListStaleProjectCoverage provides a mock function with given fields: ctx, params
+func (_m *Repository) ListStaleProjectCoverage(ctx context.Context, params data.ListStaleProjectCoverageParams) ([]data.Coverage, error) {
 	ret := _m.Called(ctx, params)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpsertCoverage")
+		panic("no return value specified for ListStaleProjectCoverage")
 	}
 
-	var r0 data.Coverage
+	var r0 []data.Coverage
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertCoverageParams) (data.Coverage, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListStaleProjectCoverageParams) ([]data.Coverage, error)); ok {
 		return rf(ctx, params)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertCoverageParams) data.Coverage); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListStaleProjectCoverageParams) []data.Coverage); ok {
 		r0 = rf(ctx, params)
 	} else {
-		r0 = ret.Get(0).(data.Coverage)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.Coverage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListStaleProjectCoverageParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListStaleProjectCoverage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListStaleProjectCoverage'
+type Repository_ListStaleProjectCoverage_Call struct {
+	*mock.Call
+}
+
+// ListStaleProjectCoverage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.ListStaleProjectCoverageParams
+func (_e *Repository_Expecter) ListStaleProjectCoverage(ctx interface{}, params interface{}) *Repository_ListStaleProjectCoverage_Call {
+	return &Repository_ListStaleProjectCoverage_Call{Call: _e.mock.On("ListStaleProjectCoverage", ctx, params)}
+}
+
+func (_c *Repository_ListStaleProjectCoverage_Call) Run(run func(ctx context.Context, params data.ListStaleProjectCoverageParams)) *Repository_ListStaleProjectCoverage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.ListStaleProjectCoverageParams))
+	})
+	return _c
+}
+
+func (_c *Repository_ListStaleProjectCoverage_Call) Return(_a0 []data.Coverage, _a1 error) *Repository_ListStaleProjectCoverage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListStaleProjectCoverage_Call) RunAndReturn(run func(context.Context, data.ListStaleProjectCoverageParams) ([]data.Coverage, error)) *Repository_ListStaleProjectCoverage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpsertBadgeSettings provides a mock function with given fields: ctx, params
+func (_m *Repository) UpsertBadgeSettings(ctx context.Context, params data.UpsertBadgeSettingsParams) (data.BadgeSetting, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertBadgeSettings")
+	}
+
+	var r0 data.BadgeSetting
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertBadgeSettingsParams) (data.BadgeSetting, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertBadgeSettingsParams) data.BadgeSetting); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(data.BadgeSetting)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, data.UpsertCoverageParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, data.UpsertBadgeSettingsParams) error); ok {
 		r1 = rf(ctx, params)
 	} else {
 		r1 = ret.Error(1)
@@ -401,6 +1609,63 @@ func (_m *Repository) UpsertCoverage(ctx context.Context, params data.UpsertCove
 	return r0, r1
 }
 
+// Repository_UpsertBadgeSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertBadgeSettings'
+type Repository_UpsertBadgeSettings_Call struct {
+	*mock.Call
+}
+
+// UpsertBadgeSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.UpsertBadgeSettingsParams
+func (_e *Repository_Expecter) UpsertBadgeSettings(ctx interface{}, params interface{}) *Repository_UpsertBadgeSettings_Call {
+	return &Repository_UpsertBadgeSettings_Call{Call: _e.mock.On("UpsertBadgeSettings", ctx, params)}
+}
+
+func (_c *Repository_UpsertBadgeSettings_Call) Run(run func(ctx context.Context, params data.UpsertBadgeSettingsParams)) *Repository_UpsertBadgeSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.UpsertBadgeSettingsParams))
+	})
+	return _c
+}
+
+func (_c *Repository_UpsertBadgeSettings_Call) Return(_a0 data.BadgeSetting, _a1 error) *Repository_UpsertBadgeSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_UpsertBadgeSettings_Call) RunAndReturn(run func(context.Context, data.UpsertBadgeSettingsParams) (data.BadgeSetting, error)) *Repository_UpsertBadgeSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpsertCoverage provides a mock function with given fields: ctx, params, report
+func (_m *Repository) UpsertCoverage(ctx context.Context, params data.UpsertCoverageParams, report *coverage.Report) (data.Coverage, error) {
+	ret := _m.Called(ctx, params, report)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertCoverage")
+	}
+
+	var r0 data.Coverage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertCoverageParams, *coverage.Report) (data.Coverage, error)); ok {
+		return rf(ctx, params, report)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertCoverageParams, *coverage.Report) data.Coverage); ok {
+		r0 = rf(ctx, params, report)
+	} else {
+		r0 = ret.Get(0).(data.Coverage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.UpsertCoverageParams, *coverage.Report) error); ok {
+		r1 = rf(ctx, params, report)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Repository_UpsertCoverage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertCoverage'
 type Repository_UpsertCoverage_Call struct {
 	*mock.Call
@@ -409,13 +1674,14 @@ type Repository_UpsertCoverage_Call struct {
 // UpsertCoverage is a helper method to define mock.On call
 //   - ctx context.Context
 //   - params data.UpsertCoverageParams
-func (_e *Repository_Expecter) UpsertCoverage(ctx interface{}, params interface{}) *Repository_UpsertCoverage_Call {
-	return &Repository_UpsertCoverage_Call{Call: _e.mock.On("UpsertCoverage", ctx, params)}
+//   - report *coverage.Report
+func (_e *Repository_Expecter) UpsertCoverage(ctx interface{}, params interface{}, report interface{}) *Repository_UpsertCoverage_Call {
+	return &Repository_UpsertCoverage_Call{Call: _e.mock.On("UpsertCoverage", ctx, params, report)}
 }
 
-func (_c *Repository_UpsertCoverage_Call) Run(run func(ctx context.Context, params data.UpsertCoverageParams)) *Repository_UpsertCoverage_Call {
+func (_c *Repository_UpsertCoverage_Call) Run(run func(ctx context.Context, params data.UpsertCoverageParams, report *coverage.Report)) *Repository_UpsertCoverage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(data.UpsertCoverageParams))
+		run(args[0].(context.Context), args[1].(data.UpsertCoverageParams), args[2].(*coverage.Report))
 	})
 	return _c
 }
@@ -425,7 +1691,170 @@ func (_c *Repository_UpsertCoverage_Call) Return(_a0 data.Coverage, _a1 error) *
 	return _c
 }
 
-func (_c *Repository_UpsertCoverage_Call) RunAndReturn(run func(context.Context, data.UpsertCoverageParams) (data.Coverage, error)) *Repository_UpsertCoverage_Call {
+func (_c *Repository_UpsertCoverage_Call) RunAndReturn(run func(context.Context, data.UpsertCoverageParams, *coverage.Report) (data.Coverage, error)) *Repository_UpsertCoverage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpsertCoverageShard provides a mock function with given fields: ctx, params
+func (_m *Repository) UpsertCoverageShard(ctx context.Context, params data.UpsertCoverageShardParams) error {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertCoverageShard")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertCoverageShardParams) error); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_UpsertCoverageShard_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertCoverageShard'
+type Repository_UpsertCoverageShard_Call struct {
+	*mock.Call
+}
+
+// UpsertCoverageShard is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.UpsertCoverageShardParams
+func (_e *Repository_Expecter) UpsertCoverageShard(ctx interface{}, params interface{}) *Repository_UpsertCoverageShard_Call {
+	return &Repository_UpsertCoverageShard_Call{Call: _e.mock.On("UpsertCoverageShard", ctx, params)}
+}
+
+func (_c *Repository_UpsertCoverageShard_Call) Run(run func(ctx context.Context, params data.UpsertCoverageShardParams)) *Repository_UpsertCoverageShard_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.UpsertCoverageShardParams))
+	})
+	return _c
+}
+
+func (_c *Repository_UpsertCoverageShard_Call) Return(_a0 error) *Repository_UpsertCoverageShard_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_UpsertCoverageShard_Call) RunAndReturn(run func(context.Context, data.UpsertCoverageShardParams) error) *Repository_UpsertCoverageShard_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpsertCoverageWithConfig provides a mock function with given fields: ctx, params, report, config
+func (_m *Repository) UpsertCoverageWithConfig(ctx context.Context, params data.UpsertCoverageParams, report *coverage.Report, config *string) (data.Coverage, error) {
+	ret := _m.Called(ctx, params, report, config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertCoverageWithConfig")
+	}
+
+	var r0 data.Coverage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertCoverageParams, *coverage.Report, *string) (data.Coverage, error)); ok {
+		return rf(ctx, params, report, config)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertCoverageParams, *coverage.Report, *string) data.Coverage); ok {
+		r0 = rf(ctx, params, report, config)
+	} else {
+		r0 = ret.Get(0).(data.Coverage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.UpsertCoverageParams, *coverage.Report, *string) error); ok {
+		r1 = rf(ctx, params, report, config)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_UpsertCoverageWithConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertCoverageWithConfig'
+type Repository_UpsertCoverageWithConfig_Call struct {
+	*mock.Call
+}
+
+// UpsertCoverageWithConfig is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.UpsertCoverageParams
+//   - report *coverage.Report
+//   - config *string
+func (_e *Repository_Expecter) UpsertCoverageWithConfig(ctx interface{}, params interface{}, report interface{}, config interface{}) *Repository_UpsertCoverageWithConfig_Call {
+	return &Repository_UpsertCoverageWithConfig_Call{Call: _e.mock.On("UpsertCoverageWithConfig", ctx, params, report, config)}
+}
+
+func (_c *Repository_UpsertCoverageWithConfig_Call) Run(run func(ctx context.Context, params data.UpsertCoverageParams, report *coverage.Report, config *string)) *Repository_UpsertCoverageWithConfig_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.UpsertCoverageParams), args[2].(*coverage.Report), args[3].(*string))
+	})
+	return _c
+}
+
+func (_c *Repository_UpsertCoverageWithConfig_Call) Return(_a0 data.Coverage, _a1 error) *Repository_UpsertCoverageWithConfig_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_UpsertCoverageWithConfig_Call) RunAndReturn(run func(context.Context, data.UpsertCoverageParams, *coverage.Report, *string) (data.Coverage, error)) *Repository_UpsertCoverageWithConfig_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpsertGatePolicy provides a mock function with given fields: ctx, params
+func (_m *Repository) UpsertGatePolicy(ctx context.Context, params data.UpsertGatePolicyParams) (data.GatePolicy, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertGatePolicy")
+	}
+
+	var r0 data.GatePolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertGatePolicyParams) (data.GatePolicy, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.UpsertGatePolicyParams) data.GatePolicy); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(data.GatePolicy)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.UpsertGatePolicyParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_UpsertGatePolicy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertGatePolicy'
+type Repository_UpsertGatePolicy_Call struct {
+	*mock.Call
+}
+
+// UpsertGatePolicy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.UpsertGatePolicyParams
+func (_e *Repository_Expecter) UpsertGatePolicy(ctx interface{}, params interface{}) *Repository_UpsertGatePolicy_Call {
+	return &Repository_UpsertGatePolicy_Call{Call: _e.mock.On("UpsertGatePolicy", ctx, params)}
+}
+
+func (_c *Repository_UpsertGatePolicy_Call) Run(run func(ctx context.Context, params data.UpsertGatePolicyParams)) *Repository_UpsertGatePolicy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.UpsertGatePolicyParams))
+	})
+	return _c
+}
+
+func (_c *Repository_UpsertGatePolicy_Call) Return(_a0 data.GatePolicy, _a1 error) *Repository_UpsertGatePolicy_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_UpsertGatePolicy_Call) RunAndReturn(run func(context.Context, data.UpsertGatePolicyParams) (data.GatePolicy, error)) *Repository_UpsertGatePolicy_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -22,6 +22,63 @@ func (_m *Repository) EXPECT() *Repository_Expecter {
 	return &Repository_Expecter{mock: &_m.Mock}
 }
 
+// GetBadgeSettings provides a mock function with given fields: ctx, params
+func (_m *Repository) GetBadgeSettings(ctx context.Context, params data.GetBadgeSettingsParams) (data.BadgeSetting, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBadgeSettings")
+	}
+
+	var r0 data.BadgeSetting
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetBadgeSettingsParams) (data.BadgeSetting, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.GetBadgeSettingsParams) data.BadgeSetting); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(data.BadgeSetting)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.GetBadgeSettingsParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetBadgeSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBadgeSettings'
+type Repository_GetBadgeSettings_Call struct {
+	*mock.Call
+}
+
+// GetBadgeSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.GetBadgeSettingsParams
+func (_e *Repository_Expecter) GetBadgeSettings(ctx interface{}, params interface{}) *Repository_GetBadgeSettings_Call {
+	return &Repository_GetBadgeSettings_Call{Call: _e.mock.On("GetBadgeSettings", ctx, params)}
+}
+
+func (_c *Repository_GetBadgeSettings_Call) Run(run func(ctx context.Context, params data.GetBadgeSettingsParams)) *Repository_GetBadgeSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.GetBadgeSettingsParams))
+	})
+	return _c
+}
+
+func (_c *Repository_GetBadgeSettings_Call) Return(_a0 data.BadgeSetting, _a1 error) *Repository_GetBadgeSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetBadgeSettings_Call) RunAndReturn(run func(context.Context, data.GetBadgeSettingsParams) (data.BadgeSetting, error)) *Repository_GetBadgeSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetRecentCoverage provides a mock function with given fields: ctx, params
 func (_m *Repository) GetRecentCoverage(ctx context.Context, params data.GetRecentCoverageParams) (data.Coverage, error) {
 	ret := _m.Called(ctx, params)
@@ -79,6 +136,124 @@ func (_c *Repository_GetRecentCoverage_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
+// ListCoverageSummary provides a mock function with given fields: ctx, params
+func (_m *Repository) ListCoverageSummary(ctx context.Context, params data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCoverageSummary")
+	}
+
+	var r0 []data.ListCoverageSummaryRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListCoverageSummaryParams) []data.ListCoverageSummaryRow); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.ListCoverageSummaryRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListCoverageSummaryParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListCoverageSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCoverageSummary'
+type Repository_ListCoverageSummary_Call struct {
+	*mock.Call
+}
+
+// ListCoverageSummary is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.ListCoverageSummaryParams
+func (_e *Repository_Expecter) ListCoverageSummary(ctx interface{}, params interface{}) *Repository_ListCoverageSummary_Call {
+	return &Repository_ListCoverageSummary_Call{Call: _e.mock.On("ListCoverageSummary", ctx, params)}
+}
+
+func (_c *Repository_ListCoverageSummary_Call) Run(run func(ctx context.Context, params data.ListCoverageSummaryParams)) *Repository_ListCoverageSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.ListCoverageSummaryParams))
+	})
+	return _c
+}
+
+func (_c *Repository_ListCoverageSummary_Call) Return(_a0 []data.ListCoverageSummaryRow, _a1 error) *Repository_ListCoverageSummary_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListCoverageSummary_Call) RunAndReturn(run func(context.Context, data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error)) *Repository_ListCoverageSummary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListLatestProjectCoverage provides a mock function with given fields: ctx, params
+func (_m *Repository) ListLatestProjectCoverage(ctx context.Context, params data.ListLatestProjectCoverageParams) ([]data.ListLatestProjectCoverageRow, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListLatestProjectCoverage")
+	}
+
+	var r0 []data.ListLatestProjectCoverageRow
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListLatestProjectCoverageParams) ([]data.ListLatestProjectCoverageRow, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.ListLatestProjectCoverageParams) []data.ListLatestProjectCoverageRow); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.ListLatestProjectCoverageRow)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.ListLatestProjectCoverageParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListLatestProjectCoverage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListLatestProjectCoverage'
+type Repository_ListLatestProjectCoverage_Call struct {
+	*mock.Call
+}
+
+// ListLatestProjectCoverage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - params data.ListLatestProjectCoverageParams
+func (_e *Repository_Expecter) ListLatestProjectCoverage(ctx interface{}, params interface{}) *Repository_ListLatestProjectCoverage_Call {
+	return &Repository_ListLatestProjectCoverage_Call{Call: _e.mock.On("ListLatestProjectCoverage", ctx, params)}
+}
+
+func (_c *Repository_ListLatestProjectCoverage_Call) Run(run func(ctx context.Context, params data.ListLatestProjectCoverageParams)) *Repository_ListLatestProjectCoverage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(data.ListLatestProjectCoverageParams))
+	})
+	return _c
+}
+
+func (_c *Repository_ListLatestProjectCoverage_Call) Return(_a0 []data.ListLatestProjectCoverageRow, _a1 error) *Repository_ListLatestProjectCoverage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListLatestProjectCoverage_Call) RunAndReturn(run func(context.Context, data.ListLatestProjectCoverageParams) ([]data.ListLatestProjectCoverageRow, error)) *Repository_ListLatestProjectCoverage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepository(t interface {
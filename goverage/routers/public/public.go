@@ -2,79 +2,532 @@ package public
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"math"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"goverage/data"
+	"goverage/internal/badge"
+	"goverage/internal/coverage"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
 )
 
 type Router struct {
 	e    *echo.Echo
 	repo repository
+	// cacheControl is the Cache-Control header of the badges.
+	cacheControl string
 }
 
 type repository interface {
 	GetRecentCoverage(ctx context.Context, params data.GetRecentCoverageParams) (data.Coverage, error)
+	ListLatestProjectCoverage(ctx context.Context, params data.ListLatestProjectCoverageParams) ([]data.ListLatestProjectCoverageRow, error)
+	GetBadgeSettings(ctx context.Context, params data.GetBadgeSettingsParams) (data.BadgeSetting, error)
+	ListCoverageSummary(ctx context.Context, params data.ListCoverageSummaryParams) ([]data.ListCoverageSummaryRow, error)
 }
 
-func NewPublicRouter(e *echo.Echo, repo repository) *Router {
-	return &Router{e: e, repo: repo}
+func NewPublicRouter(e *echo.Echo, repo repository, cacheControl string) *Router {
+	return &Router{e: e, repo: repo, cacheControl: cacheControl}
+}
+
+const (
+	MetricLine     = "line"
+	MetricBranch   = "branch"
+	MetricFunction = "function"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// maxPrecision bounds the decimals of the percentages of badges.
+const maxPrecision = 2
+
+// defaultColor is the color of badges without a color scale.
+const defaultColor = "blue"
+
+// BadgeOptions are the query parameters styling a badge.
+type BadgeOptions struct {
+	Style string `query:"style"`
+	// Label replaces the repository, project and branch names.
+	Label     string `query:"label"`
+	Precision int    `query:"precision"`
+	// Colors overrides the color scale of the project, as "red:60,yellow:80,green".
+	Colors string `query:"colors"`
+}
+
+// validate checks the options and returns the color scale they override,
+// nil when they don't.
+func (o BadgeOptions) validate() (*badge.Scale, error) {
+	if o.Style != "" && !badge.ValidStyle(o.Style) {
+		return nil, echo.NewHTTPError(
+			http.StatusBadRequest, "style must be one of flat, flat-square, plastic, for-the-badge",
+		)
+	}
+
+	if o.Precision < 0 || o.Precision > maxPrecision {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "precision must be >=0 and <=2")
+	}
+
+	if o.Colors == "" {
+		return nil, nil
+	}
+
+	scale, err := badge.ParseScale(o.Colors)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return &scale, nil
 }
 
 type GetBranchBadgeRequest struct {
+	BadgeOptions
 	RepoName    string `param:"repoName"`
 	ProjectName string `param:"projectName"`
 	BranchName  string `param:"branchName"`
+	Metric      string `query:"metric"`
 }
 
-func (r *Router) GetBranchBadge(c echo.Context) error {
-	ctx := context.Background()
+// roundTo rounds value half up to precision decimals.
+func roundTo(value float64, precision int) float64 {
+	scale := math.Pow(10, float64(precision))
 
-	var reqData GetBranchBadgeRequest
-	if err := c.Bind(&reqData); err != nil {
-		return err
+	return math.Round(value*scale) / scale
+}
+
+func formatPercent(percent float64, precision int) string {
+	return strconv.FormatFloat(roundTo(percent, precision), 'f', precision, 64) + "%"
+}
+
+// formatDelta formats a change of percentage with its sign, "+" when it
+// rounds to zero.
+func formatDelta(delta float64, precision int) string {
+	rounded := roundTo(delta, precision)
+	if rounded >= 0 {
+		// Negative zeros are formatted with a minus sign.
+		return "+" + strconv.FormatFloat(math.Abs(rounded), 'f', precision, 64)
+	}
+
+	return strconv.FormatFloat(rounded, 'f', precision, 64)
+}
+
+// badgeColor returns the color of percent on scale, the default color without one.
+func badgeColor(percent float64, scale *badge.Scale) string {
+	if scale == nil {
+		return defaultColor
+	}
+
+	return scale.Color(percent)
+}
+
+// checkMetric defaults metric to line coverage and rejects unknown metrics.
+func checkMetric(metric *string) error {
+	switch *metric {
+	case "":
+		*metric = MetricLine
+	case MetricLine, MetricBranch, MetricFunction:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "metric must be one of line, branch, function")
+	}
+
+	return nil
+}
+
+// metricPercent returns the coverage of metric, given the headline coverage
+// and the branch and function counters of a report, and false when the report
+// doesn't measure it.
+func metricPercent(metric string, percent float64, branches, functions coverage.Counter) (float64, bool) {
+	switch metric {
+	case MetricBranch:
+		return branches.Percent(), branches.Total > 0
+	case MetricFunction:
+		return functions.Percent(), functions.Total > 0
+	default:
+		return percent, true
+	}
+}
+
+// badgeValue returns the message and color of the badge showing metric.
+func badgeValue(dbCoverage data.Coverage, metric string, precision int, scale *badge.Scale) (string, string) {
+	percent, ok := metricPercent(metric, dbCoverage.Coverage,
+		coverage.Counter{Covered: int(dbCoverage.BranchesCovered), Total: int(dbCoverage.BranchesTotal)},
+		coverage.Counter{Covered: int(dbCoverage.FunctionsCovered), Total: int(dbCoverage.FunctionsTotal)},
+	)
+	if !ok {
+		return "unknown", "lightgrey"
+	}
+
+	return formatPercent(percent, precision), badgeColor(percent, scale)
+}
+
+// badgeETag identifies a badge by version, naming the coverage it shows, and
+// by its color scale, which changes without a new upload.
+func badgeETag(version string, scale *badge.Scale) string {
+	hash := fnv.New32a()
+	if scale != nil {
+		_, _ = hash.Write([]byte(scale.String()))
+	}
+
+	return fmt.Sprintf(`"%s-%08x"`, version, hash.Sum32())
+}
+
+// etagMatches reports whether the If-None-Match header lists etag, weak or not.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notModified sets the caching headers of a badge and reports whether the
+// client already holds it, in which case a 304 is sent.
+func (r *Router) notModified(c echo.Context, etag string) (bool, error) {
+	header := c.Response().Header()
+	header.Set(headerETag, etag)
+	if r.cacheControl != "" {
+		header.Set(echo.HeaderCacheControl, r.cacheControl)
+	}
+
+	if !etagMatches(c.Request().Header.Get(headerIfNoneMatch), etag) {
+		return false, nil
+	}
+
+	return true, c.NoContent(http.StatusNotModified)
+}
+
+// apply sets the style of b and replaces its label when the options do.
+func (o BadgeOptions) apply(b badge.Badge) badge.Badge {
+	b.Style = o.Style
+	if o.Label != "" {
+		b.Label = o.Label
+	}
+
+	return b
+}
+
+func writeBadge(c echo.Context, b badge.Badge) error {
+	return c.Blob(http.StatusOK, "image/svg+xml", b.SVG())
+}
+
+// projectScale returns the color scale stored for a project, nil without one.
+// Badges still render with the default color when it can't be read.
+func (r *Router) projectScale(ctx context.Context, repoName, projectName string) *badge.Scale {
+	settings, err := r.repo.GetBadgeSettings(ctx, data.GetBadgeSettingsParams{
+		RepoName:    repoName,
+		ProjectName: projectName,
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Error().Err(err).Msg("Failed to get badge settings")
+		}
+		return nil
+	}
+
+	scale, err := badge.ParseScale(settings.ColorScale)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse stored color scale")
+		return nil
+	}
+
+	return &scale
+}
+
+// branchBadge looks up the badge of a branch and sets the caching headers of
+// the response. It returns nil after sending a 304 when the client already
+// holds the badge.
+func (r *Router) branchBadge(c echo.Context, reqData *GetBranchBadgeRequest) (*badge.Badge, error) {
+	ctx := c.Request().Context()
+
+	if err := checkMetric(&reqData.Metric); err != nil {
+		return nil, err
+	}
+
+	scale, err := reqData.validate()
+	if err != nil {
+		return nil, err
 	}
 
 	dbCoverage, err := r.repo.GetRecentCoverage(ctx, data.GetRecentCoverageParams{
 		RepoName:    reqData.RepoName,
 		ProjectName: reqData.ProjectName,
 		BranchName:  reqData.BranchName,
+		Kind:        coverage.KindUnit,
 	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound)
+		return nil, echo.NewHTTPError(http.StatusNotFound)
+	}
+
+	if scale == nil {
+		scale = r.projectScale(ctx, reqData.RepoName, reqData.ProjectName)
 	}
 
-	url := fmt.Sprintf(
-		"https://img.shields.io/badge/%s%%2F%s_%s-%s%%25-%s",
-		strings.ReplaceAll(dbCoverage.RepoName, "-", "--"),
-		strings.ReplaceAll(dbCoverage.ProjectName, "-", "--"),
-		strings.ReplaceAll(dbCoverage.BranchName, "-", "--"),
-		fmt.Sprintf("%.0f", math.Round(dbCoverage.Coverage)),
-		"blue",
+	// Recomputing a report keeps its row and date, so the version also names
+	// the values the badge may show.
+	version := fnv.New64a()
+	fmt.Fprintf(version, "%d %d %g %d %d %d %d",
+		dbCoverage.ID,
+		dbCoverage.CoverageDate.Time.UnixMicro(),
+		dbCoverage.Coverage,
+		dbCoverage.BranchesCovered,
+		dbCoverage.BranchesTotal,
+		dbCoverage.FunctionsCovered,
+		dbCoverage.FunctionsTotal,
 	)
+	if notModified, err := r.notModified(c, badgeETag(fmt.Sprintf("%x", version.Sum64()), scale)); notModified {
+		return nil, err
+	}
+
+	message, color := badgeValue(dbCoverage, reqData.Metric, reqData.Precision, scale)
+	b := reqData.apply(badge.Badge{
+		Label:   fmt.Sprintf("%s/%s %s", dbCoverage.RepoName, dbCoverage.ProjectName, dbCoverage.BranchName),
+		Message: message,
+		Color:   color,
+	})
+
+	return &b, nil
+}
+
+func (r *Router) GetBranchBadge(c echo.Context) error {
+	var reqData GetBranchBadgeRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	b, err := r.branchBadge(c, &reqData)
+	if b == nil {
+		return err
+	}
+
+	return writeBadge(c, *b)
+}
+
+// shieldsMinCacheSeconds is the shortest cache shields.io allows endpoint badges.
+const shieldsMinCacheSeconds = 300
+
+var maxAgePattern = regexp.MustCompile(`(?:^|[ ,])max-age=(\d+)`)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+// ShieldsEndpointSchema is the response shields.io reads to draw endpoint badges.
+type ShieldsEndpointSchema struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+	Style         string `json:"style,omitempty"`
+	CacheSeconds  int    `json:"cacheSeconds"`
+}
+
+// cacheSeconds returns the max-age of the Cache-Control header of the badges,
+// raised to the minimum of shields.io.
+func (r *Router) cacheSeconds() int {
+	match := maxAgePattern.FindStringSubmatch(r.cacheControl)
+	if match == nil {
+		return shieldsMinCacheSeconds
+	}
+
+	seconds, err := strconv.Atoi(match[1])
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to create badge request")
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to fetch badge")
+		return shieldsMinCacheSeconds
+	}
+
+	return max(seconds, shieldsMinCacheSeconds)
+}
+
+// GetShieldsEndpoint describes the badge of GetBranchBadge in the format of
+// the endpoint badges of shields.io.
+func (r *Router) GetShieldsEndpoint(c echo.Context) error {
+	var reqData GetBranchBadgeRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	b, err := r.branchBadge(c, &reqData)
+	if b == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ShieldsEndpointSchema{
+		SchemaVersion: 1,
+		Label:         b.Label,
+		Message:       b.Message,
+		Color:         b.Color,
+		Style:         b.Style,
+		CacheSeconds:  r.cacheSeconds(),
+	})
+}
+
+type GetRepoBadgeRequest struct {
+	BadgeOptions
+	RepoName   string `param:"repoName"`
+	BranchName string `param:"branchName"`
+}
+
+// GetRepoBadge shows the coverage of every project of a branch, weighted by
+// the statements of each project, as GetRepoCoverage of the API. Only the
+// colors query parameter sets its color scale, projects having their own.
+func (r *Router) GetRepoBadge(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData GetRepoBadgeRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
 	}
 
-	client := http.Client{}
-	resp, err := client.Do(req)
+	scale, err := reqData.validate()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch badge")
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to fetch badge")
+		return err
+	}
+
+	rows, err := r.repo.ListLatestProjectCoverage(ctx, data.ListLatestProjectCoverageParams{
+		RepoName:   reqData.RepoName,
+		BranchName: reqData.BranchName,
+		Kind:       coverage.KindUnit,
+	})
+	if err != nil || len(rows) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+
+	version := fnv.New64a()
+	for _, row := range rows {
+		fmt.Fprintf(version, "%s %s %d %g %d %d\n",
+			row.ProjectName, row.Commit, row.CoverageDate.Time.UnixMicro(), row.Coverage, row.StatementsTotal, row.LinesTotal,
+		)
+	}
+	if notModified, err := r.notModified(c, badgeETag(fmt.Sprintf("%x", version.Sum64()), scale)); notModified {
+		return err
+	}
+
+	percent := coverage.WeightedMean(lo.Map(rows, func(row data.ListLatestProjectCoverageRow, _ int) coverage.Weighted {
+		return coverage.Weighted{
+			Coverage: row.Coverage,
+			Weight:   coverage.Weight(int(row.StatementsTotal), int(row.LinesTotal)),
+		}
+	}))
+	label := fmt.Sprintf("%s %s", reqData.RepoName, reqData.BranchName)
+
+	return writeBadge(c, reqData.apply(badge.Badge{
+		Label:   label,
+		Message: formatPercent(percent, reqData.Precision),
+		Color:   badgeColor(percent, scale),
+	}))
+}
+
+const (
+	defaultTrendPoints = 20
+	maxTrendPoints     = 100
+)
+
+type GetTrendBadgeRequest struct {
+	BadgeOptions
+	RepoName    string `param:"repoName"`
+	ProjectName string `param:"projectName"`
+	BranchName  string `param:"branchName"`
+	// Points is the number of uploads drawn.
+	Points int    `query:"points"`
+	Metric string `query:"metric"`
+}
+
+// GetTrendBadge draws the coverage of the latest uploads of a branch as a
+// sparkline, followed by the latest coverage and its change since the
+// previous upload. Uploads not measuring the metric are left out.
+func (r *Router) GetTrendBadge(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var reqData GetTrendBadgeRequest
+	if err := c.Bind(&reqData); err != nil {
+		return err
+	}
+
+	if reqData.Points == 0 {
+		reqData.Points = defaultTrendPoints
+	}
+	if reqData.Points < 2 || reqData.Points > maxTrendPoints {
+		return echo.NewHTTPError(http.StatusBadRequest, "points must be >=2 and <=100")
+	}
+
+	if err := checkMetric(&reqData.Metric); err != nil {
+		return err
+	}
+
+	scale, err := reqData.validate()
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.repo.ListCoverageSummary(ctx, data.ListCoverageSummaryParams{
+		RepoName:       reqData.RepoName,
+		ProjectName:    reqData.ProjectName,
+		BranchName:     reqData.BranchName,
+		Limit:          int32(reqData.Points),
+		Kind:           coverage.KindUnit,
+		OrderDirection: "desc",
+	})
+	if err != nil || len(rows) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound)
+	}
+
+	rows = lo.Reverse(rows)
+	latest := rows[len(rows)-1]
+
+	if scale == nil {
+		scale = r.projectScale(ctx, reqData.RepoName, reqData.ProjectName)
+	}
+
+	version := fnv.New64a()
+	points := make([]float64, 0, len(rows))
+	for _, row := range rows {
+		fmt.Fprintf(version, "%s %d %g %d %d %d %d\n",
+			row.Commit, row.CoverageDate.Time.UnixMicro(), row.Coverage,
+			row.BranchesCovered, row.BranchesTotal, row.FunctionsCovered, row.FunctionsTotal,
+		)
+
+		percent, ok := metricPercent(reqData.Metric, row.Coverage,
+			coverage.Counter{Covered: int(row.BranchesCovered), Total: int(row.BranchesTotal)},
+			coverage.Counter{Covered: int(row.FunctionsCovered), Total: int(row.FunctionsTotal)},
+		)
+		if ok {
+			points = append(points, percent)
+		}
+	}
+	if notModified, err := r.notModified(c, badgeETag(fmt.Sprintf("%x", version.Sum64()), scale)); notModified {
+		return err
+	}
+
+	b := badge.Badge{
+		Label:   fmt.Sprintf("%s/%s %s", latest.RepoName, latest.ProjectName, latest.BranchName),
+		Message: "unknown",
+		Color:   "lightgrey",
+	}
+	if len(points) > 0 {
+		percent := points[len(points)-1]
+		b.Message = formatPercent(percent, reqData.Precision)
+		if len(points) > 1 {
+			b.Message += " " + formatDelta(percent-points[len(points)-2], reqData.Precision)
+		}
+		b.Color = badgeColor(percent, scale)
+		b.Trend = points
 	}
-	defer resp.Body.Close()
 
-	return c.Stream(http.StatusOK, "image/svg+xml", resp.Body)
+	return writeBadge(c, reqData.apply(b))
 }
 
 func (r *Router) Register() {
 	r.e.GET("/repos/:repoName/projects/:projectName/branches/:branchName/badge", r.GetBranchBadge)
+	r.e.GET("/repos/:repoName/projects/:projectName/branches/:branchName/badge.json", r.GetShieldsEndpoint)
+	r.e.GET("/repos/:repoName/projects/:projectName/branches/:branchName/trend", r.GetTrendBadge)
+	r.e.GET("/repos/:repoName/branches/:branchName/badge", r.GetRepoBadge)
 }
@@ -5,8 +5,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"goverage/internal/badge"
 	"goverage/routers/api/v1/mocks"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -18,7 +20,19 @@ import (
 
 func TestGetBranchBadge(t *testing.T) {
 	mockRepo := mocks.NewRepository(t)
-	router := NewPublicRouter(echo.New(), mockRepo)
+	router := NewPublicRouter(echo.New(), mockRepo, "max-age=300")
+
+	setup := func(t *testing.T) (*mocks.Repository, *Router) {
+		mockRepo := mocks.NewRepository(t)
+		mockRepo.On("GetRecentCoverage", mock.Anything, mock.Anything).Return(data.Coverage{
+			RepoName:    "repo1",
+			ProjectName: "project1",
+			BranchName:  "branch1",
+			Coverage:    72.25,
+		}, nil).Maybe()
+
+		return mockRepo, NewPublicRouter(echo.New(), mockRepo, "max-age=300")
+	}
 
 	t.Run("ReturnsBadgeSuccessfully", func(t *testing.T) {
 		mockRepo.On("GetRecentCoverage", mock.Anything, mock.Anything).Return(data.Coverage{
@@ -27,14 +41,388 @@ func TestGetBranchBadge(t *testing.T) {
 			BranchName:  "branch1",
 			Coverage:    90.0,
 		}, nil)
+		mockRepo.On("GetBadgeSettings", mock.Anything, mock.Anything).Return(data.BadgeSetting{}, pgx.ErrNoRows)
+		req := httptest.NewRequest(http.MethodGet, "/repos/repo1/projects/project1/branches/branch1/badge", http.NoBody)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+
+		err := router.GetBranchBadge(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/svg+xml", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Body.String(), "<title>repo1/project1 branch1: 90%</title>")
+		assert.Contains(t, rec.Body.String(), `fill="#007ec6"`)
+	})
+
+	t.Run("UsesStoredColorScale", func(t *testing.T) {
+		mockRepo, router := setup(t)
+		mockRepo.On("GetBadgeSettings", mock.Anything, data.GetBadgeSettingsParams{
+			RepoName: "repo1", ProjectName: "project1",
+		}).Return(data.BadgeSetting{ColorScale: "red:60,yellow:80,green"}, nil)
 		req := httptest.NewRequest(http.MethodGet, "/repos/repo1/projects/project1/branches/branch1/badge", http.NoBody)
 		req.ContentLength = 0 // Required for echo to parse the request body correctly
 		rec := httptest.NewRecorder()
 		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName")
+		c.SetParamValues("repo1", "project1", "branch1")
+
+		err := router.GetBranchBadge(c)
+
+		assert.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `fill="#dfb317"`)
+	})
+
+	t.Run("AppliesQueryOptions", func(t *testing.T) {
+		_, router := setup(t)
+		req := httptest.NewRequest(
+			http.MethodGet,
+			"/repos/repo1/projects/project1/branches/branch1/badge?style=flat-square&label=coverage&precision=1&colors=red:80,green",
+			http.NoBody,
+		)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
 
 		err := router.GetBranchBadge(c)
 
+		assert.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), "<title>coverage: 72.3%</title>")
+		assert.Contains(t, rec.Body.String(), `fill="#e05d44"`)
+		assert.Contains(t, rec.Body.String(), `rx="0"`)
+	})
+
+	t.Run("HonorsIfNoneMatch", func(t *testing.T) {
+		mockRepo, router := setup(t)
+		mockRepo.On("GetBadgeSettings", mock.Anything, mock.Anything).Return(data.BadgeSetting{}, pgx.ErrNoRows)
+		get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/repos/repo1/projects/project1/branches/branch1/badge", http.NoBody)
+			req.ContentLength = 0 // Required for echo to parse the request body correctly
+			req.Header.Set("If-None-Match", ifNoneMatch)
+			rec := httptest.NewRecorder()
+
+			assert.NoError(t, router.GetBranchBadge(router.e.NewContext(req, rec)))
+
+			return rec
+		}
+
+		first := get("")
+		etag := first.Header().Get("ETag")
+		second := get(`"other", W/` + etag)
+
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "max-age=300", first.Header().Get(echo.HeaderCacheControl))
+		assert.NotEmpty(t, etag)
+		assert.Equal(t, http.StatusNotModified, second.Code)
+		assert.Equal(t, etag, second.Header().Get("ETag"))
+		assert.Empty(t, second.Body.String())
+	})
+
+	t.Run("ChangesETagWithRecomputedCoverage", func(t *testing.T) {
+		etag := func(percent float64) string {
+			mockRepo := mocks.NewRepository(t)
+			mockRepo.On("GetRecentCoverage", mock.Anything, mock.Anything).Return(data.Coverage{ID: 1, Coverage: percent}, nil)
+			mockRepo.On("GetBadgeSettings", mock.Anything, mock.Anything).Return(data.BadgeSetting{}, pgx.ErrNoRows)
+			router := NewPublicRouter(echo.New(), mockRepo, "max-age=300")
+			req := httptest.NewRequest(http.MethodGet, "/repos/repo1/projects/project1/branches/branch1/badge", http.NoBody)
+			req.ContentLength = 0 // Required for echo to parse the request body correctly
+			rec := httptest.NewRecorder()
+
+			assert.NoError(t, router.GetBranchBadge(router.e.NewContext(req, rec)))
+
+			return rec.Header().Get("ETag")
+		}
+
+		assert.NotEqual(t, etag(72.25), etag(80))
+	})
+
+	t.Run("RejectsInvalidOptions", func(t *testing.T) {
+		for _, query := range []string{"style=round", "precision=3", "colors=red:80"} {
+			_, router := setup(t)
+			req := httptest.NewRequest(http.MethodGet, "/repos/repo1/projects/project1/branches/branch1/badge?"+query, http.NoBody)
+			req.ContentLength = 0 // Required for echo to parse the request body correctly
+			rec := httptest.NewRecorder()
+			c := router.e.NewContext(req, rec)
+
+			err := router.GetBranchBadge(c)
+
+			var httpErr *echo.HTTPError
+			assert.ErrorAs(t, err, &httpErr, query)
+			assert.Equal(t, http.StatusBadRequest, httpErr.Code, query)
+		}
+	})
+
+	t.Run("RejectsUnknownMetric", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/repos/repo1/projects/project1/branches/branch1/badge?metric=mutation", http.NoBody)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+
+		err := router.GetBranchBadge(c)
+
+		var httpErr *echo.HTTPError
+		assert.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+}
+
+func TestGetRepoBadge(t *testing.T) {
+	mockRepo := mocks.NewRepository(t)
+	router := NewPublicRouter(echo.New(), mockRepo, "max-age=300")
+
+	t.Run("ReturnsNotFoundWithoutCoverage", func(t *testing.T) {
+		mockRepo.On("ListLatestProjectCoverage", mock.Anything, data.ListLatestProjectCoverageParams{
+			RepoName: "repo1", BranchName: "branch1", Kind: "unit",
+		}).Return(nil, nil)
+		req := httptest.NewRequest(http.MethodGet, "/repos/repo1/branches/branch1/badge", http.NoBody)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "branchName")
+		c.SetParamValues("repo1", "branch1")
+
+		err := router.GetRepoBadge(c)
+
+		var httpErr *echo.HTTPError
+		assert.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+
+	t.Run("ChangesETagWithRecomputedCoverage", func(t *testing.T) {
+		etag := func(percent float64) string {
+			mockRepo := mocks.NewRepository(t)
+			mockRepo.On("ListLatestProjectCoverage", mock.Anything, mock.Anything).Return([]data.ListLatestProjectCoverageRow{
+				{ProjectName: "project1", Commit: "a", Coverage: percent, LinesTotal: 10},
+			}, nil)
+			router := NewPublicRouter(echo.New(), mockRepo, "max-age=300")
+			req := httptest.NewRequest(http.MethodGet, "/repos/repo1/branches/branch1/badge", http.NoBody)
+			req.ContentLength = 0 // Required for echo to parse the request body correctly
+			rec := httptest.NewRecorder()
+
+			assert.NoError(t, router.GetRepoBadge(router.e.NewContext(req, rec)))
+
+			return rec.Header().Get("ETag")
+		}
+
+		assert.NotEqual(t, etag(70), etag(75))
+	})
+}
+
+func TestGetShieldsEndpoint(t *testing.T) {
+	setup := func(t *testing.T, target, cacheControl string) (*mocks.Repository, *Router, echo.Context, *httptest.ResponseRecorder) {
+		mockRepo := mocks.NewRepository(t)
+		router := NewPublicRouter(echo.New(), mockRepo, cacheControl)
+		req := httptest.NewRequest(http.MethodGet, "/repos/repo1/projects/project1/branches/branch1/badge.json"+target, http.NoBody)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName")
+		c.SetParamValues("repo1", "project1", "branch1")
+
+		return mockRepo, router, c, rec
+	}
+
+	t.Run("DescribesBranchBadge", func(t *testing.T) {
+		mockRepo, router, c, rec := setup(t, "?metric=branch&style=flat-square", "public, max-age=3600")
+		mockRepo.On("GetRecentCoverage", mock.Anything, data.GetRecentCoverageParams{
+			RepoName: "repo1", ProjectName: "project1", BranchName: "branch1", Kind: "unit",
+		}).Return(data.Coverage{
+			RepoName:        "repo1",
+			ProjectName:     "project1",
+			BranchName:      "branch1",
+			BranchesCovered: 1,
+			BranchesTotal:   2,
+		}, nil)
+		mockRepo.On("GetBadgeSettings", mock.Anything, mock.Anything).Return(data.BadgeSetting{ColorScale: "red:60,green"}, nil)
+
+		err := router.GetShieldsEndpoint(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+		assert.JSONEq(t, `{
+			"schemaVersion": 1,
+			"label": "repo1/project1 branch1",
+			"message": "50%",
+			"color": "red",
+			"style": "flat-square",
+			"cacheSeconds": 3600
+		}`, rec.Body.String())
+	})
+
+	t.Run("RejectsUnknownMetric", func(t *testing.T) {
+		_, router, c, _ := setup(t, "?metric=mutation", "")
+
+		err := router.GetShieldsEndpoint(c)
+
+		var httpErr *echo.HTTPError
+		assert.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("KeepsShieldsMinimumCache", func(t *testing.T) {
+		for cacheControl, seconds := range map[string]int{"": 300, "no-cache": 300, "max-age=60": 300, "s-maxage=10, max-age=900": 900} {
+			router := NewPublicRouter(echo.New(), mocks.NewRepository(t), cacheControl)
+
+			assert.Equal(t, seconds, router.cacheSeconds(), cacheControl)
+		}
+	})
+}
+
+func TestGetTrendBadge(t *testing.T) {
+	setup := func(t *testing.T, target string) (*mocks.Repository, *Router, echo.Context, *httptest.ResponseRecorder) {
+		mockRepo := mocks.NewRepository(t)
+		router := NewPublicRouter(echo.New(), mockRepo, "max-age=300")
+		req := httptest.NewRequest(http.MethodGet, "/repos/repo1/projects/project1/branches/branch1/trend"+target, http.NoBody)
+		req.ContentLength = 0 // Required for echo to parse the request body correctly
+		rec := httptest.NewRecorder()
+		c := router.e.NewContext(req, rec)
+		c.SetParamNames("repoName", "projectName", "branchName")
+		c.SetParamValues("repo1", "project1", "branch1")
+
+		return mockRepo, router, c, rec
+	}
+
+	t.Run("DrawsLatestUploads", func(t *testing.T) {
+		mockRepo, router, c, rec := setup(t, "?points=3&precision=1&colors=red:80,green")
+		mockRepo.On("ListCoverageSummary", mock.Anything, data.ListCoverageSummaryParams{
+			RepoName:       "repo1",
+			ProjectName:    "project1",
+			BranchName:     "branch1",
+			Limit:          3,
+			Kind:           "unit",
+			OrderDirection: "desc",
+		}).Return([]data.ListCoverageSummaryRow{
+			{RepoName: "repo1", ProjectName: "project1", BranchName: "branch1", Coverage: 78.5},
+			{RepoName: "repo1", ProjectName: "project1", BranchName: "branch1", Coverage: 81.25},
+			{RepoName: "repo1", ProjectName: "project1", BranchName: "branch1", Coverage: 80},
+		}, nil)
+
+		err := router.GetTrendBadge(c)
+
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "<title>repo1/project1 branch1: 78.5% -2.8</title>")
+		assert.Contains(t, rec.Body.String(), "<polyline")
+		assert.Contains(t, rec.Body.String(), `fill="#e05d44"`)
+	})
+
+	t.Run("ChangesETagWithAnyPoint", func(t *testing.T) {
+		etag := func(oldest float64) string {
+			mockRepo, router, c, rec := setup(t, "?colors=red:80,green")
+			mockRepo.On("ListCoverageSummary", mock.Anything, mock.Anything).Return([]data.ListCoverageSummaryRow{
+				{Commit: "b", Coverage: 80},
+				{Commit: "a", Coverage: oldest},
+			}, nil)
+
+			assert.NoError(t, router.GetTrendBadge(c))
+
+			return rec.Header().Get("ETag")
+		}
+
+		assert.NotEqual(t, etag(70), etag(75))
+	})
+
+	t.Run("DrawsMetric", func(t *testing.T) {
+		mockRepo, router, c, rec := setup(t, "?metric=branch")
+		mockRepo.On("ListCoverageSummary", mock.Anything, mock.Anything).Return([]data.ListCoverageSummaryRow{
+			{RepoName: "repo1", ProjectName: "project1", BranchName: "branch1", Coverage: 90, BranchesCovered: 3, BranchesTotal: 4},
+			{RepoName: "repo1", ProjectName: "project1", BranchName: "branch1", Coverage: 80},
+			{RepoName: "repo1", ProjectName: "project1", BranchName: "branch1", Coverage: 70, BranchesCovered: 1, BranchesTotal: 2},
+		}, nil)
+		mockRepo.On("GetBadgeSettings", mock.Anything, mock.Anything).Return(data.BadgeSetting{}, pgx.ErrNoRows)
+
+		err := router.GetTrendBadge(c)
+
+		assert.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), "<title>repo1/project1 branch1: 75% +25</title>")
+	})
+
+	t.Run("RejectsUnknownMetric", func(t *testing.T) {
+		_, router, c, _ := setup(t, "?metric=statement")
+
+		err := router.GetTrendBadge(c)
+
+		var httpErr *echo.HTTPError
+		assert.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("RejectsTooManyPoints", func(t *testing.T) {
+		_, router, c, _ := setup(t, "?points=500")
+
+		err := router.GetTrendBadge(c)
+
+		var httpErr *echo.HTTPError
+		assert.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("ReturnsNotFoundWithoutUploads", func(t *testing.T) {
+		mockRepo, router, c, _ := setup(t, "")
+		mockRepo.On("ListCoverageSummary", mock.Anything, mock.Anything).Return(nil, nil)
+
+		err := router.GetTrendBadge(c)
+
+		var httpErr *echo.HTTPError
+		assert.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
+
+func TestFormatDelta(t *testing.T) {
+	assert.Equal(t, "+1.3", formatDelta(1.25, 1))
+	assert.Equal(t, "-2", formatDelta(-1.5, 0))
+	assert.Equal(t, "+0.0", formatDelta(-0.04, 1))
+}
+
+func TestBadgeETag(t *testing.T) {
+	red, _ := badge.ParseScale("red")
+	green, _ := badge.ParseScale("green")
+
+	assert.Equal(t, badgeETag("1-2", nil), badgeETag("1-2", nil))
+	assert.NotEqual(t, badgeETag("1-2", nil), badgeETag("1-3", nil))
+	assert.NotEqual(t, badgeETag("1-2", &red), badgeETag("1-2", &green))
+	assert.True(t, etagMatches("*", `"1-2"`))
+	assert.False(t, etagMatches("", `"1-2"`))
+}
+
+func TestBadgeValue(t *testing.T) {
+	dbCoverage := data.Coverage{Coverage: 89.6, BranchesCovered: 1, BranchesTotal: 3}
+
+	t.Run("ShowsLineCoverage", func(t *testing.T) {
+		message, color := badgeValue(dbCoverage, MetricLine, 0, nil)
+
+		assert.Equal(t, "90%", message)
+		assert.Equal(t, "blue", color)
+	})
+
+	t.Run("ShowsBranchCoverage", func(t *testing.T) {
+		message, _ := badgeValue(dbCoverage, MetricBranch, 0, nil)
+
+		assert.Equal(t, "33%", message)
+	})
+
+	t.Run("ShowsFunctionCoverage", func(t *testing.T) {
+		message, _ := badgeValue(data.Coverage{FunctionsCovered: 2, FunctionsTotal: 3}, MetricFunction, 2, nil)
+
+		assert.Equal(t, "66.67%", message)
+	})
+
+	t.Run("ColorsByScale", func(t *testing.T) {
+		scale, _ := badge.ParseScale("red:60,yellow:90,green")
+
+		message, color := badgeValue(dbCoverage, MetricLine, 1, &scale)
+
+		assert.Equal(t, "89.6%", message)
+		assert.Equal(t, "yellow", color)
+	})
+
+	t.Run("ShowsUnknownWithoutBranches", func(t *testing.T) {
+		message, color := badgeValue(data.Coverage{Coverage: 50}, MetricBranch, 0, nil)
+
+		assert.Equal(t, "unknown", message)
+		assert.Equal(t, "lightgrey", color)
 	})
 }
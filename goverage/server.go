@@ -6,8 +6,8 @@ import (
 	"embed"
 	"net/http"
 
-	"goverage/data"
 	"goverage/internal/config"
+	"goverage/internal/store"
 	apiv1 "goverage/routers/api/v1"
 	"goverage/routers/public"
 
@@ -63,12 +63,12 @@ func main() {
 	e.Use(middleware.RequestID())
 	e.Use(middleware.Recover())
 
-	repo := data.New(pool)
+	repo := store.New(pool, config.Config.BadgeCacheTTL)
 
 	apiV1Router := apiv1.NewAPIV1Router(e, repo)
 	apiV1Router.Register()
 
-	publicRouter := public.NewPublicRouter(e, repo)
+	publicRouter := public.NewPublicRouter(e, repo, config.Config.BadgeCacheControl)
 	publicRouter.Register()
 
 	e.GET("/_live", func(c echo.Context) error {
@@ -3,15 +3,91 @@ SELECT * FROM coverage
 WHERE repo_name = $1
     AND project_name = $2
     AND branch_name = $3
+    AND kind = $4
 ORDER BY coverage_date DESC
 LIMIT 1;
 
+-- name: ListLatestProjectCoverage :many
+SELECT DISTINCT ON (coverage.project_name)
+    coverage.project_name, coverage.commit, coverage.coverage, coverage.coverage_date,
+    coverage.lines_covered, coverage.lines_total,
+    COALESCE(files.statements_covered, 0)::int AS statements_covered,
+    COALESCE(files.statements_total, 0)::int AS statements_total
+FROM coverage
+LEFT JOIN LATERAL (
+    SELECT SUM(statements_covered) AS statements_covered, SUM(statements_total) AS statements_total
+    FROM coverage_file
+    WHERE coverage_file.coverage_id = coverage.id
+) AS files ON true
+WHERE coverage.repo_name = $1
+    AND coverage.branch_name = $2
+    AND coverage.kind = $3
+ORDER BY coverage.project_name, coverage.coverage_date DESC;
+
 -- name: GetCoverageData :one
 SELECT raw_data FROM coverage
 WHERE repo_name = $1
     AND project_name = $2
     AND branch_name = $3
     AND "commit" = $4
+    AND kind = $5
+LIMIT 1;
+
+-- name: ListCommitsByPrefix :many
+SELECT DISTINCT "commit" FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND branch_name = $3
+    AND kind = $4
+    AND ("commit" LIKE @prefix::text || '%'
+        OR (length("commit") = 8 AND @prefix::text LIKE "commit" || '%'))
+    AND NOT (length("commit") = 8 AND EXISTS (
+        SELECT 1 FROM coverage full_row
+        WHERE full_row.repo_name = coverage.repo_name
+            AND full_row.project_name = coverage.project_name
+            AND full_row.branch_name = coverage.branch_name
+            AND full_row.kind = coverage.kind
+            AND length(full_row."commit") > 8
+            AND full_row."commit" LIKE coverage."commit" || '%'
+            AND full_row."commit" LIKE @prefix::text || '%'
+    ))
+ORDER BY "commit"
+LIMIT 10;
+
+-- name: ListProjectCommitsByPrefix :many
+SELECT DISTINCT "commit" FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND kind = $3
+    AND ("commit" LIKE @prefix::text || '%'
+        OR (length("commit") = 8 AND @prefix::text LIKE "commit" || '%'))
+    AND NOT (length("commit") = 8 AND EXISTS (
+        SELECT 1 FROM coverage full_row
+        WHERE full_row.repo_name = coverage.repo_name
+            AND full_row.project_name = coverage.project_name
+            AND full_row.kind = coverage.kind
+            AND length(full_row."commit") > 8
+            AND full_row."commit" LIKE coverage."commit" || '%'
+            AND full_row."commit" LIKE @prefix::text || '%'
+    ))
+ORDER BY "commit"
+LIMIT 10;
+
+-- name: GetCommitCoverage :one
+SELECT * FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND branch_name = $3
+    AND "commit" = $4
+    AND kind = $5;
+
+-- name: GetProjectCommitCoverage :one
+SELECT * FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND "commit" = $3
+    AND kind = $4
+ORDER BY coverage_date DESC
 LIMIT 1;
 
 -- name: ListCoverage :many
@@ -19,6 +95,7 @@ SELECT * FROM coverage
 WHERE repo_name = $1
   AND project_name = $2
   AND branch_name = $3
+  AND kind = @kind
 ORDER BY
 case WHEN lower(@order_direction) = 'asc' THEN coverage_date END ASC,
 case WHEN lower(@order_direction) = 'desc' THEN coverage_date END DESC,
@@ -26,13 +103,90 @@ coverage_date ASC
 OFFSET $4
 LIMIT $5;
 
+-- name: PromoteTruncatedCommit :exec
+UPDATE coverage SET "commit" = @commit::text
+WHERE repo_name = $1
+    AND project_name = $2
+    AND branch_name = $3
+    AND kind = $4
+    AND "commit" = left(@commit::text, 8);
+
+-- name: ListCoverageLineRanges :many
+SELECT coverage_file.path, coverage_line_range.start_line, coverage_line_range.end_line, coverage_line_range.hits,
+    coverage_line_range.branches_covered, coverage_line_range.branches_total
+FROM coverage_line_range
+JOIN coverage_file ON coverage_file.id = coverage_line_range.coverage_file_id
+JOIN coverage ON coverage.id = coverage_file.coverage_id
+WHERE coverage.repo_name = $1
+    AND coverage.project_name = $2
+    AND coverage.branch_name = $3
+    AND coverage."commit" = $4
+    AND coverage.kind = $5
+ORDER BY coverage_file.path, coverage_line_range.start_line;
+
 -- name: UpsertCoverage :one
-INSERT INTO coverage (repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-ON CONFLICT (repo_name, project_name, branch_name, commit)
-    DO UPDATE SET coverage = $5, coverage_date = $6, raw_data = $7
+INSERT INTO coverage (
+    repo_name, project_name, branch_name, commit, coverage, coverage_date, raw_data, format, kind,
+    lines_covered, lines_total, branches_covered, branches_total,
+    original_coverage, original_lines_covered, original_lines_total, functions_covered, functions_total, rules_version
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+ON CONFLICT (repo_name, project_name, branch_name, commit, kind)
+    DO UPDATE SET coverage = $5, coverage_date = $6, raw_data = $7, format = $8,
+        lines_covered = $10, lines_total = $11, branches_covered = $12, branches_total = $13,
+        original_coverage = $14, original_lines_covered = $15, original_lines_total = $16,
+        functions_covered = $17, functions_total = $18, rules_version = $19
 RETURNING *;
 
+-- name: UpsertCoverageShard :exec
+INSERT INTO coverage_shard (
+    repo_name, project_name, branch_name, commit, shard_id, format, content, expected_shards
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (repo_name, project_name, branch_name, commit, shard_id)
+    DO UPDATE SET format = $6, content = $7, expected_shards = $8, uploaded_at = now();
+
+-- name: GetCoverageConfig :one
+SELECT content FROM coverage_config WHERE coverage_id = $1;
+
+-- name: UpsertCoverageConfig :exec
+INSERT INTO coverage_config (coverage_id, content)
+VALUES ($1, $2)
+ON CONFLICT (coverage_id) DO UPDATE SET content = $2, uploaded_at = now();
+
+-- name: DeleteCoverageConfig :exec
+DELETE FROM coverage_config WHERE coverage_id = $1;
+
+-- name: ListCoverageShards :many
+SELECT * FROM coverage_shard
+WHERE repo_name = $1
+    AND project_name = $2
+    AND branch_name = $3
+    AND "commit" = $4
+ORDER BY shard_id;
+
+-- name: DeleteCoverageFiles :exec
+DELETE FROM coverage_file WHERE coverage_id = $1;
+
+-- name: ListCoverageFiles :many
+SELECT * FROM coverage_file WHERE coverage_id = $1 ORDER BY path;
+
+-- name: InsertCoverageFiles :many
+INSERT INTO coverage_file (
+    coverage_id, path, coverage, lines_covered, lines_total, branches_covered, branches_total,
+    statements_covered, statements_total
+)
+SELECT @coverage_id::int, unnest(@paths::text[]), unnest(@coverages::float8[]),
+    unnest(@lines_covered::int[]), unnest(@lines_total::int[]),
+    unnest(@branches_covered::int[]), unnest(@branches_total::int[]),
+    unnest(@statements_covered::int[]), unnest(@statements_total::int[])
+RETURNING id, path;
+
+-- name: InsertCoverageLineRanges :exec
+INSERT INTO coverage_line_range (coverage_file_id, start_line, end_line, hits, branches_covered, branches_total)
+SELECT unnest(@coverage_file_ids::int[]), unnest(@start_lines::int[]), unnest(@end_lines::int[]),
+    unnest(@hits::bigint[]), unnest(@branches_covered::int[]), unnest(@branches_total::int[]);
+
 
 -- name: ListRepositories :many
 SELECT DISTINCT repo_name FROM coverage order by repo_name;
@@ -45,13 +199,102 @@ SELECT DISTINCT branch_name FROM coverage WHERE repo_name = $1 AND project_name
 
 
 -- name: ListCoverageSummary :many
-SELECT repo_name, project_name, branch_name, commit, coverage, coverage_date FROM coverage
+SELECT repo_name, project_name, branch_name, commit, coverage, coverage_date, format, kind,
+    branches_covered, branches_total, original_coverage, functions_covered, functions_total
+FROM coverage
 WHERE repo_name = $1
   AND project_name = $2
   AND branch_name = $3
+  AND kind = @kind
 ORDER BY
 case WHEN lower(@order_direction) = 'asc' THEN coverage_date END ASC,
 case WHEN lower(@order_direction) = 'desc' THEN coverage_date END DESC,
 coverage_date ASC
 OFFSET $4
 LIMIT $5;
+
+-- name: ListFileCoverageHistory :many
+SELECT "commit", coverage_date, path, coverage, lines_covered, lines_total, branches_covered, branches_total
+FROM (
+    SELECT DISTINCT ON (coverage.id) coverage."commit", coverage.coverage_date, coverage_file.path,
+        coverage_file.coverage, coverage_file.lines_covered, coverage_file.lines_total,
+        coverage_file.branches_covered, coverage_file.branches_total
+    FROM coverage
+    JOIN coverage_file ON coverage_file.coverage_id = coverage.id
+    WHERE coverage.repo_name = $1
+      AND coverage.project_name = $2
+      AND coverage.branch_name = $3
+      AND coverage.kind = @kind
+      AND coverage_file.path = ANY(@paths::text[])
+    ORDER BY coverage.id, array_position(@paths::text[], coverage_file.path)
+) history
+ORDER BY
+case WHEN lower(@order_direction) = 'asc' THEN coverage_date END ASC,
+case WHEN lower(@order_direction) = 'desc' THEN coverage_date END DESC,
+coverage_date ASC
+OFFSET $4
+LIMIT $5;
+
+-- name: InsertFileRenames :exec
+INSERT INTO file_rename (repo_name, project_name, branch_name, commit, old_path, new_path)
+SELECT @repo_name::text, @project_name::text, @branch_name::text, @commit::text,
+    unnest(@old_paths::text[]), unnest(@new_paths::text[])
+ON CONFLICT (repo_name, project_name, branch_name, old_path, new_path) DO NOTHING;
+
+-- name: ListFileRenames :many
+SELECT old_path, new_path FROM file_rename
+WHERE repo_name = $1 AND project_name = $2 AND branch_name = $3
+ORDER BY created_at, old_path, new_path;
+
+-- name: GetGatePolicy :one
+SELECT * FROM gate_policy WHERE repo_name = $1 AND project_name = $2;
+
+-- name: UpsertGatePolicy :one
+INSERT INTO gate_policy (repo_name, project_name, min_coverage, max_drop, min_patch_coverage, path_rules)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (repo_name, project_name)
+    DO UPDATE SET min_coverage = $3, max_drop = $4, min_patch_coverage = $5, path_rules = $6, updated_at = now()
+RETURNING *;
+
+-- name: DeleteGatePolicy :execrows
+DELETE FROM gate_policy WHERE repo_name = $1 AND project_name = $2;
+
+-- name: GetBadgeSettings :one
+SELECT * FROM badge_settings WHERE repo_name = $1 AND project_name = $2;
+
+-- name: UpsertBadgeSettings :one
+INSERT INTO badge_settings (repo_name, project_name, color_scale)
+VALUES ($1, $2, $3)
+ON CONFLICT (repo_name, project_name)
+    DO UPDATE SET color_scale = $3, updated_at = now()
+RETURNING *;
+
+-- name: DeleteBadgeSettings :execrows
+DELETE FROM badge_settings WHERE repo_name = $1 AND project_name = $2;
+
+-- name: ListExclusionRules :many
+SELECT * FROM exclusion_rule WHERE repo_name = $1 AND project_name = $2 ORDER BY pattern;
+
+-- name: InsertExclusionRule :one
+INSERT INTO exclusion_rule (repo_name, project_name, pattern)
+VALUES ($1, $2, $3)
+ON CONFLICT (repo_name, project_name, pattern) DO UPDATE SET pattern = EXCLUDED.pattern
+RETURNING *;
+
+-- name: DeleteExclusionRule :execrows
+DELETE FROM exclusion_rule WHERE id = $1 AND repo_name = $2 AND project_name = $3;
+
+-- name: ListStaleProjectCoverage :many
+SELECT * FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND id > @after_id
+    AND rules_version <> @rules_version
+ORDER BY id
+LIMIT $3;
+
+-- name: CountStaleProjectCoverage :one
+SELECT count(*) FROM coverage
+WHERE repo_name = $1
+    AND project_name = $2
+    AND rules_version <> $3;
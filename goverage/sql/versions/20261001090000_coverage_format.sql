@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE coverage ADD COLUMN format VARCHAR(32) NOT NULL DEFAULT 'python';
+
+-- Go profiles were stored as parsed reports before the format was recorded.
+UPDATE coverage SET format = 'go' WHERE NOT raw_data ? 'meta' AND raw_data ? 'files';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE coverage DROP COLUMN format;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE coverage ADD COLUMN kind VARCHAR(32) NOT NULL DEFAULT 'unit';
+
+-- Integration coverage is stored next to the unit coverage of the same commit.
+DROP INDEX coverage_repo_name_project_name_branch_name_commit_idx;
+CREATE UNIQUE INDEX coverage_repo_name_project_name_branch_name_commit_kind_idx
+    ON coverage (repo_name, project_name, branch_name, commit, kind);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM coverage WHERE kind <> 'unit';
+
+DROP INDEX coverage_repo_name_project_name_branch_name_commit_kind_idx;
+CREATE UNIQUE INDEX coverage_repo_name_project_name_branch_name_commit_idx
+    ON coverage (repo_name, project_name, branch_name, commit);
+
+ALTER TABLE coverage DROP COLUMN kind;
+-- +goose StatementEnd
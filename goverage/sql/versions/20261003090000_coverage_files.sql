@@ -0,0 +1,114 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE coverage ADD COLUMN lines_covered INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE coverage ADD COLUMN lines_total INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE coverage_file (
+    id SERIAL PRIMARY KEY,
+    coverage_id INTEGER NOT NULL REFERENCES coverage (id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    coverage FLOAT NOT NULL,
+    lines_covered INTEGER NOT NULL,
+    lines_total INTEGER NOT NULL
+);
+
+CREATE UNIQUE INDEX coverage_file_coverage_id_path_idx ON coverage_file (coverage_id, path);
+
+-- Consecutive lines with the same hits are stored as a single range.
+CREATE TABLE coverage_line_range (
+    coverage_file_id INTEGER NOT NULL REFERENCES coverage_file (id) ON DELETE CASCADE,
+    start_line INTEGER NOT NULL,
+    end_line INTEGER NOT NULL,
+    hits BIGINT NOT NULL,
+    PRIMARY KEY (coverage_file_id, start_line)
+);
+
+-- Backfill from raw_data: coverage.py reports are stored as uploaded, the
+-- other formats as a normalized report without a "meta" key.
+UPDATE coverage SET
+    lines_covered = COALESCE((raw_data -> 'totals' ->> 'covered_lines')::INTEGER, 0),
+    lines_total = COALESCE((raw_data -> 'totals' ->> 'num_statements')::INTEGER, 0)
+WHERE raw_data ? 'meta';
+
+UPDATE coverage SET
+    lines_covered = (raw_data -> 'totals' -> 'lines' ->> 'covered')::INTEGER,
+    lines_total = (raw_data -> 'totals' -> 'lines' ->> 'total')::INTEGER
+WHERE NOT raw_data ? 'meta' AND raw_data -> 'totals' ? 'lines';
+
+INSERT INTO coverage_file (coverage_id, path, coverage, lines_covered, lines_total)
+SELECT
+    coverage.id,
+    files.path,
+    CASE WHEN files.lines_total = 0 THEN 0 ELSE files.lines_covered * 100.0 / files.lines_total END,
+    files.lines_covered,
+    files.lines_total
+FROM coverage, LATERAL (
+    SELECT
+        file.key AS path,
+        jsonb_array_length(COALESCE(file.value -> 'executed_lines', '[]')) AS lines_covered,
+        jsonb_array_length(COALESCE(file.value -> 'executed_lines', '[]'))
+            + jsonb_array_length(COALESCE(file.value -> 'missing_lines', '[]')) AS lines_total
+    FROM jsonb_each(coverage.raw_data -> 'files') AS file
+) AS files
+WHERE coverage.raw_data ? 'meta';
+
+INSERT INTO coverage_file (coverage_id, path, coverage, lines_covered, lines_total)
+SELECT
+    coverage.id,
+    file.key,
+    CASE
+        WHEN (file.value -> 'totals' -> 'statements' ->> 'total')::INTEGER > 0
+            THEN (file.value -> 'totals' -> 'statements' ->> 'covered')::INTEGER * 100.0
+                / (file.value -> 'totals' -> 'statements' ->> 'total')::INTEGER
+        WHEN (file.value -> 'totals' -> 'lines' ->> 'total')::INTEGER > 0
+            THEN (file.value -> 'totals' -> 'lines' ->> 'covered')::INTEGER * 100.0
+                / (file.value -> 'totals' -> 'lines' ->> 'total')::INTEGER
+        ELSE 0
+    END,
+    COALESCE((file.value -> 'totals' -> 'lines' ->> 'covered')::INTEGER, 0),
+    COALESCE((file.value -> 'totals' -> 'lines' ->> 'total')::INTEGER, 0)
+FROM coverage, jsonb_each(coverage.raw_data -> 'files') AS file
+WHERE NOT coverage.raw_data ? 'meta';
+
+-- Lines are grouped into ranges with the gaps and islands technique: within a
+-- file and a hit count, consecutive line numbers share line - row_number().
+WITH lines AS (
+    SELECT coverage_file.id AS coverage_file_id, line.number::INTEGER AS number, 1::BIGINT AS hits
+    FROM coverage_file
+    JOIN coverage ON coverage.id = coverage_file.coverage_id
+    CROSS JOIN jsonb_array_elements_text(coverage.raw_data -> 'files' -> coverage_file.path -> 'executed_lines') AS line (number)
+    WHERE coverage.raw_data ? 'meta'
+    UNION ALL
+    SELECT coverage_file.id, line.number::INTEGER, 0
+    FROM coverage_file
+    JOIN coverage ON coverage.id = coverage_file.coverage_id
+    CROSS JOIN jsonb_array_elements_text(coverage.raw_data -> 'files' -> coverage_file.path -> 'missing_lines') AS line (number)
+    WHERE coverage.raw_data ? 'meta'
+    UNION ALL
+    SELECT coverage_file.id, (line.value ->> 'line')::INTEGER, (line.value ->> 'hits')::BIGINT
+    FROM coverage_file
+    JOIN coverage ON coverage.id = coverage_file.coverage_id
+    CROSS JOIN jsonb_array_elements(coverage.raw_data -> 'files' -> coverage_file.path -> 'lines') AS line
+    WHERE NOT coverage.raw_data ? 'meta'
+), islands AS (
+    SELECT
+        coverage_file_id,
+        number,
+        hits,
+        number - ROW_NUMBER() OVER (PARTITION BY coverage_file_id, hits ORDER BY number) AS island
+    FROM lines
+)
+INSERT INTO coverage_line_range (coverage_file_id, start_line, end_line, hits)
+SELECT coverage_file_id, MIN(number), MAX(number), hits
+FROM islands
+GROUP BY coverage_file_id, hits, island;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE coverage_line_range;
+DROP TABLE coverage_file;
+
+ALTER TABLE coverage DROP COLUMN lines_total;
+ALTER TABLE coverage DROP COLUMN lines_covered;
+-- +goose StatementEnd
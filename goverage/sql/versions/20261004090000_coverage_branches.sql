@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE coverage ADD COLUMN branches_covered INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE coverage ADD COLUMN branches_total INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE coverage_file ADD COLUMN branches_covered INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE coverage_file ADD COLUMN branches_total INTEGER NOT NULL DEFAULT 0;
+
+-- Ranges of lines without conditional branches have zero branches.
+ALTER TABLE coverage_line_range ADD COLUMN branches_covered INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE coverage_line_range ADD COLUMN branches_total INTEGER NOT NULL DEFAULT 0;
+
+-- Only totals are backfilled, line ranges get their branches on the next upload.
+UPDATE coverage SET
+    branches_covered = COALESCE((raw_data -> 'totals' ->> 'covered_branches')::INTEGER, 0),
+    branches_total = COALESCE((raw_data -> 'totals' ->> 'num_branches')::INTEGER, 0)
+WHERE raw_data ? 'meta';
+
+UPDATE coverage SET
+    branches_covered = COALESCE((raw_data -> 'totals' -> 'branches' ->> 'covered')::INTEGER, 0),
+    branches_total = COALESCE((raw_data -> 'totals' -> 'branches' ->> 'total')::INTEGER, 0)
+WHERE NOT raw_data ? 'meta';
+
+UPDATE coverage_file SET
+    branches_covered = COALESCE((coverage.raw_data -> 'files' -> coverage_file.path -> 'summary' ->> 'covered_branches')::INTEGER, 0),
+    branches_total = COALESCE((coverage.raw_data -> 'files' -> coverage_file.path -> 'summary' ->> 'num_branches')::INTEGER, 0)
+FROM coverage
+WHERE coverage.id = coverage_file.coverage_id AND coverage.raw_data ? 'meta';
+
+UPDATE coverage_file SET
+    branches_covered = COALESCE((coverage.raw_data -> 'files' -> coverage_file.path -> 'totals' -> 'branches' ->> 'covered')::INTEGER, 0),
+    branches_total = COALESCE((coverage.raw_data -> 'files' -> coverage_file.path -> 'totals' -> 'branches' ->> 'total')::INTEGER, 0)
+FROM coverage
+WHERE coverage.id = coverage_file.coverage_id AND NOT coverage.raw_data ? 'meta';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE coverage_line_range DROP COLUMN branches_total;
+ALTER TABLE coverage_line_range DROP COLUMN branches_covered;
+
+ALTER TABLE coverage_file DROP COLUMN branches_total;
+ALTER TABLE coverage_file DROP COLUMN branches_covered;
+
+ALTER TABLE coverage DROP COLUMN branches_total;
+ALTER TABLE coverage DROP COLUMN branches_covered;
+-- +goose StatementEnd
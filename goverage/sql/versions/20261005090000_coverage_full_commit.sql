@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Commits used to be truncated to 8 characters on upload. Those rows are kept as is, they are matched by lookups
+-- starting with them and get their full SHA on the next upload of the same commit.
+-- Commits differing only by case are the same commit, only its latest upload is kept before lowercasing them.
+DELETE FROM coverage
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, row_number() OVER (
+            PARTITION BY repo_name, project_name, branch_name, kind, lower("commit")
+            ORDER BY coverage_date DESC, id DESC
+        ) AS rank
+        FROM coverage
+    ) ranked
+    WHERE rank > 1
+);
+
+UPDATE coverage SET "commit" = lower("commit") WHERE "commit" <> lower("commit");
+
+CREATE INDEX coverage_commit_prefix_idx
+    ON coverage (repo_name, project_name, branch_name, "commit" text_pattern_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX coverage_commit_prefix_idx;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE coverage_shard (
+    id SERIAL PRIMARY KEY,
+    repo_name VARCHAR(255) NOT NULL,
+    project_name VARCHAR(255) NOT NULL,
+    branch_name VARCHAR(255) NOT NULL,
+    commit VARCHAR(255) NOT NULL,
+    shard_id VARCHAR(255) NOT NULL,
+    format VARCHAR(32) NOT NULL,
+    content BYTEA NOT NULL,
+    expected_shards INTEGER,
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX coverage_shard_repo_name_project_name_branch_name_commit_shard_id_idx
+    ON coverage_shard (repo_name, project_name, branch_name, commit, shard_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE coverage_shard;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE gate_policy (
+    id SERIAL PRIMARY KEY,
+    repo_name VARCHAR(255) NOT NULL,
+    project_name VARCHAR(255) NOT NULL,
+    min_coverage FLOAT,
+    max_drop FLOAT,
+    min_patch_coverage FLOAT,
+    path_rules JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX gate_policy_repo_name_project_name_idx ON gate_policy (repo_name, project_name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE gate_policy;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The .goverage.yml file uploaded with the report of a commit, as uploaded.
+CREATE TABLE coverage_config (
+    coverage_id INTEGER PRIMARY KEY REFERENCES coverage (id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE coverage_config;
+-- +goose StatementEnd
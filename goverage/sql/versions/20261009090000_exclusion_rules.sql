@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE exclusion_rule (
+    id SERIAL PRIMARY KEY,
+    repo_name VARCHAR(255) NOT NULL,
+    project_name VARCHAR(255) NOT NULL,
+    pattern TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX exclusion_rule_repo_name_project_name_pattern_idx ON exclusion_rule (repo_name, project_name, pattern);
+
+-- Totals of the report as uploaded, before excluded files are left out.
+ALTER TABLE coverage ADD COLUMN original_coverage FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE coverage ADD COLUMN original_lines_covered INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE coverage ADD COLUMN original_lines_total INTEGER NOT NULL DEFAULT 0;
+
+UPDATE coverage SET
+    original_coverage = coverage,
+    original_lines_covered = lines_covered,
+    original_lines_total = lines_total;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE coverage DROP COLUMN original_lines_total;
+ALTER TABLE coverage DROP COLUMN original_lines_covered;
+ALTER TABLE coverage DROP COLUMN original_coverage;
+
+DROP TABLE exclusion_rule;
+-- +goose StatementEnd
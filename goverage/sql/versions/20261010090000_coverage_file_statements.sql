@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE coverage_file ADD COLUMN statements_covered INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE coverage_file ADD COLUMN statements_total INTEGER NOT NULL DEFAULT 0;
+
+-- coverage.py reports count lines only, they keep zero statements.
+UPDATE coverage_file SET
+    statements_covered = COALESCE((coverage.raw_data -> 'files' -> coverage_file.path -> 'totals' -> 'statements' ->> 'covered')::INTEGER, 0),
+    statements_total = COALESCE((coverage.raw_data -> 'files' -> coverage_file.path -> 'totals' -> 'statements' ->> 'total')::INTEGER, 0)
+FROM coverage
+WHERE coverage.id = coverage_file.coverage_id AND NOT coverage.raw_data ? 'meta';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE coverage_file DROP COLUMN statements_total;
+ALTER TABLE coverage_file DROP COLUMN statements_covered;
+-- +goose StatementEnd
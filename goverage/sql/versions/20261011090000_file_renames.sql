@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Renames uploaded with the reports, followed by the file coverage history.
+CREATE TABLE file_rename (
+    repo_name VARCHAR(255) NOT NULL,
+    project_name VARCHAR(255) NOT NULL,
+    old_path TEXT NOT NULL,
+    new_path TEXT NOT NULL,
+    commit VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (repo_name, project_name, old_path, new_path)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE file_rename;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE coverage ADD COLUMN functions_covered INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE coverage ADD COLUMN functions_total INTEGER NOT NULL DEFAULT 0;
+
+-- Python reports count no functions.
+UPDATE coverage SET
+    functions_covered = COALESCE((raw_data -> 'totals' -> 'functions' ->> 'covered')::INTEGER, 0),
+    functions_total = COALESCE((raw_data -> 'totals' -> 'functions' ->> 'total')::INTEGER, 0)
+WHERE NOT raw_data ? 'meta';
+
+CREATE TABLE badge_settings (
+    id SERIAL PRIMARY KEY,
+    repo_name VARCHAR(255) NOT NULL,
+    project_name VARCHAR(255) NOT NULL,
+    color_scale TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX badge_settings_repo_name_project_name_idx ON badge_settings (repo_name, project_name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE badge_settings;
+
+ALTER TABLE coverage DROP COLUMN functions_total;
+ALTER TABLE coverage DROP COLUMN functions_covered;
+-- +goose StatementEnd
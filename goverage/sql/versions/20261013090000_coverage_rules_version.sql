@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- rules_version identifies the exclusion rules a report was stored with, so a
+-- recompute interrupted midway resumes with the reports it didn't reach.
+ALTER TABLE coverage ADD COLUMN rules_version TEXT NOT NULL DEFAULT '';
+
+-- Reports stored while the project had exclusion rules may predate them.
+UPDATE coverage SET rules_version = 'unknown'
+WHERE EXISTS (
+    SELECT 1 FROM exclusion_rule
+    WHERE exclusion_rule.repo_name = coverage.repo_name AND exclusion_rule.project_name = coverage.project_name
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE coverage DROP COLUMN rules_version;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- File histories look files up by path across the reports of a branch.
+CREATE INDEX IF NOT EXISTS coverage_file_path_idx ON coverage_file (path);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS coverage_file_path_idx;
+-- +goose StatementEnd
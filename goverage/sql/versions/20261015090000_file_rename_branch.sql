@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Renames apply to the history of the branch they were uploaded to only.
+ALTER TABLE file_rename ADD COLUMN branch_name VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE file_rename DROP CONSTRAINT file_rename_pkey;
+ALTER TABLE file_rename ADD PRIMARY KEY (repo_name, project_name, branch_name, old_path, new_path);
+
+-- Renames stored per project are kept for every branch their commit was
+-- uploaded to, and dropped when it can't be found anymore.
+INSERT INTO file_rename (repo_name, project_name, branch_name, old_path, new_path, commit, created_at)
+SELECT DISTINCT
+    file_rename.repo_name, file_rename.project_name, coverage.branch_name,
+    file_rename.old_path, file_rename.new_path, file_rename.commit, file_rename.created_at
+FROM file_rename
+JOIN coverage ON coverage.repo_name = file_rename.repo_name
+    AND coverage.project_name = file_rename.project_name
+    AND coverage.commit = file_rename.commit
+WHERE file_rename.branch_name = ''
+ON CONFLICT DO NOTHING;
+
+DELETE FROM file_rename WHERE branch_name = '';
+
+ALTER TABLE file_rename ALTER COLUMN branch_name DROP DEFAULT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM file_rename
+USING file_rename AS kept
+WHERE kept.repo_name = file_rename.repo_name
+    AND kept.project_name = file_rename.project_name
+    AND kept.old_path = file_rename.old_path
+    AND kept.new_path = file_rename.new_path
+    AND kept.ctid < file_rename.ctid;
+
+ALTER TABLE file_rename DROP CONSTRAINT file_rename_pkey;
+ALTER TABLE file_rename DROP COLUMN branch_name;
+ALTER TABLE file_rename ADD PRIMARY KEY (repo_name, project_name, old_path, new_path);
+-- +goose StatementEnd